@@ -0,0 +1,71 @@
+package backoff
+
+import (
+	"context"
+	"math/rand/v2"
+	"time"
+)
+
+// Política de novas tentativas com backoff exponencial e "full jitter"
+type Policy struct {
+	// Total de tentativas, contando a primeira (<= 1 desabilita as novas tentativas)
+	MaxAttempts int
+	BaseDelay   time.Duration
+	// Teto do intervalo, por maior que seja a tentativa
+	MaxDelay time.Duration
+	// Chamado antes de cada espera, com a tentativa que falhou e o intervalo sorteado
+	OnRetry func(attempt int, delay time.Duration)
+}
+
+// Intervalo antes da tentativa seguinte a attempt (a partir de 1): um valor aleatório entre 0 e
+// min(BaseDelay*2^(attempt-1), MaxDelay)
+func (p Policy) Next(attempt int) time.Duration {
+	return time.Duration(rand.Int64N(int64(p.ceiling(attempt)) + 1))
+}
+
+// Limite superior do sorteio, protegido contra overflow do deslocamento
+func (p Policy) ceiling(attempt int) time.Duration {
+	delay := p.MaxDelay
+	if shift := attempt - 1; shift >= 0 && shift < 32 {
+		if d := p.BaseDelay << shift; d > 0 && d < p.MaxDelay {
+			delay = d
+		}
+	}
+	return max(delay, 0)
+}
+
+// Executa fn até ela pedir para parar (retry=false) ou as tentativas acabarem, devolvendo o último erro.
+// Não espera quando o intervalo ultrapassaria o prazo do contexto (fica o último resultado) e, com o
+// contexto cancelado durante a espera, devolve ctx.Err()
+func Retry(ctx context.Context, p Policy, fn func(attempt int) (retry bool, err error)) error {
+	for attempt := 1; ; attempt++ {
+		retry, err := fn(attempt)
+		if !retry || attempt >= p.MaxAttempts {
+			return err
+		}
+
+		delay := p.Next(attempt)
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+			return err
+		}
+		if p.OnRetry != nil {
+			p.OnRetry(attempt, delay)
+		}
+		if err := Sleep(ctx, delay); err != nil {
+			return err
+		}
+	}
+}
+
+// Aguarda o intervalo, desistindo se o contexto for cancelado antes
+func Sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,190 @@
+package backoff
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+var errTransient = errors.New("transient")
+
+func TestPolicy_CeilingSequence(t *testing.T) {
+	p := Policy{BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}
+
+	expected := []time.Duration{
+		100 * time.Millisecond,
+		200 * time.Millisecond,
+		400 * time.Millisecond,
+		800 * time.Millisecond,
+		time.Second,
+		time.Second,
+	}
+	for i, want := range expected {
+		if got := p.ceiling(i + 1); got != want {
+			t.Errorf("attempt %d: expected ceiling %s, but got %s", i+1, want, got)
+		}
+	}
+
+	// Deslocamentos grandes estourariam o int64; o teto continua valendo
+	for _, attempt := range []int{31, 32, 40, 64, 1000} {
+		if got := p.ceiling(attempt); got != time.Second {
+			t.Errorf("attempt %d: expected ceiling 1s, but got %s", attempt, got)
+		}
+	}
+}
+
+func TestPolicy_NextFullJitter(t *testing.T) {
+	p := Policy{BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}
+
+	for attempt := 1; attempt <= 40; attempt++ {
+		ceiling := p.ceiling(attempt)
+		seen := make(map[time.Duration]bool)
+		for range 50 {
+			d := p.Next(attempt)
+			if d < 0 || d > ceiling {
+				t.Fatalf("attempt %d: expected delay within [0, %s], but got %s", attempt, ceiling, d)
+			}
+			seen[d] = true
+		}
+		// Com jitter, 50 sorteios entre 0 e ao menos 100ms não se repetem todos
+		if len(seen) == 1 {
+			t.Errorf("attempt %d: expected jittered delays, but always got the same value", attempt)
+		}
+	}
+}
+
+func TestPolicy_NextZeroDelay(t *testing.T) {
+	if d := (Policy{}).Next(3); d != 0 {
+		t.Errorf("expected no delay for a zero policy, but got %s", d)
+	}
+}
+
+func TestRetry_SucceedsAfterTransientFailures(t *testing.T) {
+	var retried []int
+	p := Policy{MaxAttempts: 5, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond,
+		OnRetry: func(attempt int, delay time.Duration) { retried = append(retried, attempt) },
+	}
+
+	calls := 0
+	err := Retry(context.Background(), p, func(attempt int) (bool, error) {
+		calls++
+		if attempt < 3 {
+			return true, errTransient
+		}
+		return false, nil
+	})
+
+	if err != nil {
+		t.Fatalf("expected no error, but got: %v", err)
+	}
+	if calls != 3 {
+		t.Errorf("expected 3 calls, but got %d", calls)
+	}
+	if !slices.Equal(retried, []int{1, 2}) {
+		t.Errorf("expected retries after attempts [1 2], but got %v", retried)
+	}
+}
+
+func TestRetry_Limits(t *testing.T) {
+	tests := []struct {
+		name        string
+		maxAttempts int
+		retry       bool
+		expected    int
+	}{
+		{"gives up after max attempts", 3, true, 3},
+		{"retries disabled", 1, true, 1},
+		{"zero attempts runs once", 0, true, 1},
+		{"permanent failure", 3, false, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := Policy{MaxAttempts: tt.maxAttempts, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond}
+
+			calls := 0
+			err := Retry(context.Background(), p, func(attempt int) (bool, error) {
+				calls++
+				return tt.retry, errTransient
+			})
+
+			if !errors.Is(err, errTransient) {
+				t.Errorf("expected the last error '%v', but got '%v'", errTransient, err)
+			}
+			if calls != tt.expected {
+				t.Errorf("expected %d calls, but got %d", tt.expected, calls)
+			}
+		})
+	}
+}
+
+func TestRetry_ContextCancelledDuringWait(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	// Cancela logo antes da espera, que seria de 1h
+	p := Policy{MaxAttempts: 3, BaseDelay: time.Hour, MaxDelay: time.Hour,
+		OnRetry: func(attempt int, delay time.Duration) { cancel() },
+	}
+
+	calls := 0
+	start := time.Now()
+	err := Retry(ctx, p, func(attempt int) (bool, error) {
+		calls++
+		return true, errTransient
+	})
+
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected error '%v', but got '%v'", context.Canceled, err)
+	}
+	if calls != 1 {
+		t.Errorf("expected 1 call, but got %d", calls)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("expected to stop on cancellation, but took %s", elapsed)
+	}
+}
+
+func TestRetry_SkipsWaitPastDeadline(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	// Sorteado entre 0 e 1h, o intervalo só fica abaixo do prazo com probabilidade desprezível
+	p := Policy{MaxAttempts: 3, BaseDelay: time.Hour, MaxDelay: time.Hour,
+		OnRetry: func(attempt int, delay time.Duration) {
+			if delay >= 50*time.Millisecond {
+				t.Errorf("expected no wait past the deadline, but got OnRetry with %s", delay)
+			}
+		},
+	}
+
+	calls := 0
+	start := time.Now()
+	err := Retry(ctx, p, func(attempt int) (bool, error) {
+		calls++
+		return true, errTransient
+	})
+
+	// Fica o resultado da última tentativa, e não o erro do contexto
+	if !errors.Is(err, errTransient) {
+		t.Errorf("expected the last error '%v', but got '%v'", errTransient, err)
+	}
+	if calls != 1 {
+		t.Errorf("expected 1 call, but got %d", calls)
+	}
+	if elapsed := time.Since(start); elapsed > 40*time.Millisecond {
+		t.Errorf("expected to return without waiting, but took %s", elapsed)
+	}
+}
+
+func TestSleep(t *testing.T) {
+	if err := Sleep(context.Background(), time.Millisecond); err != nil {
+		t.Errorf("expected no error, but got: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := Sleep(ctx, time.Hour); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected error '%v', but got '%v'", context.Canceled, err)
+	}
+}
@@ -0,0 +1,23 @@
+package main
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/baggage"
+)
+
+// Chave do CEP normalizado no baggage, propagado ao app2 pelo cabeçalho baggage
+const cepBaggageKey = "cep"
+
+// Contexto com o CEP no baggage; se o baggage não puder ser montado, ctx segue sem ele
+func withCepBaggage(ctx context.Context, zipcode string) context.Context {
+	member, err := baggage.NewMember(cepBaggageKey, zipcode)
+	if err != nil {
+		return ctx
+	}
+	bag, err := baggage.FromContext(ctx).SetMember(member)
+	if err != nil {
+		return ctx
+	}
+	return baggage.ContextWithBaggage(ctx, bag)
+}
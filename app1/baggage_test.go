@@ -0,0 +1,27 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel/baggage"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+func TestWithCepBaggage_RoundTrip(t *testing.T) {
+	propagator := propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{})
+	ctx := withCepBaggage(context.Background(), "01001-000")
+
+	header := http.Header{}
+	propagator.Inject(ctx, propagation.HeaderCarrier(header))
+	if !strings.Contains(header.Get("Baggage"), "cep=01001-000") {
+		t.Fatalf("expected the cep in the baggage header, but got '%s'", header.Get("Baggage"))
+	}
+
+	extracted := propagator.Extract(context.Background(), propagation.HeaderCarrier(header))
+	if got := baggage.FromContext(extracted).Member(cepBaggageKey).Value(); got != "01001-000" {
+		t.Errorf("expected cep '01001-000' after extracting, but got '%s'", got)
+	}
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"sync"
+	"time"
+)
+
+// Tempo fora do rodízio de uma instância do app2 que falhou na conexão
+const defaultBackendCooldown = 30 * time.Second
+
+// Balanceamento no cliente entre as instâncias do APP2_BASE_URLS: rodízio, pulando por um
+// cooldown as que tiveram erro de conexão
+type backendPool struct {
+	mu       sync.Mutex
+	urls     []string
+	until    []time.Time
+	next     int
+	cooldown time.Duration
+	now      func() time.Time
+}
+
+func newBackendPool(urls []string, cooldown time.Duration) *backendPool {
+	return &backendPool{
+		urls:     urls,
+		until:    make([]time.Time, len(urls)),
+		cooldown: cooldown,
+		now:      time.Now,
+	}
+}
+
+// Índices na ordem de tentativa: a partir da vez do rodízio, com as instâncias em cooldown no fim,
+// para que ainda sejam tentadas se todas estiverem fora
+func (p *backendPool) order() []int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := p.now()
+	available := make([]int, 0, len(p.urls))
+	var cooling []int
+	for i := range p.urls {
+		idx := (p.next + i) % len(p.urls)
+		if now.Before(p.until[idx]) {
+			cooling = append(cooling, idx)
+		} else {
+			available = append(available, idx)
+		}
+	}
+
+	ordered := append(available, cooling...)
+	p.next = (ordered[0] + 1) % len(p.urls)
+	return ordered
+}
+
+// Com uma única instância não há alternativa: o cooldown não se aplica
+func (p *backendPool) markFailed(idx int) bool {
+	if len(p.urls) < 2 {
+		return false
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.until[idx] = p.now().Add(p.cooldown)
+	return true
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestBackendPool_RoundRobin(t *testing.T) {
+	pool := newBackendPool([]string{"a", "b", "c"}, time.Minute)
+
+	var got []int
+	for range 4 {
+		got = append(got, pool.order()[0])
+	}
+
+	if expected := []int{0, 1, 2, 0}; !slices.Equal(got, expected) {
+		t.Errorf("expected %v, but got %v", expected, got)
+	}
+}
+
+func TestBackendPool_SkipsFailedDuringCooldown(t *testing.T) {
+	now := time.Date(2026, time.January, 1, 12, 0, 0, 0, time.UTC)
+	pool := newBackendPool([]string{"a", "b", "c"}, time.Minute)
+	pool.now = func() time.Time { return now }
+
+	pool.markFailed(1)
+	if got := pool.order(); !slices.Equal(got, []int{0, 2, 1}) {
+		t.Errorf("expected the failed instance last, but got %v", got)
+	}
+	if got := pool.order()[0]; got != 2 {
+		t.Errorf("expected instance 2 to be skipped to, but got %d", got)
+	}
+
+	now = now.Add(time.Minute)
+	if got := pool.order(); !slices.Equal(got, []int{0, 1, 2}) {
+		t.Errorf("expected the instance back after the cooldown, but got %v", got)
+	}
+}
+
+func TestBackendPool_SingleInstance(t *testing.T) {
+	pool := newBackendPool([]string{"a"}, time.Minute)
+
+	if pool.markFailed(0) {
+		t.Error("expected a single instance not to be put in cooldown")
+	}
+	if got := pool.order(); !slices.Equal(got, []int{0}) {
+		t.Errorf("expected [0], but got %v", got)
+	}
+}
+
+func TestFetchWeather_DistributesAcrossBackends(t *testing.T) {
+	var mu sync.Mutex
+	hits := map[string]int{}
+	newBackend := func(name string) *httptest.Server {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			hits[name]++
+			mu.Unlock()
+			w.Write([]byte(`{"city": "São Paulo"}`))
+		}))
+		t.Cleanup(server.Close)
+		return server
+	}
+	a, b := newBackend("a"), newBackend("b")
+
+	// Instância recusando conexões: sai do rodízio depois da primeira falha
+	down := httptest.NewServer(http.NotFoundHandler())
+	down.Close()
+
+	app := newTestApplication()
+	app.app2Backends = newBackendPool([]string{a.URL, down.URL, b.URL}, time.Minute)
+	app.hedgeDelay = time.Minute
+
+	for range 6 {
+		rec := httptest.NewRecorder()
+		app.handler(rec, httptest.NewRequest(http.MethodGet, "/weather-by-cep?cep=01001000", nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected status 200, but got %d: %s", rec.Code, rec.Body.String())
+		}
+	}
+
+	if hits["a"] != 3 || hits["b"] != 3 {
+		t.Errorf("expected 3 requests on each healthy instance, but got %v", hits)
+	}
+	if until := app.app2Backends.until[1]; until.IsZero() {
+		t.Error("expected the unreachable instance to be in cooldown")
+	}
+}
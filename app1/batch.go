@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+
+	"l02-01/cep"
+)
+
+const (
+	maxBatchSize            = 50
+	defaultBatchConcurrency = 5
+)
+
+type BatchRequest struct {
+	Ceps []string `json:"ceps"`
+}
+
+// Cada item traz o clima ou o erro daquele CEP, para que um CEP ruim não derrube o lote
+type BatchResult struct {
+	Cep     string         `json:"cep"`
+	Weather *Response      `json:"weather,omitempty"`
+	Error   *errorResponse `json:"error,omitempty"`
+}
+
+func (app *application) batchHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, span := app.tracer.Start(r.Context(), "/weather-by-cep/batch")
+	defer span.End()
+	defer r.Body.Close()
+
+	if !requireJSONBody(w, r) {
+		return
+	}
+
+	// Corpo vazio (io.EOF) cai no erro de 'ceps' ausente
+	r.Body = http.MaxBytesReader(w, r.Body, app.maxBodyBytes)
+	var req BatchRequest
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		writeBodyError(w, err)
+		return
+	}
+
+	if len(req.Ceps) == 0 {
+		writeJSONError(w, http.StatusBadRequest, codeMissingCep, msgMissingCepsField)
+		return
+	}
+
+	if len(req.Ceps) > maxBatchSize {
+		writeJSONError(w, http.StatusBadRequest, codeBatchTooLarge, fmt.Sprintf(msgBatchTooLarge, maxBatchSize))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(app.fetchBatch(ctx, req.Ceps))
+}
+
+// Consulta os CEPs com no máximo batchConcurrency chamadas simultâneas ao app2, mantendo a ordem da entrada
+func (app *application) fetchBatch(ctx context.Context, ceps []string) []BatchResult {
+	results := make([]BatchResult, len(ceps))
+	jobs := make(chan int)
+
+	workers := min(max(app.batchConcurrency, 1), len(ceps))
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i] = app.fetchBatchItem(ctx, ceps[i])
+			}
+		}()
+	}
+
+	for i := range ceps {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	return results
+}
+
+func (app *application) fetchBatchItem(ctx context.Context, raw string) BatchResult {
+	result := BatchResult{Cep: raw}
+	if raw == "" {
+		result.Error = &errorResponse{Error: msgMissingCep, Code: codeMissingCep}
+		return result
+	}
+
+	zipcode, err := cep.NormalizeCEP(raw)
+	if err != nil {
+		result.Error = &errorResponse{Error: msgInvalidZipcode, Code: codeInvalidZipcode}
+		return result
+	}
+
+	weather, apiErr := app.fetchWeather(ctx, zipcode)
+	if apiErr != nil {
+		result.Error = &errorResponse{Error: apiErr.msg, Code: apiErr.code}
+		return result
+	}
+
+	result.Weather = weather
+	return result
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newBatchApp2Stub(t *testing.T, inFlight, peak *atomic.Int32) {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+
+		// O primeiro CEP demora mais, para que as respostas cheguem fora de ordem
+		cep := r.URL.Query().Get("cep")
+		switch cep {
+		case "01001-000":
+			time.Sleep(30 * time.Millisecond)
+		case "99999-999":
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error":"CEP não encontrado","code":"cep_not_found"}`))
+			return
+		}
+		fmt.Fprintf(w, `{"city": "City %s", "cep": "%s", "temp_C": 25, "temp_F": 77, "temp_K": 298.15}`, cep, cep)
+	}))
+	t.Cleanup(server.Close)
+	t.Setenv("APP2_BASE_URL", server.URL)
+}
+
+func TestBatchHandler_MixedResultsInOrder(t *testing.T) {
+	var inFlight, peak atomic.Int32
+	newBatchApp2Stub(t, &inFlight, &peak)
+
+	app := newTestApplication()
+	body := `{"ceps": ["01001-000", "99999-999", "123", "", "20040000"]}`
+	rec := httptest.NewRecorder()
+	app.batchHandler(rec, newJSONRequest(http.MethodPost, "/weather-by-cep/batch", body))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, but got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	var results []BatchResult
+	if err := json.NewDecoder(rec.Body).Decode(&results); err != nil {
+		t.Fatalf("expected a JSON array, but got: %v", err)
+	}
+
+	if len(results) != 5 {
+		t.Fatalf("expected 5 results, but got %d", len(results))
+	}
+
+	expected := []struct {
+		cep  string
+		city string
+		code string
+	}{
+		{"01001-000", "City 01001-000", ""},
+		{"99999-999", "", codeCepNotFound},
+		{"123", "", codeInvalidZipcode},
+		{"", "", codeMissingCep},
+		{"20040000", "City 20040-000", ""},
+	}
+
+	for i, want := range expected {
+		got := results[i]
+		if got.Cep != want.cep {
+			t.Errorf("result %d: expected cep '%s', but got '%s'", i, want.cep, got.Cep)
+		}
+
+		if want.code != "" {
+			if got.Error == nil || got.Error.Code != want.code || got.Weather != nil {
+				t.Errorf("result %d: expected error code '%s', but got %+v", i, want.code, got)
+			}
+			continue
+		}
+
+		if got.Error != nil || got.Weather == nil || got.Weather.City != want.city {
+			t.Errorf("result %d: expected city '%s', but got %+v", i, want.city, got)
+		}
+	}
+}
+
+func TestBatchHandler_BoundedConcurrency(t *testing.T) {
+	var inFlight, peak atomic.Int32
+	newBatchApp2Stub(t, &inFlight, &peak)
+
+	app := newTestApplication()
+	app.batchConcurrency = 2
+
+	ceps := make([]string, 10)
+	for i := range ceps {
+		ceps[i] = `"01001-000"`
+	}
+	body := `{"ceps": [` + strings.Join(ceps, ",") + `]}`
+
+	rec := httptest.NewRecorder()
+	app.batchHandler(rec, newJSONRequest(http.MethodPost, "/weather-by-cep/batch", body))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, but got %d", rec.Code)
+	}
+
+	if got := peak.Load(); got > 2 {
+		t.Errorf("expected at most 2 concurrent calls to app2, but got %d", got)
+	}
+}
+
+func TestBatchHandler_RejectsInvalidBatches(t *testing.T) {
+	tooMany := make([]string, maxBatchSize+1)
+	for i := range tooMany {
+		tooMany[i] = `"01001-000"`
+	}
+
+	tests := []struct {
+		name string
+		body string
+		code string
+	}{
+		{"too large", `{"ceps": [` + strings.Join(tooMany, ",") + `]}`, codeBatchTooLarge},
+		{"empty list", `{"ceps": []}`, codeMissingCep},
+		{"malformed", `{"ceps": `, codeInvalidJSON},
+		{"empty body", "", codeMissingCep},
+		{"unknown field", `{"ceps": ["01001-000"], "zips": []}`, codeInvalidJSON},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			newTestApplication().batchHandler(rec, newJSONRequest(http.MethodPost, "/weather-by-cep/batch", tt.body))
+
+			if rec.Code != http.StatusBadRequest {
+				t.Fatalf("expected status 400, but got %d", rec.Code)
+			}
+
+			var body errorResponse
+			if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+				t.Fatalf("expected a JSON body, but got: %v", err)
+			}
+
+			if body.Code != tt.code {
+				t.Errorf("expected code '%s', but got '%s'", tt.code, body.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package cep
+
+import "testing"
+
+func TestNormalizeCEP(t *testing.T) {
+	tests := []struct {
+		name    string
+		raw     string
+		want    string
+		wantErr bool
+	}{
+		{name: "hyphenated", raw: "01001-000", want: "01001-000"},
+		{name: "digits only", raw: "01001000", want: "01001-000"},
+		{name: "dots", raw: "01.001-000", want: "01001-000"},
+		{name: "spaces", raw: " 01001 000 ", want: "01001-000"},
+		{name: "mixed separators", raw: "01 001.000", want: "01001-000"},
+		{name: "empty", raw: "", wantErr: true},
+		{name: "too short", raw: "0100-000", wantErr: true},
+		{name: "too long", raw: "010010000", wantErr: true},
+		{name: "letters", raw: "0100a-000", wantErr: true},
+		{name: "other separators", raw: "01001/000", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NormalizeCEP(tt.raw)
+			if tt.wantErr {
+				if err != ErrInvalidCep {
+					t.Errorf("expected error '%v', but got '%v'", ErrInvalidCep, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("expected no error, but got: %v", err)
+			}
+
+			if got != tt.want {
+				t.Errorf("expected '%s', but got '%s'", tt.want, got)
+			}
+		})
+	}
+}
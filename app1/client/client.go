@@ -0,0 +1,151 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"l02-01/httpx"
+)
+
+var (
+	ErrCepNotFound    = fmt.Errorf("CEP não encontrado")
+	ErrInvalidZipcode = fmt.Errorf("CEP com formato inválido")
+)
+
+// Limite de leitura do corpo de erro do app1
+const maxErrorBodySize = 4 << 10
+
+// Erro devolvido pelo app1 que não tem um erro próprio no pacote (429, 5xx...)
+type APIError struct {
+	StatusCode int
+	Code       string
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("app1 returned status %d (%s): %s", e.StatusCode, e.Code, e.Message)
+}
+
+// Mesmo formato da resposta do app1
+type Response struct {
+	City   string `json:"city"`
+	State  string `json:"state,omitempty"`
+	Street string `json:"street,omitempty"`
+	Cep    string `json:"cep,omitempty"`
+	// Ausentes apenas no modo degradado (Partial), quando o clima não pôde ser obtido
+	TempC *float64 `json:"temp_C,omitempty"`
+	TempF *float64 `json:"temp_F,omitempty"`
+	TempK *float64 `json:"temp_K,omitempty"`
+	// Omitidos quando a WeatherAPI não os informa
+	Humidity   *int        `json:"humidity,omitempty"`
+	WindKph    *float64    `json:"wind_kph,omitempty"`
+	Condition  string      `json:"condition,omitempty"`
+	Region     string      `json:"region,omitempty"`
+	Country    string      `json:"country,omitempty"`
+	AirQuality *AirQuality `json:"air_quality,omitempty"`
+	Partial    bool        `json:"partial,omitempty"`
+}
+
+type AirQuality struct {
+	PM25       float64 `json:"pm2_5"`
+	PM10       float64 `json:"pm10"`
+	USEPAIndex int     `json:"us_epa_index"`
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+	Code  string `json:"code"`
+}
+
+type Client struct {
+	httpClient *http.Client
+	baseURL    string
+	timeout    time.Duration
+}
+
+type Option func(*Client)
+
+// Tempo máximo de cada chamada ao app1 (ignorado junto com WithHTTPClient)
+func WithTimeout(d time.Duration) Option {
+	return func(c *Client) {
+		if d > 0 {
+			c.timeout = d
+		}
+	}
+}
+
+// Usa o cliente informado como está: sem o transporte de tracing
+// e sem aplicar WithTimeout, que só vale para o cliente padrão
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// O cliente padrão propaga o contexto de trace (traceparent) para o app1
+func NewClient(baseURL string, opts ...Option) *Client {
+	c := &Client{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		timeout: 10 * time.Second,
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	if c.httpClient == nil {
+		c.httpClient = httpx.NewInstrumentedClient(httpx.WithTimeout(c.timeout))
+	}
+
+	return c
+}
+
+// Consulta o clima pelo CEP, com ou sem hífen. O modo degradado do app2 (206) não é erro: vem com Partial
+func (c *Client) WeatherByCEP(ctx context.Context, cep string) (*Response, error) {
+	endpoint := fmt.Sprintf("%s/weather-by-cep?cep=%s", c.baseURL, url.QueryEscape(cep))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusPartialContent {
+		return nil, decodeError(resp)
+	}
+
+	var data Response
+	if err := json.NewDecoder(resp.Body).Decode(&data); err != nil {
+		return nil, fmt.Errorf("invalid response from app1: %w", err)
+	}
+
+	return &data, nil
+}
+
+// Os códigos do corpo têm precedência; sem corpo estruturado, vale o status
+func decodeError(resp *http.Response) error {
+	var body errorResponse
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxErrorBodySize)).Decode(&body); err != nil {
+		body = errorResponse{}
+	}
+
+	switch {
+	case body.Code == "cep_not_found", body.Code == "" && resp.StatusCode == http.StatusNotFound:
+		return ErrCepNotFound
+	case body.Code == "invalid_zipcode", body.Code == "" && resp.StatusCode == http.StatusUnprocessableEntity:
+		return ErrInvalidZipcode
+	}
+
+	return &APIError{StatusCode: resp.StatusCode, Code: body.Code, Message: body.Error}
+}
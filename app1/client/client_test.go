@@ -0,0 +1,152 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+func TestWeatherByCEP_Success(t *testing.T) {
+	var gotPath, gotCep, gotAccept string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath, gotCep, gotAccept = r.URL.Path, r.URL.Query().Get("cep"), r.Header.Get("Accept")
+		w.Write([]byte(`{"city": "São Paulo", "state": "SP", "cep": "01001-000", "temp_C": 25, "temp_F": 77, "temp_K": 298.15, "humidity": 60}`))
+	}))
+	defer server.Close()
+
+	resp, err := NewClient(server.URL+"/").WeatherByCEP(context.Background(), "01001-000")
+	if err != nil {
+		t.Fatalf("expected no error, but got: %v", err)
+	}
+
+	if gotPath != "/weather-by-cep" || gotCep != "01001-000" {
+		t.Errorf("expected /weather-by-cep?cep=01001-000, but got %s?cep=%s", gotPath, gotCep)
+	}
+	if gotAccept != "application/json" {
+		t.Errorf("expected Accept 'application/json', but got '%s'", gotAccept)
+	}
+	if resp.City != "São Paulo" || resp.State != "SP" {
+		t.Errorf("expected São Paulo/SP, but got %s/%s", resp.City, resp.State)
+	}
+	if resp.TempC == nil || *resp.TempC != 25 {
+		t.Errorf("expected temp_C 25, but got %v", resp.TempC)
+	}
+	if resp.Humidity == nil || *resp.Humidity != 60 {
+		t.Errorf("expected humidity 60, but got %v", resp.Humidity)
+	}
+}
+
+func TestWeatherByCEP_PartialResponse(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusPartialContent)
+		w.Write([]byte(`{"city": "São Paulo", "partial": true}`))
+	}))
+	defer server.Close()
+
+	resp, err := NewClient(server.URL).WeatherByCEP(context.Background(), "01001000")
+	if err != nil {
+		t.Fatalf("expected no error, but got: %v", err)
+	}
+	if !resp.Partial || resp.TempC != nil {
+		t.Errorf("expected a partial response without temperatures, but got %+v", resp)
+	}
+}
+
+func TestWeatherByCEP_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		body     string
+		expected error
+		code     string
+	}{
+		{"cep not found", http.StatusNotFound, `{"error": "can not find zipcode", "code": "cep_not_found"}`, ErrCepNotFound, ""},
+		{"not found without body", http.StatusNotFound, "", ErrCepNotFound, ""},
+		{"invalid zipcode", http.StatusUnprocessableEntity, `{"error": "invalid zipcode", "code": "invalid_zipcode"}`, ErrInvalidZipcode, ""},
+		{"rate limited", http.StatusTooManyRequests, `{"error": "too many requests", "code": "rate_limited"}`, nil, "rate_limited"},
+		{"upstream error", http.StatusBadGateway, `{"error": "can not reach orchestrator service", "code": "upstream_error"}`, nil, "upstream_error"},
+		{"plain text error", http.StatusInternalServerError, "boom", nil, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			_, err := NewClient(server.URL).WeatherByCEP(context.Background(), "01001-000")
+			if tt.expected != nil {
+				if !errors.Is(err, tt.expected) {
+					t.Errorf("expected %v, but got %v", tt.expected, err)
+				}
+				return
+			}
+
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("expected an *APIError, but got %v", err)
+			}
+			if apiErr.StatusCode != tt.status || apiErr.Code != tt.code {
+				t.Errorf("expected (%d, %s), but got (%d, %s)", tt.status, tt.code, apiErr.StatusCode, apiErr.Code)
+			}
+		})
+	}
+}
+
+func TestWeatherByCEP_Timeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	t.Run("context deadline", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		if _, err := NewClient(server.URL).WeatherByCEP(ctx, "01001-000"); !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("expected context.DeadlineExceeded, but got %v", err)
+		}
+	})
+
+	t.Run("client timeout", func(t *testing.T) {
+		_, err := NewClient(server.URL, WithTimeout(50*time.Millisecond)).WeatherByCEP(context.Background(), "01001-000")
+		if err == nil {
+			t.Error("expected a timeout error, but got nil")
+		}
+	})
+}
+
+func TestWeatherByCEP_PropagatesTraceContext(t *testing.T) {
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() { otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator()) })
+
+	var gotTraceparent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotTraceparent = r.Header.Get("traceparent")
+		w.Write([]byte(`{"city": "São Paulo"}`))
+	}))
+	defer server.Close()
+
+	ctx, span := sdktrace.NewTracerProvider().Tracer("test").Start(context.Background(), "caller")
+	defer span.End()
+
+	if _, err := NewClient(server.URL).WeatherByCEP(ctx, "01001-000"); err != nil {
+		t.Fatalf("expected no error, but got: %v", err)
+	}
+
+	if gotTraceparent == "" {
+		t.Fatal("expected a traceparent header")
+	}
+	if traceID := span.SpanContext().TraceID().String(); len(gotTraceparent) < 35 || gotTraceparent[3:35] != traceID {
+		t.Errorf("expected traceparent with trace ID %s, but got %s", traceID, gotTraceparent)
+	}
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// Limites do http.Server contra clientes lentos e conexões presas
+	defaultServerReadHeaderTimeout = 5 * time.Second
+	defaultServerReadTimeout       = 10 * time.Second
+	defaultServerWriteTimeout      = 15 * time.Second
+	defaultServerIdleTimeout       = 60 * time.Second
+
+	// Drenagem das requisições em andamento e descarga dos spans no desligamento
+	defaultShutdownTimeout          = 5 * time.Second
+	defaultTelemetryShutdownTimeout = 10 * time.Second
+
+	defaultHTTPClientTimeout = 10 * time.Second
+	defaultDownstreamTimeout = 5 * time.Second
+
+	// Tamanho máximo do corpo das requisições POST
+	defaultMaxBodyBytes = 64 << 10
+
+	// Validade das respostas de clima no Cache-Control
+	defaultCacheMaxAge = 300 * time.Second
+)
+
+// Lê variáveis de ambiente numéricas; valores inválidos geram um aviso e caem no padrão
+func envFloat(logger *slog.Logger, key string, def float64) float64 {
+	v := os.Getenv(key)
+	if v == "" {
+		return def
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		logger.Warn("invalid env value, using default", "key", key, "value", v, "default", def)
+		return def
+	}
+	return f
+}
+
+// Aceita os formatos do strconv.ParseBool ("true", "1", "false"...)
+func envBool(logger *slog.Logger, key string, def bool) bool {
+	v := os.Getenv(key)
+	if v == "" {
+		return def
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		logger.Warn("invalid env value, using default", "key", key, "value", v, "default", def)
+		return def
+	}
+	return b
+}
+
+func envInt(logger *slog.Logger, key string, def int) int {
+	v := os.Getenv(key)
+	if v == "" {
+		return def
+	}
+	i, err := strconv.Atoi(v)
+	if err != nil {
+		logger.Warn("invalid env value, using default", "key", key, "value", v, "default", def)
+		return def
+	}
+	return i
+}
+
+// Durações no formato do time.ParseDuration ("500ms", "5s"...); valores inválidos, zero ou negativos caem no padrão
+func envDuration(logger *slog.Logger, key string, def time.Duration) time.Duration {
+	v := os.Getenv(key)
+	if v == "" {
+		return def
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d <= 0 {
+		logger.Warn("invalid env value, using default", "key", key, "value", v, "default", def.String())
+		return def
+	}
+	return d
+}
+
+// APP2_BASE_URLS (separadas por vírgula) tem precedência; sem ela, a instância única do APP2_BASE_URL
+func parseBaseURLs(list, single string) []string {
+	var urls []string
+	for _, u := range strings.Split(list, ",") {
+		if u = strings.TrimSpace(u); u != "" {
+			urls = append(urls, u)
+		}
+	}
+	if len(urls) == 0 && single != "" {
+		urls = []string{single}
+	}
+	return urls
+}
+
+// Valida na subida as variáveis sem as quais nenhuma requisição funcionaria (PORT vazio usa o padrão)
+func validateConfig(app2BaseURLs []string, port string) error {
+	var errs []error
+
+	if len(app2BaseURLs) == 0 {
+		errs = append(errs, errors.New("APP2_BASE_URL or APP2_BASE_URLS is required"))
+	}
+	for _, app2BaseURL := range app2BaseURLs {
+		if u, err := url.Parse(app2BaseURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			errs = append(errs, fmt.Errorf("app2 base URL must be an absolute http(s) URL, got %q", app2BaseURL))
+		}
+	}
+
+	if port != "" {
+		if n, err := strconv.Atoi(port); err != nil || n < 1 || n > 65535 {
+			errs = append(errs, fmt.Errorf("PORT must be a number between 1 and 65535, got %q", port))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func newHTTPServer(logger *slog.Logger, addr string, handler http.Handler) *http.Server {
+	return &http.Server{
+		Addr:              addr,
+		Handler:           handler,
+		ReadHeaderTimeout: envDuration(logger, "SERVER_READ_HEADER_TIMEOUT", defaultServerReadHeaderTimeout),
+		ReadTimeout:       envDuration(logger, "SERVER_READ_TIMEOUT", defaultServerReadTimeout),
+		WriteTimeout:      envDuration(logger, "SERVER_WRITE_TIMEOUT", defaultServerWriteTimeout),
+		IdleTimeout:       envDuration(logger, "SERVER_IDLE_TIMEOUT", defaultServerIdleTimeout),
+	}
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"log/slog"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestEnvDuration(t *testing.T) {
+	logger := slog.New(slog.DiscardHandler)
+
+	tests := []struct {
+		name  string
+		value string
+		want  time.Duration
+	}{
+		{name: "unset", value: "", want: 5 * time.Second},
+		{name: "valid", value: "750ms", want: 750 * time.Millisecond},
+		{name: "minutes", value: "1m", want: time.Minute},
+		{name: "invalid", value: "five seconds", want: 5 * time.Second},
+		{name: "missing unit", value: "5", want: 5 * time.Second},
+		{name: "zero", value: "0s", want: 5 * time.Second},
+		{name: "negative", value: "-1s", want: 5 * time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("TEST_TIMEOUT", tt.value)
+			if got := envDuration(logger, "TEST_TIMEOUT", 5*time.Second); got != tt.want {
+				t.Errorf("expected %s, but got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestValidateConfig(t *testing.T) {
+	tests := []struct {
+		name     string
+		baseURL  string
+		baseURLs string
+		port     string
+		wantErr  bool
+	}{
+		{name: "valid", baseURL: "http://app2:8083", port: "8080"},
+		{name: "valid https without port", baseURL: "https://app2.example.com"},
+		{name: "missing base URL", baseURL: "", port: "8080", wantErr: true},
+		{name: "relative base URL", baseURL: "app2:8083", wantErr: true},
+		{name: "path only", baseURL: "/get-weather-by-cep", wantErr: true},
+		{name: "unsupported scheme", baseURL: "ftp://app2", wantErr: true},
+		{name: "non numeric port", baseURL: "http://app2:8083", port: "http", wantErr: true},
+		{name: "port out of range", baseURL: "http://app2:8083", port: "70000", wantErr: true},
+		{name: "valid list", baseURLs: "http://app2-a:8083, http://app2-b:8083"},
+		{name: "list with relative URL", baseURLs: "http://app2-a:8083,app2-b:8083", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateConfig(parseBaseURLs(tt.baseURLs, tt.baseURL), tt.port)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("expected error: %v, but got: %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestNewHTTPServer_Timeouts(t *testing.T) {
+	logger := slog.New(slog.DiscardHandler)
+
+	t.Run("defaults", func(t *testing.T) {
+		server := newHTTPServer(logger, ":0", http.NotFoundHandler())
+
+		if server.ReadHeaderTimeout != 5*time.Second || server.ReadTimeout != 10*time.Second ||
+			server.WriteTimeout != 15*time.Second || server.IdleTimeout != 60*time.Second {
+			t.Errorf("unexpected defaults: header=%s read=%s write=%s idle=%s",
+				server.ReadHeaderTimeout, server.ReadTimeout, server.WriteTimeout, server.IdleTimeout)
+		}
+	})
+
+	t.Run("from env", func(t *testing.T) {
+		t.Setenv("SERVER_READ_HEADER_TIMEOUT", "2s")
+		t.Setenv("SERVER_READ_TIMEOUT", "3s")
+		t.Setenv("SERVER_WRITE_TIMEOUT", "30s")
+		t.Setenv("SERVER_IDLE_TIMEOUT", "invalid")
+		server := newHTTPServer(logger, ":0", http.NotFoundHandler())
+
+		if server.ReadHeaderTimeout != 2*time.Second || server.ReadTimeout != 3*time.Second ||
+			server.WriteTimeout != 30*time.Second || server.IdleTimeout != 60*time.Second {
+			t.Errorf("unexpected timeouts: header=%s read=%s write=%s idle=%s",
+				server.ReadHeaderTimeout, server.ReadTimeout, server.WriteTimeout, server.IdleTimeout)
+		}
+	})
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+)
+
+const (
+	corsAllowedMethods = "GET, POST, OPTIONS"
+	corsAllowedHeaders = "Content-Type, X-Request-ID"
+)
+
+type corsPolicy struct {
+	allowAll bool
+	origins  map[string]bool
+}
+
+// Lista separada por vírgulas; "*" libera qualquer origem
+func newCorsPolicy(allowedOrigins string) *corsPolicy {
+	p := &corsPolicy{origins: make(map[string]bool)}
+	for _, origin := range strings.Split(allowedOrigins, ",") {
+		origin = strings.TrimSpace(origin)
+		switch origin {
+		case "":
+		case "*":
+			p.allowAll = true
+		default:
+			p.origins[origin] = true
+		}
+	}
+	return p
+}
+
+func (p *corsPolicy) allowed(origin string) bool {
+	return p.allowAll || p.origins[origin]
+}
+
+func (app *application) cors(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		if origin != "" && app.corsPolicy != nil && app.corsPolicy.allowed(origin) {
+			h := w.Header()
+			if app.corsPolicy.allowAll {
+				h.Set("Access-Control-Allow-Origin", "*")
+			} else {
+				h.Set("Access-Control-Allow-Origin", origin)
+				h.Add("Vary", "Origin")
+			}
+			h.Set("Access-Control-Allow-Methods", corsAllowedMethods)
+			h.Set("Access-Control-Allow-Headers", corsAllowedHeaders)
+			h.Set("Access-Control-Expose-Headers", requestIDHeader+", "+traceIDHeader)
+		}
+
+		// Preflight não chega ao handler
+		if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
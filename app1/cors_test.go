@@ -0,0 +1,78 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCors(t *testing.T) {
+	app := newTestApplication()
+	app.corsPolicy = newCorsPolicy("https://allowed.example, https://other.example")
+
+	var reached bool
+	handler := app.cors(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reached = true
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	t.Run("allowed origin", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/weather-by-cep", nil)
+		req.Header.Set("Origin", "https://allowed.example")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		if got := rec.Header().Get("Access-Control-Allow-Origin"); got != "https://allowed.example" {
+			t.Errorf("expected origin to be echoed, but got '%s'", got)
+		}
+		if got := rec.Header().Get("Access-Control-Allow-Methods"); got != corsAllowedMethods {
+			t.Errorf("expected methods '%s', but got '%s'", corsAllowedMethods, got)
+		}
+	})
+
+	t.Run("disallowed origin", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/weather-by-cep", nil)
+		req.Header.Set("Origin", "https://evil.example")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		if got := rec.Header().Get("Access-Control-Allow-Origin"); got != "" {
+			t.Errorf("expected no CORS header, but got '%s'", got)
+		}
+	})
+
+	t.Run("preflight", func(t *testing.T) {
+		reached = false
+		req := httptest.NewRequest(http.MethodOptions, "/weather-by-cep", nil)
+		req.Header.Set("Origin", "https://allowed.example")
+		req.Header.Set("Access-Control-Request-Method", http.MethodPost)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusNoContent {
+			t.Errorf("expected status 204, but got %d", rec.Code)
+		}
+		if got := rec.Header().Get("Access-Control-Allow-Headers"); got != corsAllowedHeaders {
+			t.Errorf("expected headers '%s', but got '%s'", corsAllowedHeaders, got)
+		}
+		if reached {
+			t.Error("expected preflight to short-circuit the handler")
+		}
+	})
+}
+
+func TestCors_Wildcard(t *testing.T) {
+	app := newTestApplication()
+	app.corsPolicy = newCorsPolicy("*")
+
+	handler := app.cors(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	req := httptest.NewRequest(http.MethodGet, "/weather-by-cep", nil)
+	req.Header.Set("Origin", "https://any.example")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if got := rec.Header().Get("Access-Control-Allow-Origin"); got != "*" {
+		t.Errorf("expected '*', but got '%s'", got)
+	}
+}
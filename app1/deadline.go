@@ -0,0 +1,26 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// Prazo absoluto (RFC 3339, UTC) repassado ao app2 para que ele limite as próprias chamadas
+const requestDeadlineHeader = "X-Request-Deadline"
+
+// Deriva o contexto da chamada ao app2 do contexto da requisição: vale o menor prazo
+// entre o do cliente (se houver) e o máximo configurado
+func downstreamContext(ctx context.Context, max time.Duration) (context.Context, context.CancelFunc) {
+	deadline := time.Now().Add(max)
+	if parent, ok := ctx.Deadline(); ok && parent.Before(deadline) {
+		deadline = parent
+	}
+	return context.WithDeadline(ctx, deadline)
+}
+
+func setDeadlineHeader(req *http.Request) {
+	if deadline, ok := req.Context().Deadline(); ok {
+		req.Header.Set(requestDeadlineHeader, deadline.UTC().Format(time.RFC3339Nano))
+	}
+}
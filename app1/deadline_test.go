@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestDownstreamContext_UsesSmallerDeadline(t *testing.T) {
+	tests := []struct {
+		name     string
+		client   time.Duration
+		max      time.Duration
+		expected time.Duration
+	}{
+		{"client deadline is smaller", 2 * time.Second, 5 * time.Second, 2 * time.Second},
+		{"configured max is smaller", 10 * time.Second, 5 * time.Second, 5 * time.Second},
+		{"no client deadline", 0, 5 * time.Second, 5 * time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parent := context.Background()
+			if tt.client > 0 {
+				var cancel context.CancelFunc
+				parent, cancel = context.WithTimeout(parent, tt.client)
+				defer cancel()
+			}
+
+			ctx, cancel := downstreamContext(parent, tt.max)
+			defer cancel()
+
+			deadline, ok := ctx.Deadline()
+			if !ok {
+				t.Fatal("expected a deadline")
+			}
+
+			if remaining := time.Until(deadline); remaining > tt.expected || remaining < tt.expected-time.Second {
+				t.Errorf("expected remaining time close to %s, but got %s", tt.expected, remaining)
+			}
+		})
+	}
+}
+
+func TestHandler_ForwardsRequestDeadline(t *testing.T) {
+	var got string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Get(requestDeadlineHeader)
+		w.Write([]byte(`{"city": "São Paulo", "temp_C": 25, "temp_F": 77, "temp_K": 298.15}`))
+	}))
+	defer server.Close()
+	t.Setenv("APP2_BASE_URL", server.URL)
+
+	app := newTestApplication()
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	clientDeadline, _ := ctx.Deadline()
+
+	rec := httptest.NewRecorder()
+	app.handler(rec, httptest.NewRequest(http.MethodGet, "/weather-by-cep?cep=01001-000", nil).WithContext(ctx))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, but got %d", rec.Code)
+	}
+
+	deadline, err := time.Parse(time.RFC3339Nano, got)
+	if err != nil {
+		t.Fatalf("expected an RFC 3339 deadline header, but got '%s'", got)
+	}
+
+	if !deadline.Equal(clientDeadline) {
+		t.Errorf("expected deadline %s, but got %s", clientDeadline.UTC(), deadline)
+	}
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+)
+
+// Mesmos códigos do app2; os recebidos dele são repassados ao cliente
+const (
+	codeMissingCep           = "missing_cep"
+	codeInvalidZipcode       = "invalid_zipcode"
+	codeInvalidJSON          = "invalid_json"
+	codeCepNotFound          = "cep_not_found"
+	codeUpstreamError        = "upstream_error"
+	codeInternalError        = "internal_error"
+	codeMethodNotAllowed     = "method_not_allowed"
+	codeRateLimited          = "rate_limited"
+	codeTimeout              = "timeout"
+	codeUnsupportedMediaType = "unsupported_media_type"
+	codeBodyTooLarge         = "body_too_large"
+	codeBatchTooLarge        = "batch_too_large"
+)
+
+// Mensagens das respostas de erro. Podem mudar de texto; os clientes devem se basear no código
+const (
+	msgEmptyBody         = "empty request body"
+	msgMissingCepField   = "field 'cep' is required"
+	msgMissingCepParam   = "param 'cep' is required"
+	msgMissingCepsField  = "field 'ceps' is required"
+	msgMissingCep        = "cep is required"
+	msgBatchTooLarge     = "batch accepts at most %d ceps"
+	msgInvalidZipcode    = "invalid zipcode"
+	msgCepNotFound       = "can not find zipcode"
+	msgInvalidJSON       = "invalid JSON body: "
+	msgBodyTooLarge      = "request body must be at most %d bytes"
+	msgUnsupportedMedia  = "content type must be application/json"
+	msgMethodNotAllowed  = "method not allowed"
+	msgRateLimited       = "too many requests"
+	msgInternal          = "internal server error"
+	msgApp2Request       = "failed to create request to orchestrator service"
+	msgApp2Timeout       = "timeout on orchestrator service"
+	msgApp2Unreachable   = "can not reach orchestrator service"
+	msgApp2InvalidResp   = "invalid response from orchestrator service"
+	msgApp2WeatherFailed = "failed to find weather in orchestrator service"
+)
+
+// Limite de leitura do corpo de erro do app2
+const maxErrorBodySize = 4 << 10
+
+type errorResponse struct {
+	Error string `json:"error"`
+	Code  string `json:"code"`
+}
+
+// Erro de uma consulta, com o status e o código a devolver ao cliente
+type apiError struct {
+	status int
+	code   string
+	msg    string
+	// Retry-After do app2 (limite da WeatherAPI), repassado ao cliente
+	retryAfter string
+}
+
+func writeJSONError(w http.ResponseWriter, status int, code, msg string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	// Erros nunca devem ser reaproveitados por clientes ou CDNs
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(errorResponse{Error: msg, Code: code})
+}
+
+// Lê o erro estruturado do app2; ok é falso quando o corpo não segue o formato (ex.: texto puro)
+func decodeApp2Error(body io.Reader) (errorResponse, bool) {
+	var e errorResponse
+	if err := json.NewDecoder(io.LimitReader(body, maxErrorBodySize)).Decode(&e); err != nil || e.Code == "" {
+		return errorResponse{}, false
+	}
+	return e, true
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const defaultETagCacheMaxEntries = 1000
+
+type etagEntry struct {
+	etag      string
+	response  Response
+	expiresAt time.Time
+}
+
+// Respostas do app2 guardadas com o ETag, para enviar o If-None-Match e reaproveitar o corpo no 304.
+// Cada entrada vale pelo max-age do Cache-Control do app2 (seguro para uso concorrente)
+type etagCache struct {
+	mu         sync.Mutex
+	maxEntries int
+	entries    map[string]etagEntry
+	now        func() time.Time
+}
+
+func newETagCache(maxEntries int) *etagCache {
+	return &etagCache{
+		maxEntries: maxEntries,
+		entries:    make(map[string]etagEntry),
+		now:        time.Now,
+	}
+}
+
+func (c *etagCache) get(key string) (etagEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok {
+		return etagEntry{}, false
+	}
+	if !c.now().Before(entry.expiresAt) {
+		delete(c.entries, key)
+		return etagEntry{}, false
+	}
+	return entry, true
+}
+
+// Sem ETag ou sem max-age positivo, a resposta não é guardada (e a anterior, se houver, é descartada)
+func (c *etagCache) set(key, etag string, response Response, cacheControl string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	maxAge, ok := parseMaxAge(cacheControl)
+	if etag == "" || !ok {
+		delete(c.entries, key)
+		return
+	}
+
+	now := c.now()
+	if _, exists := c.entries[key]; !exists && len(c.entries) >= c.maxEntries {
+		c.evict(now)
+	}
+	c.entries[key] = etagEntry{etag: etag, response: response, expiresAt: now.Add(maxAge)}
+}
+
+// Remove as entradas expiradas e, se ainda estiver cheio, a que expira primeiro
+func (c *etagCache) evict(now time.Time) {
+	var oldestKey string
+	var oldest time.Time
+	for key, entry := range c.entries {
+		if !now.Before(entry.expiresAt) {
+			delete(c.entries, key)
+			continue
+		}
+		if oldestKey == "" || entry.expiresAt.Before(oldest) {
+			oldestKey, oldest = key, entry.expiresAt
+		}
+	}
+
+	if len(c.entries) >= c.maxEntries && oldestKey != "" {
+		delete(c.entries, oldestKey)
+	}
+}
+
+// max-age do Cache-Control; no-store, no-cache ou ausente não permitem guardar a resposta
+func parseMaxAge(cacheControl string) (time.Duration, bool) {
+	maxAge := time.Duration(0)
+	for _, directive := range strings.Split(cacheControl, ",") {
+		directive = strings.ToLower(strings.TrimSpace(directive))
+		if directive == "no-store" || directive == "no-cache" {
+			return 0, false
+		}
+		if v, ok := strings.CutPrefix(directive, "max-age="); ok {
+			seconds, err := strconv.Atoi(v)
+			if err != nil {
+				return 0, false
+			}
+			maxAge = time.Duration(seconds) * time.Second
+		}
+	}
+	return maxAge, maxAge > 0
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// app2 com ETag: responde 304 quando o If-None-Match é o ETag atual
+func newETagApp2(t *testing.T, notModified *atomic.Int32) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"v1"`)
+		w.Header().Set("Cache-Control", "public, max-age=300")
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			notModified.Add(1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Write([]byte(`{"city": "São Paulo", "temp_C": 25}`))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestFetchWeather_ETagRevalidation(t *testing.T) {
+	var notModified atomic.Int32
+	t.Setenv("APP2_BASE_URL", newETagApp2(t, &notModified).URL)
+
+	app := newTestApplication()
+	app.etagCache = newETagCache(10)
+
+	for i := range 2 {
+		rec := httptest.NewRecorder()
+		app.handler(rec, httptest.NewRequest(http.MethodGet, "/weather-by-cep?cep=01001000", nil))
+
+		if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "São Paulo") {
+			t.Fatalf("request %d: expected the weather response, but got %d: %s", i, rec.Code, rec.Body.String())
+		}
+	}
+
+	// A segunda consulta é condicional e reaproveita o corpo da primeira
+	if got := notModified.Load(); got != 1 {
+		t.Errorf("expected 1 not modified response from app2, but got %d", got)
+	}
+}
+
+func TestFetchWeather_NoConditionalRequestsByDefault(t *testing.T) {
+	var notModified atomic.Int32
+	t.Setenv("APP2_BASE_URL", newETagApp2(t, &notModified).URL)
+
+	app := newTestApplication()
+	for range 2 {
+		app.handler(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/weather-by-cep?cep=01001000", nil))
+	}
+
+	if got := notModified.Load(); got != 0 {
+		t.Errorf("expected no conditional requests, but app2 answered %d with 304", got)
+	}
+}
+
+func TestETagCache_Expiry(t *testing.T) {
+	now := time.Date(2026, time.January, 1, 12, 0, 0, 0, time.UTC)
+	cache := newETagCache(10)
+	cache.now = func() time.Time { return now }
+
+	cache.set("01001-000", `"v1"`, Response{City: "São Paulo"}, "public, max-age=60")
+	if entry, ok := cache.get("01001-000"); !ok || entry.etag != `"v1"` {
+		t.Fatalf("expected the cached entry, but got %v, %v", entry, ok)
+	}
+
+	now = now.Add(time.Minute)
+	if _, ok := cache.get("01001-000"); ok {
+		t.Error("expected the entry to expire after max-age")
+	}
+}
+
+func TestETagCache_SkipsUncacheable(t *testing.T) {
+	tests := []struct {
+		name         string
+		etag         string
+		cacheControl string
+	}{
+		{"no etag", "", "public, max-age=60"},
+		{"no-store", `"v1"`, "no-store"},
+		{"no max-age", `"v1"`, "public"},
+		{"zero max-age", `"v1"`, "max-age=0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cache := newETagCache(10)
+			cache.set("01001-000", tt.etag, Response{City: "São Paulo"}, tt.cacheControl)
+
+			if _, ok := cache.get("01001-000"); ok {
+				t.Error("expected the response not to be cached")
+			}
+		})
+	}
+}
+
+func TestETagCache_MaxEntries(t *testing.T) {
+	cache := newETagCache(2)
+	for _, cep := range []string{"01001-000", "02002-000", "03003-000"} {
+		cache.set(cep, `"v1"`, Response{}, "max-age=60")
+	}
+
+	if got := len(cache.entries); got != 2 {
+		t.Errorf("expected 2 entries, but got %d", got)
+	}
+}
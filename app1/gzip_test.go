@@ -0,0 +1,99 @@
+package main
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestGzipResponses_CompressesLargeBodies(t *testing.T) {
+	cities := make([]string, 200)
+	for i := range cities {
+		cities[i] = "São Paulo"
+	}
+	handler := gzipResponses(defaultGzipMinSize)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusMultiStatus)
+		json.NewEncoder(w).Encode(map[string][]string{"cities": cities})
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/weather-by-cep/batch", nil)
+	req.Header.Set("Accept-Encoding", "gzip, deflate")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusMultiStatus {
+		t.Errorf("expected status 207, but got %d", rec.Code)
+	}
+	if got := rec.Header().Get("Content-Encoding"); got != "gzip" {
+		t.Fatalf("expected Content-Encoding 'gzip', but got '%s'", got)
+	}
+
+	gz, err := gzip.NewReader(rec.Body)
+	if err != nil {
+		t.Fatalf("expected a gzip body, but got: %v", err)
+	}
+	var body map[string][]string
+	if err := json.NewDecoder(gz).Decode(&body); err != nil {
+		t.Fatalf("expected JSON after decompressing, but got: %v", err)
+	}
+	if len(body["cities"]) != len(cities) || body["cities"][0] != "São Paulo" {
+		t.Errorf("expected the original JSON, but got %v", body)
+	}
+}
+
+func TestGzipResponses_SkipsSmallBodiesAndOtherEncodings(t *testing.T) {
+	tests := []struct {
+		name           string
+		acceptEncoding string
+		body           string
+	}{
+		{"small body", "gzip", `{"city": "São Paulo"}`},
+		{"gzip not accepted", "", strings.Repeat("a", 2*defaultGzipMinSize)},
+		{"gzip refused", "gzip;q=0, br", strings.Repeat("a", 2*defaultGzipMinSize)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := gzipResponses(defaultGzipMinSize)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				io.WriteString(w, tt.body)
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, "/weather-by-cep", nil)
+			req.Header.Set("Accept-Encoding", tt.acceptEncoding)
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if got := rec.Header().Get("Content-Encoding"); got != "" {
+				t.Errorf("expected no Content-Encoding, but got '%s'", got)
+			}
+			if rec.Body.String() != tt.body {
+				t.Errorf("expected the body unchanged, but got %q", rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestGzipResponses_StatusRecorder(t *testing.T) {
+	handler := gzipResponses(16)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		io.WriteString(w, strings.Repeat("a", 1024))
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/weather-by-cep", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	rec := &statusRecorder{ResponseWriter: httptest.NewRecorder(), status: http.StatusOK}
+	handler.ServeHTTP(rec, req)
+
+	// O status chega ao statusRecorder, e os bytes contados são os comprimidos
+	if rec.status != http.StatusNotFound {
+		t.Errorf("expected status 404, but got %d", rec.status)
+	}
+	if rec.bytes == 0 || rec.bytes >= 1024 {
+		t.Errorf("expected the compressed size, but got %d bytes", rec.bytes)
+	}
+}
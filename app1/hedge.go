@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Tempo de espera pela instância anterior do APP2_BASE_URLS antes de acionar a seguinte
+const defaultHedgeDelay = 200 * time.Millisecond
+
+type hedgeResult struct {
+	idx  int
+	resp *http.Response
+	err  error
+}
+
+// O app2 respondeu sem falhar; um 4xx (CEP não encontrado, por exemplo) é uma resposta válida
+func (r hedgeResult) succeeded() bool {
+	return r.err == nil && r.resp.StatusCode < http.StatusInternalServerError
+}
+
+// Envia reqs[0] e, a cada delay sem resposta bem-sucedida, a próxima da lista; uma falha aciona a
+// próxima na hora. A primeira resposta bem-sucedida vence e as demais são canceladas (cancels[i]
+// é o contexto de reqs[i]). Sem vencedora, devolve o último resultado. O corpo da resposta
+// devolvida continua legível até o chamador cancelar o contexto dela. connFailed recebe o índice das
+// requisições que falharam na conexão sem terem sido canceladas
+func hedgedDo(client *http.Client, span trace.Span, reqs []*http.Request, cancels []context.CancelFunc, delay time.Duration, connFailed func(int)) (*http.Response, error) {
+	results := make(chan hedgeResult, len(reqs))
+	next, pending := 0, 0
+	launch := func() {
+		i := next
+		if i > 0 {
+			span.AddEvent("hedged request", trace.WithAttributes(attribute.Int("app2.instance", i)))
+		}
+		go func() {
+			resp, err := client.Do(reqs[i])
+			results <- hedgeResult{idx: i, resp: resp, err: err}
+		}()
+		next++
+		pending++
+	}
+
+	launch()
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	var last hedgeResult
+	for pending > 0 {
+		select {
+		case r := <-results:
+			pending--
+			if r.succeeded() {
+				for i, cancel := range cancels {
+					if i != r.idx {
+						cancel()
+					}
+				}
+				go discardResults(results, pending)
+				return r.resp, nil
+			}
+
+			if r.err != nil && reqs[r.idx].Context().Err() == nil {
+				connFailed(r.idx)
+			}
+			if last.resp != nil {
+				last.resp.Body.Close()
+			}
+			last = r
+			if next < len(reqs) {
+				launch()
+				timer.Reset(delay)
+			}
+		case <-timer.C:
+			if next < len(reqs) {
+				launch()
+				timer.Reset(delay)
+			}
+		}
+	}
+
+	return last.resp, last.err
+}
+
+// Fecha os corpos das respostas que chegarem depois da vencedora
+func discardResults(results <-chan hedgeResult, n int) {
+	for range n {
+		if r := <-results; r.resp != nil {
+			r.resp.Body.Close()
+		}
+	}
+}
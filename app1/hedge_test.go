@@ -0,0 +1,122 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// Réplica que só responde depois de delay; cancelled é fechado se o app1 desistir antes
+func newSlowApp2(t *testing.T, delay time.Duration, cancelled chan struct{}) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(delay):
+			w.Write([]byte(`{"city": "Lenta"}`))
+		case <-r.Context().Done():
+			close(cancelled)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestHedgedRequest_FastInstanceWins(t *testing.T) {
+	cancelled := make(chan struct{})
+	slow := newSlowApp2(t, 2*time.Second, cancelled)
+	fast := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"city": "Rápida"}`))
+	}))
+	defer fast.Close()
+
+	app := newTestApplication()
+	app.app2Backends = newBackendPool([]string{slow.URL, fast.URL}, time.Minute)
+	app.hedgeDelay = 20 * time.Millisecond
+
+	start := time.Now()
+	rec := httptest.NewRecorder()
+	app.handler(rec, httptest.NewRequest(http.MethodGet, "/weather-by-cep?cep=01001000", nil))
+
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "Rápida") {
+		t.Fatalf("expected the fast instance's response, but got %d: %s", rec.Code, rec.Body.String())
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("expected not to wait for the slow instance, but took %s", elapsed)
+	}
+
+	select {
+	case <-cancelled:
+	case <-time.After(time.Second):
+		t.Error("expected the slow instance's request to be canceled")
+	}
+}
+
+func TestHedgedRequest_NoHedgeWhenPrimaryResponds(t *testing.T) {
+	var secondary atomic.Int32
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"city": "São Paulo"}`))
+	}))
+	defer primary.Close()
+	backup := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		secondary.Add(1)
+	}))
+	defer backup.Close()
+
+	app := newTestApplication()
+	app.app2Backends = newBackendPool([]string{primary.URL, backup.URL}, time.Minute)
+	app.hedgeDelay = 500 * time.Millisecond
+
+	rec := httptest.NewRecorder()
+	app.handler(rec, httptest.NewRequest(http.MethodGet, "/weather-by-cep?cep=01001000", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, but got %d", rec.Code)
+	}
+	if got := secondary.Load(); got != 0 {
+		t.Errorf("expected no hedged request, but the second instance got %d", got)
+	}
+}
+
+func TestHedgedRequest_FailoverOnServerError(t *testing.T) {
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeJSONError(w, http.StatusInternalServerError, codeUpstreamError, "boom")
+	}))
+	defer failing.Close()
+	healthy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"city": "São Paulo"}`))
+	}))
+	defer healthy.Close()
+
+	app := newTestApplication()
+	app.app2Backends = newBackendPool([]string{failing.URL, healthy.URL}, time.Minute)
+	// Maior que o timeout do teste: a segunda instância só pode ter sido acionada pela falha da primeira
+	app.hedgeDelay = time.Minute
+
+	rec := httptest.NewRecorder()
+	app.handler(rec, httptest.NewRequest(http.MethodGet, "/weather-by-cep?cep=01001000", nil))
+
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "São Paulo") {
+		t.Errorf("expected the healthy instance's response, but got %d: %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestHedgedRequest_AllInstancesFail(t *testing.T) {
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeJSONError(w, http.StatusBadGateway, codeUpstreamError, "boom")
+	}))
+	defer failing.Close()
+
+	app := newTestApplication()
+	app.app2Backends = newBackendPool([]string{failing.URL, failing.URL}, time.Minute)
+	app.hedgeDelay = 10 * time.Millisecond
+
+	rec := httptest.NewRecorder()
+	app.handler(rec, httptest.NewRequest(http.MethodGet, "/weather-by-cep?cep=01001000", nil))
+
+	if rec.Code != http.StatusBadGateway {
+		t.Errorf("expected status 502, but got %d", rec.Code)
+	}
+}
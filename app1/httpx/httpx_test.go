@@ -0,0 +1,188 @@
+package httpx
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestNewInstrumentedClient_Defaults(t *testing.T) {
+	client := NewInstrumentedClient()
+
+	if client.Timeout != defaultTimeout {
+		t.Errorf("expected timeout %v, but got %v", defaultTimeout, client.Timeout)
+	}
+
+	if _, ok := client.Transport.(*otelhttp.Transport); !ok {
+		t.Fatalf("expected an otelhttp transport, but got %T", client.Transport)
+	}
+}
+
+func TestNewInstrumentedClient_Options(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     []Option
+		expected time.Duration
+	}{
+		{"custom timeout", []Option{WithTimeout(3 * time.Second)}, 3 * time.Second},
+		{"zero timeout keeps default", []Option{WithTimeout(0)}, defaultTimeout},
+		{"negative timeout keeps default", []Option{WithTimeout(-time.Second)}, defaultTimeout},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NewInstrumentedClient(tt.opts...).Timeout; got != tt.expected {
+				t.Errorf("expected timeout %v, but got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+// Captura o transporte de base montado pelo factory
+func baseTransport(opts ...Option) *http.Transport {
+	var got *http.Transport
+	opts = append(opts, WithMiddleware(func(next http.RoundTripper) http.RoundTripper {
+		got = next.(*http.Transport)
+		return next
+	}))
+	NewInstrumentedClient(opts...)
+	return got
+}
+
+func TestNewInstrumentedClient_TransportConfig(t *testing.T) {
+	tests := []struct {
+		name     string
+		cfg      TransportConfig
+		expected TransportConfig
+	}{
+		{"defaults", TransportConfig{}, TransportConfig{100, 20, 90 * time.Second}},
+		{"custom", TransportConfig{200, 50, 30 * time.Second}, TransportConfig{200, 50, 30 * time.Second}},
+		{"partial", TransportConfig{MaxIdleConnsPerHost: 5}, TransportConfig{100, 5, 90 * time.Second}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := baseTransport(WithTransportConfig(tt.cfg))
+
+			if got == http.DefaultTransport {
+				t.Fatal("expected a clone, not the shared http.DefaultTransport")
+			}
+
+			actual := TransportConfig{got.MaxIdleConns, got.MaxIdleConnsPerHost, got.IdleConnTimeout}
+			if actual != tt.expected {
+				t.Errorf("expected %+v, but got %+v", tt.expected, actual)
+			}
+		})
+	}
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+func TestNewInstrumentedClient_MiddlewareOrder(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	var order []string
+	wrap := func(name string) Option {
+		return WithMiddleware(func(next http.RoundTripper) http.RoundTripper {
+			return roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+				order = append(order, name)
+				return next.RoundTrip(r)
+			})
+		})
+	}
+
+	client := NewInstrumentedClient(wrap("first"), wrap("second"))
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("expected no error, but got: %v", err)
+	}
+	resp.Body.Close()
+
+	// O último informado é o mais externo
+	if len(order) != 2 || order[0] != "second" || order[1] != "first" {
+		t.Errorf("expected [second first], but got %v", order)
+	}
+}
+
+func TestNewInstrumentedClient_BaseTransport(t *testing.T) {
+	called := false
+	base := roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		called = true
+		return &http.Response{StatusCode: http.StatusNoContent, Body: http.NoBody, Request: r}, nil
+	})
+
+	resp, err := NewInstrumentedClient(WithBaseTransport(base)).Get("http://example.invalid")
+	if err != nil {
+		t.Fatalf("expected no error, but got: %v", err)
+	}
+	resp.Body.Close()
+
+	if !called || resp.StatusCode != http.StatusNoContent {
+		t.Errorf("expected the custom base transport to be used, but got called=%v status=%d", called, resp.StatusCode)
+	}
+}
+
+func TestNewInstrumentedClient_RedactsSpanURL(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("key") != "secret" {
+			t.Errorf("expected the real key to reach the server, but got '%s'", r.URL.Query().Get("key"))
+		}
+	}))
+	defer server.Close()
+
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	ctx, parent := tp.Tracer("test").Start(context.Background(), "parent")
+
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/current.json?key=secret&q=x", nil)
+	resp, err := NewInstrumentedClient(WithRedactedQueryParams("key")).Do(req)
+	if err != nil {
+		t.Fatalf("expected no error, but got: %v", err)
+	}
+	resp.Body.Close()
+	parent.End()
+
+	for _, span := range recorder.Ended() {
+		for _, attr := range span.Attributes() {
+			if v := attr.Value.Emit(); v != "" && containsSecret(v) {
+				t.Errorf("expected the key to be redacted, but span %s has %s=%s", span.Name(), attr.Key, v)
+			}
+		}
+	}
+}
+
+func containsSecret(v string) bool {
+	u, err := url.Parse(v)
+	return err == nil && u.Query().Get("key") == "secret"
+}
+
+func TestRedactQuery(t *testing.T) {
+	tests := []struct {
+		raw      string
+		expected string
+		ok       bool
+	}{
+		{"http://api/current.json?key=secret&q=x", "http://api/current.json?key=%2A%2A%2A&q=x", true},
+		{"http://api/current.json?q=x", "http://api/current.json?q=x", false},
+	}
+
+	for _, tt := range tests {
+		u, _ := url.Parse(tt.raw)
+		got, ok := RedactQuery(u, "key")
+		if got != tt.expected || ok != tt.ok {
+			t.Errorf("expected (%s, %v), but got (%s, %v)", tt.expected, tt.ok, got, ok)
+		}
+	}
+}
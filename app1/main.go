@@ -3,29 +3,55 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"encoding/xml"
 	"errors"
 	"fmt"
-	"log"
+	"io"
+	"log/slog"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
-	"regexp"
 	"syscall"
 	"time"
 
+	"l02-01/backoff"
+	"l02-01/cep"
+	"l02-01/httpx"
+	"l02-01/metrics"
 	"l02-01/telemetry"
+	"l02-01/version"
 
 	"github.com/joho/godotenv"
-	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
 	"go.opentelemetry.io/otel/trace"
 )
 
-const regextCepPattern = `^[0-9]{5}-[0-9]{3}$`
-
 type application struct {
-	logger     *log.Logger
+	logger     *slog.Logger
 	tracer     trace.Tracer
 	httpClient *http.Client
+	metrics    *metrics.Metrics
+	limiter    *rateLimiter
+	corsPolicy *corsPolicy
+	// Tempo máximo da chamada ao app2
+	downstreamTimeout time.Duration
+	// Chamadas simultâneas ao app2 por requisição do lote
+	batchConcurrency int
+	// Limite do corpo dos POSTs, aplicado antes da decodificação
+	maxBodyBytes int64
+	// Novas tentativas da chamada ao app2 (APP2_MAX_RETRIES)
+	app2Retry backoff.Policy
+	// Réplicas do app2 (APP2_BASE_URLS), em rodízio e com hedgedDo; nil usa o APP2_BASE_URL
+	app2Backends *backendPool
+	hedgeDelay   time.Duration
+	// max-age do Cache-Control das respostas de sucesso
+	cacheMaxAge time.Duration
+	// Respostas do app2 para as requisições condicionais (If-None-Match); nil desabilita
+	etagCache *etagCache
+	// Tamanho mínimo do corpo para a compressão gzip
+	gzipMinSize int
 }
 
 type Request struct {
@@ -33,51 +59,98 @@ type Request struct {
 }
 
 type Response struct {
-	City  string  `json:"city"`
-	TempC float64 `json:"temp_C"`
-	TempF float64 `json:"temp_F"`
-	TempK float64 `json:"temp_K"`
+	XMLName xml.Name `json:"-" xml:"weather"`
+	City    string   `json:"city" xml:"city"`
+	State   string   `json:"state,omitempty" xml:"state,omitempty"`
+	Street  string   `json:"street,omitempty" xml:"street,omitempty"`
+	Cep     string   `json:"cep,omitempty" xml:"cep,omitempty"`
+	// Ausentes apenas no modo degradado, quando o clima não pôde ser obtido
+	TempC *float64 `json:"temp_C,omitempty" xml:"temp_C,omitempty"`
+	TempF *float64 `json:"temp_F,omitempty" xml:"temp_F,omitempty"`
+	TempK *float64 `json:"temp_K,omitempty" xml:"temp_K,omitempty"`
+	// Omitidos quando a WeatherAPI não os informa
+	Humidity  *int     `json:"humidity,omitempty" xml:"humidity,omitempty"`
+	WindKph   *float64 `json:"wind_kph,omitempty" xml:"wind_kph,omitempty"`
+	Condition string   `json:"condition,omitempty" xml:"condition,omitempty"`
+	// Desambiguam cidades homônimas em estados diferentes
+	Region  string `json:"region,omitempty" xml:"region,omitempty"`
+	Country string `json:"country,omitempty" xml:"country,omitempty"`
+	// Com WEATHERAPI_AQI_ENABLED no app2
+	AirQuality *airQuality `json:"air_quality,omitempty" xml:"air_quality,omitempty"`
+	Partial    bool        `json:"partial,omitempty" xml:"partial,omitempty"`
+}
+
+// Resumo da qualidade do ar; us_epa_index vai de 1 (boa) a 6 (perigosa)
+type airQuality struct {
+	PM25       float64 `json:"pm2_5" xml:"pm2_5"`
+	PM10       float64 `json:"pm10" xml:"pm10"`
+	USEPAIndex int     `json:"us_epa_index" xml:"us_epa_index"`
 }
 
 type loggingRoundTripper struct {
-	logger *log.Logger
+	logger *slog.Logger
 	next   http.RoundTripper
 }
 
-var cepRegex = regexp.MustCompile(regextCepPattern)
-
 func main() {
 	godotenv.Load()
 
-	logger := log.New(os.Stderr, "INFO: ", log.Ldate|log.Ltime|log.Lshortfile)
+	level, ok := parseLogLevel(os.Getenv("LOG_LEVEL"))
+	logger := newLogger(os.Stderr, level)
+	// Redireciona também o pacote log (usado pela telemetria) para o mesmo handler
+	slog.SetDefault(logger)
+	if !ok {
+		logger.Warn("invalid LOG_LEVEL, using info", "value", os.Getenv("LOG_LEVEL"))
+	}
+
+	app2BaseURLs := parseBaseURLs(os.Getenv("APP2_BASE_URLS"), os.Getenv("APP2_BASE_URL"))
+	if err := validateConfig(app2BaseURLs, os.Getenv("PORT")); err != nil {
+		logger.Error("invalid configuration", "error", err)
+		os.Exit(1)
+	}
 
-	tracer, shutdown, err := telemetry.InitTelemetry("app1-service", "app1-tracer")
+	tracer, meter, shutdown, err := telemetry.InitTelemetry("app1-service", "app1-tracer", "app1-meter")
 	if err != nil {
-		log.Fatalf("ERROR: Failed to initialize telemetry: %v", err)
+		logger.Error("failed to initialize telemetry", "error", err)
+		os.Exit(1)
 	}
-	defer func() {
-		logger.Println("INFO: Shutting down telemetry...")
-		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-		defer cancel()
-		if err := shutdown(ctx); err != nil {
-			logger.Printf("ERROR: Failed to shutdown telemetry gracefully: %v", err)
-		}
-		logger.Println("INFO: Telemetry shut down.")
-	}()
 
-	baseTransport := http.DefaultTransport
-	loggingTransport := &loggingRoundTripper{
-		logger: logger,
-		next:   baseTransport,
+	httpClient := httpx.NewInstrumentedClient(
+		httpx.WithTimeout(envDuration(logger, "HTTP_CLIENT_TIMEOUT", defaultHTTPClientTimeout)),
+		httpx.WithMiddleware(func(next http.RoundTripper) http.RoundTripper {
+			return &loggingRoundTripper{logger: logger, next: next}
+		}),
+	)
+
+	app := newApplication(logger, tracer, httpClient)
+	app.metrics = metrics.New(metrics.WithMeter(meter))
+	app.corsPolicy = newCorsPolicy(os.Getenv("CORS_ALLOWED_ORIGINS"))
+	app.downstreamTimeout = envDuration(logger, "APP1_DOWNSTREAM_TIMEOUT", defaultDownstreamTimeout)
+	app.batchConcurrency = envInt(logger, "BATCH_CONCURRENCY", defaultBatchConcurrency)
+	app.maxBodyBytes = int64(envInt(logger, "MAX_REQUEST_BODY_BYTES", defaultMaxBodyBytes))
+	if app.maxBodyBytes <= 0 {
+		logger.Warn("MAX_REQUEST_BODY_BYTES must be positive, using default", "value", app.maxBodyBytes, "default", defaultMaxBodyBytes)
+		app.maxBodyBytes = defaultMaxBodyBytes
 	}
 
-	otelTransport := otelhttp.NewTransport(loggingTransport)
-	httpClient := &http.Client{Transport: otelTransport, Timeout: 10 * time.Second}
+	app.cacheMaxAge = envDuration(logger, "CACHE_MAX_AGE", defaultCacheMaxAge)
+	app.gzipMinSize = envInt(logger, "GZIP_MIN_SIZE", defaultGzipMinSize)
+	app.app2Backends = newBackendPool(app2BaseURLs, envDuration(logger, "APP2_BACKEND_COOLDOWN", defaultBackendCooldown))
+	app.hedgeDelay = envDuration(logger, "APP2_HEDGE_DELAY", defaultHedgeDelay)
+	if retries := envInt(logger, "APP2_MAX_RETRIES", defaultApp2MaxRetries); retries >= 0 {
+		app.app2Retry = newRetryPolicy(retries)
+	} else {
+		logger.Warn("APP2_MAX_RETRIES must not be negative, using default", "value", retries, "default", defaultApp2MaxRetries)
+	}
 
-	app := &application{
-		logger:     logger,
-		tracer:     tracer,
-		httpClient: httpClient,
+	// RATE_LIMIT_RPS=0 desabilita o limite
+	if rps := envFloat(logger, "RATE_LIMIT_RPS", defaultRateLimitRPS); rps > 0 {
+		app.limiter = newRateLimiter(rps, envInt(logger, "RATE_LIMIT_BURST", defaultRateLimitBurst))
+	}
+
+	// Requer o ETAG_ENABLED no app2; sem ETag nas respostas, nada é guardado
+	if envBool(logger, "APP2_ETAG_ENABLED", false) {
+		app.etagCache = newETagCache(defaultETagCacheMaxEntries)
 	}
 
 	port := os.Getenv("PORT")
@@ -85,12 +158,15 @@ func main() {
 		port = "8080"
 	}
 
-	mux := http.NewServeMux()
-	mux.Handle("/weather-by-cep", app.logRequest(http.HandlerFunc(app.handler)))
+	server := newHTTPServer(logger, ":"+port, app.routes())
 
-	server := &http.Server{
-		Addr:    ":" + port,
-		Handler: mux,
+	// Perfis do net/http/pprof em um listener separado, desligados por padrão
+	if envBool(logger, "ENABLE_PPROF", false) {
+		pprofAddr := os.Getenv("PPROF_ADDR")
+		if pprofAddr == "" {
+			pprofAddr = defaultPprofAddr
+		}
+		startPprofServer(logger, pprofAddr)
 	}
 
 	// (Ctrl+C)
@@ -99,114 +175,357 @@ func main() {
 
 	// Inicia o servidor em uma goroutine para não bloquear a execução
 	go func() {
-		app.logger.Printf("Server listening on port %s", port)
+		app.logger.Info("server listening", "port", port)
 		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
-			log.Fatalf("ERROR: Could not start server: %v", err)
+			app.logger.Error("could not start server", "error", err)
+			os.Exit(1)
 		}
 	}()
 
 	// Bloqueia a execução até que um sinal de interrupção seja recebido
 	<-stop
 
-	app.logger.Println("Shutting down server...")
-	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
+	if err := gracefulShutdown(logger, server, shutdown,
+		envDuration(logger, "SHUTDOWN_TIMEOUT", defaultShutdownTimeout),
+		envDuration(logger, "TELEMETRY_SHUTDOWN_TIMEOUT", defaultTelemetryShutdownTimeout),
+	); err != nil {
+		os.Exit(1)
+	}
+}
 
-	if err := server.Shutdown(shutdownCtx); err != nil {
-		log.Fatalf("ERROR: Failed to shut down server gracefully: %v", err)
+// Dependências obrigatórias; os demais campos ficam com os padrões (sem CORS e sem limite de requisições)
+func newApplication(logger *slog.Logger, tracer trace.Tracer, httpClient *http.Client) *application {
+	return &application{
+		logger:     logger,
+		tracer:     tracer,
+		httpClient: httpClient,
+		metrics:    metrics.New(),
+
+		downstreamTimeout: defaultDownstreamTimeout,
+		batchConcurrency:  defaultBatchConcurrency,
+		maxBodyBytes:      defaultMaxBodyBytes,
+		hedgeDelay:        defaultHedgeDelay,
+		app2Retry:         newRetryPolicy(defaultApp2MaxRetries),
+		cacheMaxAge:       defaultCacheMaxAge,
+		gzipMinSize:       defaultGzipMinSize,
 	}
+}
 
-	app.logger.Println("Server shut down.")
+func (app *application) routes() http.Handler {
+	mux := http.NewServeMux()
+	// O span do servidor (como no app2) é o que o traceID devolve no cabeçalho
+	mux.Handle("/weather-by-cep", chain(http.HandlerFunc(app.handler),
+		app.instrumentRoute("/weather-by-cep"),
+		app.requestID,
+		app.cors,
+		methods(http.MethodGet, http.MethodPost),
+		app.rateLimit,
+		app.logRequest,
+		gzipResponses(app.gzipMinSize),
+		otelServer("/app1-server"),
+		traceID,
+	))
+	mux.Handle("/weather-by-cep/batch", chain(http.HandlerFunc(app.batchHandler),
+		app.instrumentRoute("/weather-by-cep/batch"),
+		app.requestID,
+		app.cors,
+		methods(http.MethodPost),
+		app.rateLimit,
+		app.logRequest,
+		gzipResponses(app.gzipMinSize),
+		otelServer("/app1-batch-server"),
+		traceID,
+	))
+	// Fora do logRequest/tracing para não poluir os traces com as sondas
+	mux.HandleFunc("/health", app.healthHandler)
+	mux.HandleFunc("/version", versionHandler)
+	mux.Handle("/metrics", app.metrics.Handler())
+
+	return app.recoverPanics(mux)
 }
 
-// Algo parecido como log de acesso
+// Log de acesso: uma linha por requisição, depois da resposta, com o status, a duração e o tamanho do corpo
 func (app *application) logRequest(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		ip := r.Header.Get("X-Forwarded-For")
-		if ip == "" {
-			ip = r.RemoteAddr
-		}
-		app.logger.Printf("Request: IP=%s Method=%s URL=%s User-Agent=\"%s\"", ip, r.Method, r.URL.RequestURI(), r.UserAgent())
-		next.ServeHTTP(w, r)
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+
+		app.logger.InfoContext(r.Context(), "request",
+			"ip", clientIP(r),
+			"method", r.Method,
+			"path", r.URL.Path,
+			"url", redactURL(r.URL.RequestURI()),
+			"user_agent", r.UserAgent(),
+			"status", rec.status,
+			"duration_ms", time.Since(start).Milliseconds(),
+			"bytes", rec.bytes,
+		)
 	})
 }
 
+func clientIP(r *http.Request) string {
+	ip := r.Header.Get("X-Forwarded-For")
+	if ip == "" {
+		ip = r.RemoteAddr
+		// Sem a porta, para que conexões diferentes do mesmo cliente caiam no mesmo bucket
+		if host, _, err := net.SplitHostPort(ip); err == nil {
+			ip = host
+		}
+	}
+	return ip
+}
+
 func (app *application) handler(w http.ResponseWriter, r *http.Request) {
 	ctx, span := app.tracer.Start(r.Context(), "/weather-by-cep")
 	defer span.End()
 
 	// 1. Validação
-	req := Request{}
-	err := json.NewDecoder(r.Body).Decode(&req)
-	defer r.Body.Close()
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
-		return
+	// GET usa apenas a query; no POST a query, se presente, tem precedência sobre o corpo
+	req := Request{Cep: r.URL.Query().Get("cep")}
+	if r.Method == http.MethodPost {
+		defer r.Body.Close()
+		if !requireJSONBody(w, r) {
+			return
+		}
+
+		r.Body = http.MaxBytesReader(w, r.Body, app.maxBodyBytes)
+		body := Request{}
+		decoder := json.NewDecoder(r.Body)
+		decoder.DisallowUnknownFields()
+		err := decoder.Decode(&body)
+		switch {
+		case errors.Is(err, io.EOF):
+			// Corpo vazio só é aceito quando o CEP veio na query
+			if req.Cep == "" {
+				writeJSONError(w, http.StatusBadRequest, codeMissingCep, msgEmptyBody)
+				return
+			}
+		case err != nil:
+			writeBodyError(w, err)
+			return
+		}
+
+		if req.Cep == "" {
+			req.Cep = body.Cep
+		} else if body.Cep != "" {
+			app.logger.WarnContext(r.Context(), "cep sent in both query and body, using query", "cep", req.Cep, "body_cep", body.Cep)
+		}
+
+		// JSON válido, mas sem o campo: a mensagem aponta o corpo, não a query
+		if req.Cep == "" {
+			writeJSONError(w, http.StatusBadRequest, codeMissingCep, msgMissingCepField)
+			return
+		}
 	}
-	defer r.Body.Close()
 
 	if req.Cep == "" {
-		http.Error(w, "param 'cep' is required", http.StatusBadRequest)
+		writeJSONError(w, http.StatusBadRequest, codeMissingCep, msgMissingCepParam)
 		return
 	}
 
-	if !cepRegex.MatchString(req.Cep) {
-		http.Error(w, "invalid zipcode", http.StatusUnprocessableEntity)
+	zipcode, err := cep.NormalizeCEP(req.Cep)
+	if err != nil {
+		writeJSONError(w, http.StatusUnprocessableEntity, codeInvalidZipcode, msgInvalidZipcode)
 		return
 	}
 
 	// 2. Processamento
-	ctxWithTimeout, cancel := context.WithTimeout(ctx, 5*time.Second)
+	resp, apiErr := app.fetchWeather(ctx, zipcode)
+	if apiErr != nil {
+		if apiErr.retryAfter != "" {
+			w.Header().Set("Retry-After", apiErr.retryAfter)
+		}
+		writeJSONError(w, apiErr.status, apiErr.code, apiErr.msg)
+		return
+	}
+
+	// 3. Resultado
+	// O app2 em modo degradado devolve o endereço sem as temperaturas
+	// A resposta parcial não é cacheada, para que a próxima consulta já traga o clima
+	status := http.StatusOK
+	if resp.Partial {
+		status = http.StatusPartialContent
+		w.Header().Set("Cache-Control", "no-store")
+	} else {
+		setCacheControl(w, app.cacheMaxAge)
+	}
+	writeResponse(w, r, status, resp)
+}
+
+// Consulta o app2 para um CEP já normalizado; o erro já vem com status e código da resposta
+func (app *application) fetchWeather(ctx context.Context, zipcode string) (*Response, *apiError) {
+	// No baggage, o CEP chega ao app2 e vira atributo dos spans seguintes, inclusive este
+	ctx = withCepBaggage(ctx, zipcode)
+
+	// Span lógico da chamada ao app2; o span HTTP do transporte fica abaixo dele
+	ctx, span := app.tracer.Start(ctx, "app2.GetWeatherByCep")
+	span.SetAttributes(attribute.String("cep.value", zipcode))
+	defer span.End()
+
+	ctxWithTimeout, cancel := downstreamContext(ctx, app.downstreamTimeout)
 	defer cancel()
 
-	app2Endpoint := fmt.Sprintf("%s/get-weather-by-cep?cep=%s", os.Getenv("APP2_BASE_URL"), req.Cep)
-	reqApp2, err := http.NewRequestWithContext(ctxWithTimeout, "GET", app2Endpoint, nil)
-	if err != nil {
-		span.RecordError(err)
-		http.Error(w, "fail create request to orchestrator service", http.StatusInternalServerError)
-		return
+	var cached etagEntry
+	var hasCached bool
+	if app.etagCache != nil {
+		cached, hasCached = app.etagCache.get(zipcode)
 	}
 
-	response, err := app.httpClient.Do(reqApp2)
+	// Novas tentativas só em erro de conexão e 5xx, com backoff e dentro do prazo de ctxWithTimeout
+	start := time.Now()
+	var response *http.Response
+	var release func()
+	policy := app.app2Retry
+	policy.OnRetry = func(attempt int, delay time.Duration) {
+		if response != nil {
+			response.Body.Close()
+			response = nil
+		}
+		release()
+
+		span.AddEvent("retry", trace.WithAttributes(
+			attribute.Int("retry.attempt", attempt),
+			attribute.Int64("retry.delay_ms", delay.Milliseconds()),
+		))
+	}
+	err := backoff.Retry(ctxWithTimeout, policy, func(attempt int) (bool, error) {
+		var err error
+		response, release, err = app.callApp2(ctxWithTimeout, span, zipcode, cached.etag)
+		return !errors.Is(err, errInvalidApp2Request) && retryable(ctxWithTimeout, response, err), err
+	})
+	// O corpo da resposta final ainda será lido; repetir o release de uma tentativa anterior não tem efeito
+	defer release()
+	app.metrics.ObserveOutbound("app2", time.Since(start))
+	if errors.Is(err, errInvalidApp2Request) {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "invalid request")
+		return nil, &apiError{status: http.StatusInternalServerError, code: codeInternalError, msg: msgApp2Request}
+	}
 	if err != nil {
 		span.RecordError(err)
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
+		span.SetStatus(codes.Error, "orchestrator request failed")
+		// Timeout e falha de conexão são distinguidos para clientes e balanceadores
+		if httpx.IsTimeout(err) {
+			return nil, &apiError{status: http.StatusGatewayTimeout, code: codeTimeout, msg: msgApp2Timeout}
+		}
+		return nil, &apiError{status: http.StatusBadGateway, code: codeUpstreamError, msg: msgApp2Unreachable}
 	}
 	defer response.Body.Close()
 
-	if response.StatusCode == http.StatusNotFound {
-		http.Error(w, "can not find zipcode", http.StatusNotFound)
-		return
+	span.SetAttributes(attribute.Int("downstream.status_code", response.StatusCode))
+	if response.StatusCode == http.StatusNotModified && hasCached {
+		span.AddEvent("etag.not_modified")
+		app.etagCache.set(zipcode, cached.etag, cached.response, response.Header.Get("Cache-Control"))
+		resp := cached.response
+		return &resp, nil
 	}
-
 	if response.StatusCode >= http.StatusBadRequest {
-		http.Error(w, "error on find weather in orchestrator service", response.StatusCode)
-		return
+		apiErr := app2Error(response)
+		span.SetAttributes(attribute.String("downstream.error_code", apiErr.code))
+		span.SetStatus(codes.Error, fmt.Sprintf("orchestrator returned status %d", response.StatusCode))
+		return nil, apiErr
 	}
 
-	// 3. Resultado
 	resp := Response{}
-	err = json.NewDecoder(response.Body).Decode(&resp)
-	if err != nil {
+	if err := json.NewDecoder(response.Body).Decode(&resp); err != nil {
 		span.RecordError(err)
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
+		span.SetStatus(codes.Error, "invalid orchestrator response")
+		return nil, &apiError{status: http.StatusBadGateway, code: codeUpstreamError, msg: msgApp2InvalidResp}
+	}
+	span.SetAttributes(attribute.String("city.name", resp.City))
+	if app.etagCache != nil {
+		app.etagCache.set(zipcode, response.Header.Get("ETag"), resp, response.Header.Get("Cache-Control"))
+	}
+
+	return &resp, nil
+}
+
+var errInvalidApp2Request = errors.New("invalid request to orchestrator service")
+
+// Uma tentativa: uma requisição (e um contexto) por instância, na ordem do rodízio, disparadas pelo hedgedDo.
+// release cancela os contextos e só deve ser chamado depois de ler o corpo da resposta.
+// Com etag, a requisição é condicional e o app2 pode responder 304
+func (app *application) callApp2(ctx context.Context, span trace.Span, zipcode, etag string) (*http.Response, func(), error) {
+	var cancels []context.CancelFunc
+	release := func() {
+		for _, cancel := range cancels {
+			cancel()
+		}
+	}
+
+	// A ordem do rodízio define a instância principal e as do hedge
+	var baseURLs []string
+	var backends []int
+	if app.app2Backends != nil {
+		backends = app.app2Backends.order()
+		for _, idx := range backends {
+			baseURLs = append(baseURLs, app.app2Backends.urls[idx])
+		}
+	} else {
+		baseURLs = []string{os.Getenv("APP2_BASE_URL")}
+	}
+
+	reqs := make([]*http.Request, 0, len(baseURLs))
+	for _, baseURL := range baseURLs {
+		attemptCtx, cancel := context.WithCancel(ctx)
+		cancels = append(cancels, cancel)
+
+		app2Endpoint := fmt.Sprintf("%s/get-weather-by-cep?cep=%s", baseURL, zipcode)
+		reqApp2, err := http.NewRequestWithContext(attemptCtx, "GET", app2Endpoint, nil)
+		if err != nil {
+			return nil, release, fmt.Errorf("%w: %v", errInvalidApp2Request, err)
+		}
+		reqApp2.Header.Set("Accept", "application/json")
+		if etag != "" {
+			reqApp2.Header.Set("If-None-Match", etag)
+		}
+		setDeadlineHeader(reqApp2)
+		if id := requestIDFromContext(ctx); id != "" {
+			reqApp2.Header.Set(requestIDHeader, id)
+		}
+		reqs = append(reqs, reqApp2)
+	}
+
+	response, err := hedgedDo(app.httpClient, span, reqs, cancels, app.hedgeDelay, func(i int) {
+		if app.app2Backends != nil && app.app2Backends.markFailed(backends[i]) {
+			app.logger.WarnContext(ctx, "app2 instance unreachable, skipping it during cooldown", "instance", baseURLs[i])
+		}
+	})
+	return response, release, err
+}
+
+// Repassa o status e o código de erro do app2; sem corpo estruturado, usa um código padrão pelo status
+func app2Error(response *http.Response) *apiError {
+	e := &apiError{status: response.StatusCode, code: codeUpstreamError, msg: msgApp2WeatherFailed, retryAfter: response.Header.Get("Retry-After")}
+	switch response.StatusCode {
+	case http.StatusNotFound:
+		e.code, e.msg = codeCepNotFound, msgCepNotFound
+	case http.StatusUnprocessableEntity:
+		// O app2 (segundo o ViaCEP) rejeitou o formato do CEP
+		e.code, e.msg = codeInvalidZipcode, msgInvalidZipcode
+	}
+	if body, ok := decodeApp2Error(response.Body); ok {
+		e.code = body.Code
 	}
+	return e
+}
+
+// Dados do build, injetados via -ldflags
+func versionHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(version.Get())
+}
 
+// Liveness: não depende do app2
+func (app *application) healthHandler(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(resp)
+	json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
 }
 
 // Para fins didáticos, é necessário uma camanda extra para capturar os dados de cabeçalhos
 func (l *loggingRoundTripper) RoundTrip(r *http.Request) (*http.Response, error) {
-	l.logger.Println("------- Headers send -------")
-	for name, values := range r.Header {
-		for _, value := range values {
-			l.logger.Printf("Header: %s: %s", name, value)
-		}
-	}
-	l.logger.Println("-----------------------------")
+	l.logger.InfoContext(r.Context(), "headers sent", "url", redactURL(r.URL.String()), "headers", redactHeaders(r.Header))
 	return l.next.RoundTrip(r)
 }
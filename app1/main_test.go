@@ -0,0 +1,734 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"l02-01/version"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+func newTestApplication() *application {
+	return newApplication(slog.New(slog.DiscardHandler), noop.NewTracerProvider().Tracer("test"), http.DefaultClient)
+}
+
+// Requisição com corpo JSON e o Content-Type exigido pelos handlers POST
+func newJSONRequest(method, target, body string) *http.Request {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	return req
+}
+
+func TestHealthHandler(t *testing.T) {
+	app := newTestApplication()
+
+	rec := httptest.NewRecorder()
+	app.healthHandler(rec, httptest.NewRequest(http.MethodGet, "/health", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, but got %d", rec.Code)
+	}
+
+	if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("expected Content-Type 'application/json', but got '%s'", ct)
+	}
+
+	var body map[string]string
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatalf("expected a JSON body, but got: %v", err)
+	}
+
+	if body["status"] != "ok" {
+		t.Errorf("expected status 'ok', but got '%s'", body["status"])
+	}
+}
+
+func TestMetricsEndpoint(t *testing.T) {
+	app := newTestApplication()
+
+	app2 := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"city": "São Paulo", "temp_C": 25, "temp_F": 77, "temp_K": 298.15}`))
+	}))
+	defer app2.Close()
+	t.Setenv("APP2_BASE_URL", app2.URL)
+
+	handler := app.instrument("/weather-by-cep", http.HandlerFunc(app.handler))
+	handler.ServeHTTP(httptest.NewRecorder(), newJSONRequest(http.MethodPost, "/weather-by-cep", `{"cep": "01001-000"}`))
+
+	rec := httptest.NewRecorder()
+	app.metrics.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	body, _ := io.ReadAll(rec.Body)
+	for _, name := range []string{
+		`http_requests_total{route="/weather-by-cep",status="200"} 1`,
+		"http_request_duration_seconds_bucket",
+		`outbound_request_duration_seconds_count{upstream="app2"} 1`,
+	} {
+		if !strings.Contains(string(body), name) {
+			t.Errorf("expected metrics output to contain '%s'", name)
+		}
+	}
+}
+
+func TestHandler_RelaysAddressFields(t *testing.T) {
+	app := newTestApplication()
+
+	app2 := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"city": "São Paulo", "state": "SP", "street": "Praça da Sé", "cep": "01001-000", "temp_C": 25, "temp_F": 77, "temp_K": 298.15, "humidity": 62, "wind_kph": 11.2, "condition": "Partly cloudy", "region": "Sao Paulo", "country": "Brazil"}`))
+	}))
+	defer app2.Close()
+	t.Setenv("APP2_BASE_URL", app2.URL)
+
+	rec := httptest.NewRecorder()
+	app.handler(rec, newJSONRequest(http.MethodPost, "/weather-by-cep", `{"cep": "01001-000"}`))
+
+	var body Response
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatalf("expected a JSON body, but got: %v", err)
+	}
+
+	if body.State != "SP" || body.Street != "Praça da Sé" || body.Cep != "01001-000" {
+		t.Errorf("expected address fields to be relayed, but got %+v", body)
+	}
+	if body.Humidity == nil || *body.Humidity != 62 || body.WindKph == nil || *body.WindKph != 11.2 || body.Condition != "Partly cloudy" {
+		t.Errorf("expected weather details to be relayed, but got %+v", body)
+	}
+
+	if body.Region != "Sao Paulo" || body.Country != "Brazil" {
+		t.Errorf("expected location fields to be relayed, but got %+v", body)
+	}
+}
+
+// Simula o app2 devolvendo a cidade e registrando o CEP recebido
+func newApp2Stub(t *testing.T, gotCep *string) {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*gotCep = r.URL.Query().Get("cep")
+		w.Write([]byte(`{"city": "São Paulo", "temp_C": 25, "temp_F": 77, "temp_K": 298.15}`))
+	}))
+	t.Cleanup(server.Close)
+	t.Setenv("APP2_BASE_URL", server.URL)
+}
+
+func TestHandler_CepSources(t *testing.T) {
+	tests := []struct {
+		name    string
+		method  string
+		target  string
+		body    string
+		wantCep string
+		wantLog bool
+	}{
+		{name: "GET with query", method: http.MethodGet, target: "/weather-by-cep?cep=01001-000", wantCep: "01001-000"},
+		{name: "POST with body", method: http.MethodPost, target: "/weather-by-cep", body: `{"cep": "20040-000"}`, wantCep: "20040-000"},
+		{name: "POST with query only", method: http.MethodPost, target: "/weather-by-cep?cep=01001000", wantCep: "01001-000"},
+		{name: "POST with query and body", method: http.MethodPost, target: "/weather-by-cep?cep=01001-000", body: `{"cep": "20040-000"}`, wantCep: "01001-000", wantLog: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotCep string
+			newApp2Stub(t, &gotCep)
+
+			var logs strings.Builder
+			app := newTestApplication()
+			app.logger = newLogger(&logs, slog.LevelDebug)
+
+			rec := httptest.NewRecorder()
+			app.handler(rec, newJSONRequest(tt.method, tt.target, tt.body))
+
+			if rec.Code != http.StatusOK {
+				t.Fatalf("expected status 200, but got %d: %s", rec.Code, rec.Body.String())
+			}
+
+			if gotCep != tt.wantCep {
+				t.Errorf("expected app2 to receive cep '%s', but got '%s'", tt.wantCep, gotCep)
+			}
+
+			var body Response
+			if err := json.NewDecoder(rec.Body).Decode(&body); err != nil || body.City != "São Paulo" {
+				t.Errorf("expected the weather JSON response, but got %v (%v)", body, err)
+			}
+
+			if hasWarning := strings.Contains(logs.String(), `"level":"WARN"`); hasWarning != tt.wantLog {
+				t.Errorf("expected warning logged = %v, but got logs: %q", tt.wantLog, logs.String())
+			}
+		})
+	}
+}
+
+func TestHandler_GetWithoutCep(t *testing.T) {
+	app := newTestApplication()
+
+	rec := httptest.NewRecorder()
+	app.handler(rec, httptest.NewRequest(http.MethodGet, "/weather-by-cep", nil))
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400, but got %d", rec.Code)
+	}
+}
+
+func TestHandler_ForwardsRequestID(t *testing.T) {
+	var gotID string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotID = r.Header.Get(requestIDHeader)
+		w.Write([]byte(`{"city": "São Paulo"}`))
+	}))
+	defer server.Close()
+	t.Setenv("APP2_BASE_URL", server.URL)
+
+	app := newTestApplication()
+	handler := app.requestID(http.HandlerFunc(app.handler))
+
+	req := httptest.NewRequest(http.MethodGet, "/weather-by-cep?cep=01001-000", nil)
+	req.Header.Set(requestIDHeader, "abc-123")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	if gotID != "abc-123" {
+		t.Errorf("expected app2 to receive request ID 'abc-123', but got '%s'", gotID)
+	}
+}
+
+func TestHandler_ErrorMessages(t *testing.T) {
+	tests := []struct {
+		name     string
+		app2URL  string
+		target   string
+		status   int
+		wantCode string
+		wantMsg  string
+	}{
+		{"missing cep", "http://localhost:8081", "/weather-by-cep", http.StatusBadRequest, codeMissingCep, "param 'cep' is required"},
+		{"invalid zipcode", "http://localhost:8081", "/weather-by-cep?cep=123", http.StatusUnprocessableEntity, codeInvalidZipcode, "invalid zipcode"},
+		{"invalid app2 url", "http://[::1]:namedport", "/weather-by-cep?cep=01001-000", http.StatusInternalServerError, codeInternalError, "failed to create request to orchestrator service"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("APP2_BASE_URL", tt.app2URL)
+
+			rec := httptest.NewRecorder()
+			newTestApplication().handler(rec, httptest.NewRequest(http.MethodGet, tt.target, nil))
+
+			if rec.Code != tt.status {
+				t.Fatalf("expected status %d, but got %d", tt.status, rec.Code)
+			}
+
+			var body errorResponse
+			if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+				t.Fatalf("expected a JSON body, but got: %v", err)
+			}
+			if body.Code != tt.wantCode || body.Error != tt.wantMsg {
+				t.Errorf("expected (%s, %s), but got (%s, %s)", tt.wantCode, tt.wantMsg, body.Code, body.Error)
+			}
+		})
+	}
+}
+
+func TestHandler_RelaysApp2Errors(t *testing.T) {
+	tests := []struct {
+		name      string
+		app2      int
+		app2Body  string
+		wantCode  string
+		wantError string
+	}{
+		{"structured not found", http.StatusNotFound, `{"error":"CEP não encontrado","code":"cep_not_found"}`, codeCepNotFound, "can not find zipcode"},
+		{"plain text not found", http.StatusNotFound, "CEP não encontrado\n", codeCepNotFound, "can not find zipcode"},
+		{"structured invalid zipcode", http.StatusUnprocessableEntity, `{"error":"CEP inválido","code":"invalid_zipcode"}`, codeInvalidZipcode, "invalid zipcode"},
+		{"structured upstream error", http.StatusInternalServerError, `{"error":"falha","code":"upstream_error"}`, codeUpstreamError, "failed to find weather in orchestrator service"},
+		{"unstructured server error", http.StatusBadGateway, "bad gateway", codeUpstreamError, "failed to find weather in orchestrator service"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.app2)
+				w.Write([]byte(tt.app2Body))
+			}))
+			defer server.Close()
+			t.Setenv("APP2_BASE_URL", server.URL)
+
+			rec := httptest.NewRecorder()
+			newTestApplication().handler(rec, httptest.NewRequest(http.MethodGet, "/weather-by-cep?cep=01001-000", nil))
+
+			if rec.Code != tt.app2 {
+				t.Fatalf("expected status %d, but got %d", tt.app2, rec.Code)
+			}
+
+			if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+				t.Errorf("expected Content-Type 'application/json', but got '%s'", ct)
+			}
+
+			var body errorResponse
+			if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+				t.Fatalf("expected a JSON body, but got: %v", err)
+			}
+
+			if body.Code != tt.wantCode || body.Error != tt.wantError {
+				t.Errorf("expected (%s, %s), but got (%s, %s)", tt.wantCode, tt.wantError, body.Code, body.Error)
+			}
+		})
+	}
+}
+
+func TestHandler_ValidationErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		target string
+		body   string
+		status int
+		code   string
+	}{
+		{"missing cep", http.MethodGet, "/weather-by-cep", "", http.StatusBadRequest, codeMissingCep},
+		{"invalid zipcode", http.MethodGet, "/weather-by-cep?cep=123", "", http.StatusUnprocessableEntity, codeInvalidZipcode},
+		{"malformed body", http.MethodPost, "/weather-by-cep", "{", http.StatusBadRequest, codeInvalidJSON},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			newTestApplication().handler(rec, newJSONRequest(tt.method, tt.target, tt.body))
+
+			if rec.Code != tt.status {
+				t.Fatalf("expected status %d, but got %d", tt.status, rec.Code)
+			}
+
+			var body errorResponse
+			if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+				t.Fatalf("expected a JSON body, but got: %v", err)
+			}
+
+			if body.Code != tt.code {
+				t.Errorf("expected code '%s', but got '%s'", tt.code, body.Code)
+			}
+		})
+	}
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func TestHandler_RelaysPartialResponse(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusPartialContent)
+		w.Write([]byte(`{"city": "São Paulo", "state": "SP", "cep": "01001-000", "partial": true}`))
+	}))
+	defer server.Close()
+	t.Setenv("APP2_BASE_URL", server.URL)
+
+	rec := httptest.NewRecorder()
+	newTestApplication().handler(rec, httptest.NewRequest(http.MethodGet, "/weather-by-cep?cep=01001-000", nil))
+
+	if rec.Code != http.StatusPartialContent {
+		t.Fatalf("expected status 206, but got %d", rec.Code)
+	}
+
+	if body := rec.Body.String(); !strings.Contains(body, `"partial":true`) || strings.Contains(body, "temp_C") {
+		t.Errorf("expected partial body without temperatures, but got %s", body)
+	}
+}
+
+func TestRoutes_WeatherByCep(t *testing.T) {
+	tests := []struct {
+		name   string
+		target string
+		app2   int
+		status int
+		code   string
+	}{
+		{"success", "/weather-by-cep?cep=01001-000", http.StatusOK, http.StatusOK, ""},
+		{"invalid cep", "/weather-by-cep?cep=abc", http.StatusOK, http.StatusUnprocessableEntity, codeInvalidZipcode},
+		{"not found", "/weather-by-cep?cep=99999-999", http.StatusNotFound, http.StatusNotFound, codeCepNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app2 := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if tt.app2 != http.StatusOK {
+					writeJSONError(w, tt.app2, codeCepNotFound, "CEP não encontrado")
+					return
+				}
+				w.Write([]byte(`{"city": "São Paulo", "temp_C": 25, "temp_F": 77, "temp_K": 298.15}`))
+			}))
+			defer app2.Close()
+			t.Setenv("APP2_BASE_URL", app2.URL)
+
+			server := httptest.NewServer(newTestApplication().routes())
+			defer server.Close()
+
+			resp, err := http.Get(server.URL + tt.target)
+			if err != nil {
+				t.Fatalf("expected no error, but got: %v", err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != tt.status {
+				t.Fatalf("expected status %d, but got %d", tt.status, resp.StatusCode)
+			}
+
+			if resp.Header.Get(requestIDHeader) == "" {
+				t.Error("expected the request ID middleware to be wired")
+			}
+
+			if tt.code == "" {
+				var body Response
+				if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+					t.Fatalf("expected a JSON body, but got: %v", err)
+				}
+				if body.City != "São Paulo" || body.TempC == nil || *body.TempC != 25 {
+					t.Errorf("unexpected body: %+v", body)
+				}
+				return
+			}
+
+			var body errorResponse
+			if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+				t.Fatalf("expected a JSON body, but got: %v", err)
+			}
+			if body.Code != tt.code {
+				t.Errorf("expected code '%s', but got '%s'", tt.code, body.Code)
+			}
+		})
+	}
+}
+
+func TestHandler_UpstreamTransportErrors(t *testing.T) {
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+	}))
+	defer slow.Close()
+
+	closed := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	closed.Close()
+
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name   string
+		app2   string
+		ctx    context.Context
+		status int
+		code   string
+	}{
+		{"slow app2", slow.URL, context.Background(), http.StatusGatewayTimeout, codeTimeout},
+		{"connection refused", closed.URL, context.Background(), http.StatusBadGateway, codeUpstreamError},
+		{"canceled context", slow.URL, canceled, http.StatusBadGateway, codeUpstreamError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("APP2_BASE_URL", tt.app2)
+			app := newTestApplication()
+			app.downstreamTimeout = 50 * time.Millisecond
+
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/weather-by-cep?cep=01001-000", nil).WithContext(tt.ctx)
+			app.handler(rec, req)
+
+			if rec.Code != tt.status {
+				t.Fatalf("expected status %d, but got %d", tt.status, rec.Code)
+			}
+
+			var body errorResponse
+			if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+				t.Fatalf("expected a JSON body, but got: %v", err)
+			}
+
+			if body.Code != tt.code {
+				t.Errorf("expected code '%s', but got '%s'", tt.code, body.Code)
+			}
+		})
+	}
+}
+
+func TestVersionEndpoint(t *testing.T) {
+	original := version.Get()
+	t.Cleanup(func() {
+		version.Version, version.Commit, version.BuildTime = original.Version, original.Commit, original.BuildTime
+	})
+	version.Version, version.Commit, version.BuildTime = "1.2.3", "abc123", "2026-10-14T12:00:00Z"
+
+	rec := httptest.NewRecorder()
+	newTestApplication().routes().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/version", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, but got %d", rec.Code)
+	}
+
+	expected := `{"version":"1.2.3","commit":"abc123","buildTime":"2026-10-14T12:00:00Z"}`
+	if body := strings.TrimSpace(rec.Body.String()); body != expected {
+		t.Errorf("expected body %s, but got %s", expected, body)
+	}
+}
+
+func TestHandler_App2Span(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		body     string
+		expected map[attribute.Key]attribute.Value
+		code     codes.Code
+	}{
+		{
+			name:   "success",
+			status: http.StatusOK,
+			body:   `{"city": "São Paulo", "temp_C": 25, "temp_F": 77, "temp_K": 298.15}`,
+			expected: map[attribute.Key]attribute.Value{
+				"cep.value":              attribute.StringValue("01001-000"),
+				"downstream.status_code": attribute.IntValue(http.StatusOK),
+				"city.name":              attribute.StringValue("São Paulo"),
+			},
+			code: codes.Unset,
+		},
+		{
+			name:   "app2 error",
+			status: http.StatusNotFound,
+			body:   `{"code": "cep_not_found", "message": "can not find zipcode"}`,
+			expected: map[attribute.Key]attribute.Value{
+				"cep.value":              attribute.StringValue("01001-000"),
+				"downstream.status_code": attribute.IntValue(http.StatusNotFound),
+				"downstream.error_code":  attribute.StringValue("cep_not_found"),
+			},
+			code: codes.Error,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app2 := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer app2.Close()
+			t.Setenv("APP2_BASE_URL", app2.URL)
+
+			recorder := tracetest.NewSpanRecorder()
+			app := newTestApplication()
+			app.tracer = sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)).Tracer("test")
+
+			app.handler(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/weather-by-cep?cep=01001000", nil))
+
+			spans := recorder.Ended()
+			if len(spans) != 2 {
+				t.Fatalf("expected 2 spans, but got %d", len(spans))
+			}
+
+			// O span do app2 termina primeiro e é filho do span do handler
+			child, parent := spans[0], spans[1]
+			if child.Name() != "app2.GetWeatherByCep" {
+				t.Fatalf("expected span 'app2.GetWeatherByCep', but got '%s'", child.Name())
+			}
+			if child.Parent().SpanID() != parent.SpanContext().SpanID() {
+				t.Errorf("expected app2 span to be a child of '%s'", parent.Name())
+			}
+
+			attrs := attribute.NewSet(child.Attributes()...)
+			for key, want := range tt.expected {
+				if got, ok := attrs.Value(key); !ok || got != want {
+					t.Errorf("expected %s=%s, but got %s (present: %v)", key, want.Emit(), got.Emit(), ok)
+				}
+			}
+
+			if got := child.Status().Code; got != tt.code {
+				t.Errorf("expected span status %s, but got %s", tt.code, got)
+			}
+		})
+	}
+}
+
+func TestHandler_ContentType(t *testing.T) {
+	app2 := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"city": "São Paulo", "temp_C": 25, "temp_F": 77, "temp_K": 298.15}`))
+	}))
+	defer app2.Close()
+	t.Setenv("APP2_BASE_URL", app2.URL)
+
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		status      int
+		code        string
+	}{
+		{"json", "application/json", `{"cep": "01001-000"}`, http.StatusOK, ""},
+		{"json with charset", "application/json; charset=utf-8", `{"cep": "01001-000"}`, http.StatusOK, ""},
+		{"missing header", "", `{"cep": "01001-000"}`, http.StatusUnsupportedMediaType, codeUnsupportedMediaType},
+		{"empty body without header", "", "", http.StatusBadRequest, codeMissingCep},
+		{"form", "application/x-www-form-urlencoded", "cep=01001-000", http.StatusUnsupportedMediaType, codeUnsupportedMediaType},
+		{"text", "text/plain", `{"cep": "01001-000"}`, http.StatusUnsupportedMediaType, codeUnsupportedMediaType},
+		{"empty body with wrong type", "text/plain", "", http.StatusBadRequest, codeMissingCep},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/weather-by-cep", strings.NewReader(tt.body))
+			if tt.contentType != "" {
+				req.Header.Set("Content-Type", tt.contentType)
+			}
+			rec := httptest.NewRecorder()
+			newTestApplication().handler(rec, req)
+
+			if rec.Code != tt.status {
+				t.Fatalf("expected status %d, but got %d", tt.status, rec.Code)
+			}
+			if tt.code == "" {
+				return
+			}
+
+			var body errorResponse
+			if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+				t.Fatalf("expected a JSON body, but got: %v", err)
+			}
+			if body.Code != tt.code {
+				t.Errorf("expected code '%s', but got '%s'", tt.code, body.Code)
+			}
+		})
+	}
+}
+
+func TestHandler_BodyTooLarge(t *testing.T) {
+	app := newTestApplication()
+	app.maxBodyBytes = 32
+	oversized := `{"cep": "01001-000", "padding": "` + strings.Repeat("x", 64) + `"}`
+
+	tests := []struct {
+		name    string
+		target  string
+		handler http.HandlerFunc
+	}{
+		{"single", "/weather-by-cep", app.handler},
+		{"batch", "/weather-by-cep/batch", app.batchHandler},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			tt.handler(rec, newJSONRequest(http.MethodPost, tt.target, oversized))
+
+			if rec.Code != http.StatusRequestEntityTooLarge {
+				t.Fatalf("expected status 413, but got %d", rec.Code)
+			}
+
+			var body errorResponse
+			if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+				t.Fatalf("expected a JSON body, but got: %v", err)
+			}
+			if body.Code != codeBodyTooLarge {
+				t.Errorf("expected code '%s', but got '%s'", codeBodyTooLarge, body.Code)
+			}
+		})
+	}
+}
+
+func TestHandler_PostBody(t *testing.T) {
+	app2 := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"city": "São Paulo", "temp_C": 25, "temp_F": 77, "temp_K": 298.15}`))
+	}))
+	defer app2.Close()
+	t.Setenv("APP2_BASE_URL", app2.URL)
+
+	tests := []struct {
+		name   string
+		target string
+		body   string
+		status int
+		code   string
+		msg    string
+	}{
+		{"empty body", "/weather-by-cep", "", http.StatusBadRequest, codeMissingCep, "empty request body"},
+		{"empty body with query cep", "/weather-by-cep?cep=01001-000", "", http.StatusOK, "", ""},
+		{"malformed json", "/weather-by-cep", `{"cep": `, http.StatusBadRequest, codeInvalidJSON, "invalid JSON body: unexpected EOF"},
+		{"wrong type", "/weather-by-cep", `{"cep": 1001000}`, http.StatusBadRequest, codeInvalidJSON, "invalid JSON body: json: cannot unmarshal number into Go struct field Request.cep of type string"},
+		{"unknown field", "/weather-by-cep", `{"cep": "01001-000", "zip": "x"}`, http.StatusBadRequest, codeInvalidJSON, `invalid JSON body: json: unknown field "zip"`},
+		{"body without cep", "/weather-by-cep", `{}`, http.StatusBadRequest, codeMissingCep, "field 'cep' is required"},
+		{"body with empty cep", "/weather-by-cep", `{"cep": ""}`, http.StatusBadRequest, codeMissingCep, "field 'cep' is required"},
+		{"valid body", "/weather-by-cep", `{"cep": "01001-000"}`, http.StatusOK, "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			newTestApplication().handler(rec, newJSONRequest(http.MethodPost, tt.target, tt.body))
+
+			if rec.Code != tt.status {
+				t.Fatalf("expected status %d, but got %d", tt.status, rec.Code)
+			}
+			if tt.code == "" {
+				return
+			}
+
+			var body errorResponse
+			if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+				t.Fatalf("expected a JSON body, but got: %v", err)
+			}
+			if body.Code != tt.code || body.Error != tt.msg {
+				t.Errorf("expected (%s, %s), but got (%s, %s)", tt.code, tt.msg, body.Code, body.Error)
+			}
+		})
+	}
+}
+
+func TestHandler_CacheControl(t *testing.T) {
+	app := newTestApplication()
+	app.cacheMaxAge = 2 * time.Minute
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("cep") {
+		case "01001-000":
+			w.Write([]byte(`{"city": "São Paulo", "temp_C": 25, "temp_F": 77, "temp_K": 298.15}`))
+		case "02002-000":
+			w.WriteHeader(http.StatusPartialContent)
+			w.Write([]byte(`{"city": "São Paulo", "partial": true}`))
+		default:
+			writeJSONError(w, http.StatusNotFound, codeCepNotFound, "can not find zipcode")
+		}
+	}))
+	defer server.Close()
+	t.Setenv("APP2_BASE_URL", server.URL)
+
+	tests := []struct {
+		name     string
+		cep      string
+		expected string
+	}{
+		{"success", "01001-000", "public, max-age=120"},
+		{"partial", "02002-000", "no-store"},
+		{"app2 error", "03003-000", "no-store"},
+		{"validation error", "123", "no-store"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			app.handler(rec, httptest.NewRequest(http.MethodGet, "/weather-by-cep?cep="+tt.cep, nil))
+
+			if got := rec.Header().Get("Cache-Control"); got != tt.expected {
+				t.Errorf("expected Cache-Control '%s', but got '%s'", tt.expected, got)
+			}
+
+			if tt.expected != "no-store" && !slices.Contains(rec.Header().Values("Vary"), "Accept") {
+				t.Errorf("expected Vary to contain 'Accept', but got %v", rec.Header().Values("Vary"))
+			}
+		})
+	}
+}
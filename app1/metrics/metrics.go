@@ -0,0 +1,109 @@
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/noop"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+)
+
+// Registro próprio (em vez do global) para que cada instância da aplicação tenha suas métricas
+type Metrics struct {
+	registry         *prometheus.Registry
+	requestsTotal    *prometheus.CounterVec
+	requestDuration  *prometheus.HistogramVec
+	outboundDuration *prometheus.HistogramVec
+	// Espelho das métricas de requisição no OpenTelemetry (noop sem WithMeter)
+	otelRequests metric.Int64Counter
+	otelDuration metric.Float64Histogram
+}
+
+type Option func(*Metrics)
+
+// Registra também a contagem e a duração das requisições no Meter informado
+func WithMeter(meter metric.Meter) Option {
+	return func(m *Metrics) {
+		m.useMeter(meter)
+	}
+}
+
+func New(opts ...Option) *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		requestsTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "http_requests_total",
+			Help: "Total de requisições HTTP recebidas.",
+		}, []string{"route", "status"}),
+		requestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "http_request_duration_seconds",
+			Help:    "Duração das requisições HTTP recebidas.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"route", "status"}),
+		outboundDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "outbound_request_duration_seconds",
+			Help:    "Duração das chamadas aos serviços externos.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"upstream"}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.requestsTotal,
+		m.requestDuration,
+		m.outboundDuration,
+	)
+
+	m.useMeter(noop.NewMeterProvider().Meter(""))
+	for _, opt := range opts {
+		opt(m)
+	}
+
+	return m
+}
+
+// Nomes próprios para não se confundir com as métricas http.server.* do otelhttp
+func (m *Metrics) useMeter(meter metric.Meter) {
+	var err error
+	m.otelRequests, err = meter.Int64Counter("app.request.count",
+		metric.WithDescription("Total de requisições HTTP recebidas."),
+		metric.WithUnit("{request}"),
+	)
+	if err != nil {
+		otel.Handle(err)
+	}
+
+	m.otelDuration, err = meter.Float64Histogram("app.request.duration",
+		metric.WithDescription("Duração das requisições HTTP recebidas."),
+		metric.WithUnit("s"),
+	)
+	if err != nil {
+		otel.Handle(err)
+	}
+}
+
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+func (m *Metrics) ObserveRequest(route string, status int, duration time.Duration) {
+	labels := prometheus.Labels{"route": route, "status": strconv.Itoa(status)}
+	m.requestsTotal.With(labels).Inc()
+	m.requestDuration.With(labels).Observe(duration.Seconds())
+
+	attrs := metric.WithAttributes(semconv.HTTPRoute(route), semconv.HTTPResponseStatusCode(status))
+	m.otelRequests.Add(context.Background(), 1, attrs)
+	m.otelDuration.Record(context.Background(), duration.Seconds(), attrs)
+}
+
+func (m *Metrics) ObserveOutbound(upstream string, duration time.Duration) {
+	m.outboundDuration.WithLabelValues(upstream).Observe(duration.Seconds())
+}
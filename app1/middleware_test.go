@@ -0,0 +1,227 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"l02-01/metrics"
+
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+func TestRequestID(t *testing.T) {
+	app := newTestApplication()
+
+	var seen string
+	handler := app.requestID(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = requestIDFromContext(r.Context())
+	}))
+
+	t.Run("generated when missing", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+		if seen == "" {
+			t.Fatal("expected a generated request ID in the context")
+		}
+		if got := rec.Header().Get(requestIDHeader); got != seen {
+			t.Errorf("expected response header '%s', but got '%s'", seen, got)
+		}
+	})
+
+	t.Run("preserved when provided", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set(requestIDHeader, "abc-123")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		if seen != "abc-123" {
+			t.Errorf("expected request ID 'abc-123' in the context, but got '%s'", seen)
+		}
+		if got := rec.Header().Get(requestIDHeader); got != "abc-123" {
+			t.Errorf("expected response header 'abc-123', but got '%s'", got)
+		}
+	})
+
+	t.Run("replaced when invalid", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set(requestIDHeader, "bad id\nwith newline")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		if seen == "bad id\nwith newline" || seen == "" {
+			t.Errorf("expected a new request ID, but got '%s'", seen)
+		}
+	})
+}
+
+func TestAllowMethods(t *testing.T) {
+	handler := allowMethods(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}), http.MethodGet, http.MethodPost)
+
+	for _, method := range []string{http.MethodGet, http.MethodPost} {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(method, "/", nil))
+
+		if rec.Code != http.StatusOK {
+			t.Errorf("%s: expected status 200, but got %d", method, rec.Code)
+		}
+	}
+
+	for _, method := range []string{http.MethodDelete, http.MethodPut, http.MethodPatch} {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(method, "/", nil))
+
+		if rec.Code != http.StatusMethodNotAllowed {
+			t.Errorf("%s: expected status 405, but got %d", method, rec.Code)
+		}
+		if got := rec.Header().Get("Allow"); got != "GET, POST" {
+			t.Errorf("%s: expected Allow 'GET, POST', but got '%s'", method, got)
+		}
+	}
+}
+
+func TestInstrument_RecordsOTelMetrics(t *testing.T) {
+	app := newTestApplication()
+	reader := sdkmetric.NewManualReader()
+	app.metrics = metrics.New(metrics.WithMeter(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)).Meter("test")))
+
+	handler := app.instrument("/test", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/test", nil))
+
+	var data metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &data); err != nil {
+		t.Fatalf("expected no error, but got: %v", err)
+	}
+
+	found := map[string]bool{}
+	for _, scope := range data.ScopeMetrics {
+		for _, m := range scope.Metrics {
+			found[m.Name] = true
+			if sum, ok := m.Data.(metricdata.Sum[int64]); ok {
+				point := sum.DataPoints[0]
+				if route, _ := point.Attributes.Value("http.route"); route.AsString() != "/test" {
+					t.Errorf("expected route '/test', but got '%s'", route.AsString())
+				}
+				if status, _ := point.Attributes.Value("http.response.status_code"); status.AsInt64() != http.StatusNotFound {
+					t.Errorf("expected status %d, but got %d", http.StatusNotFound, status.AsInt64())
+				}
+				if point.Value != 1 {
+					t.Errorf("expected count 1, but got %d", point.Value)
+				}
+			}
+		}
+	}
+
+	for _, name := range []string{"app.request.count", "app.request.duration"} {
+		if !found[name] {
+			t.Errorf("expected metric %s to be recorded", name)
+		}
+	}
+}
+
+func TestTraceID(t *testing.T) {
+	handler := traceID(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	t.Run("set when a sampled span is active", func(t *testing.T) {
+		tracer := sdktrace.NewTracerProvider(sdktrace.WithSampler(sdktrace.AlwaysSample())).Tracer("test")
+		ctx, span := tracer.Start(context.Background(), "request")
+		defer span.End()
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil).WithContext(ctx))
+
+		if got, want := rec.Header().Get(traceIDHeader), span.SpanContext().TraceID().String(); got != want {
+			t.Errorf("expected header '%s', but got '%s'", want, got)
+		}
+	})
+
+	t.Run("omitted when the span is not sampled", func(t *testing.T) {
+		tracer := sdktrace.NewTracerProvider(sdktrace.WithSampler(sdktrace.NeverSample())).Tracer("test")
+		ctx, span := tracer.Start(context.Background(), "request")
+		defer span.End()
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil).WithContext(ctx))
+
+		if got := rec.Header().Get(traceIDHeader); got != "" {
+			t.Errorf("expected no header, but got '%s'", got)
+		}
+	})
+
+	t.Run("omitted without span", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+		if got := rec.Header().Get(traceIDHeader); got != "" {
+			t.Errorf("expected no header, but got '%s'", got)
+		}
+	})
+}
+
+func TestChain_Order(t *testing.T) {
+	var calls []string
+	mark := func(name string) func(http.Handler) http.Handler {
+		return func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls = append(calls, name+" before")
+				next.ServeHTTP(w, r)
+				calls = append(calls, name+" after")
+			})
+		}
+	}
+
+	handler := chain(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls = append(calls, "handler")
+	}), mark("first"), mark("second"))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	expected := []string{"first before", "second before", "handler", "second after", "first after"}
+	if !slices.Equal(calls, expected) {
+		t.Errorf("expected %v, but got %v", expected, calls)
+	}
+}
+
+func TestLogRequest_RecordsStatus(t *testing.T) {
+	var buf bytes.Buffer
+	app := newTestApplication()
+	app.logger = newLogger(&buf, slog.LevelInfo)
+
+	handler := app.logRequest(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "not found", http.StatusNotFound)
+	}))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/weather-by-cep?cep=99999999", nil))
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	var entry map[string]any
+	if err := json.Unmarshal([]byte(lines[len(lines)-1]), &entry); err != nil {
+		t.Fatalf("expected a JSON log line, but got %q: %v", buf.String(), err)
+	}
+
+	if entry["msg"] != "request" || entry["status"] != float64(http.StatusNotFound) {
+		t.Errorf("expected a request log with status 404, but got %v", entry)
+	}
+	if entry["method"] != http.MethodGet || entry["path"] != "/weather-by-cep" {
+		t.Errorf("expected method and path, but got %v", entry)
+	}
+	if entry["bytes"] != float64(len("not found\n")) {
+		t.Errorf("expected bytes %d, but got %v", len("not found\n"), entry["bytes"])
+	}
+	if _, ok := entry["duration_ms"]; !ok {
+		t.Error("expected duration_ms in the log line")
+	}
+}
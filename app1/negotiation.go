@@ -0,0 +1,99 @@
+package main
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Corpo das requisições POST: aceita application/json com parâmetros (ex.: charset).
+// Sem Content-Type não é JSON, e o corpo não chega a ser lido
+func isJSONContentType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	return err == nil && mediaType == "application/json"
+}
+
+// Corpo vazio não é validado: vira o erro de CEP ausente em vez de 415
+func requireJSONBody(w http.ResponseWriter, r *http.Request) bool {
+	if r.ContentLength == 0 || isJSONContentType(r.Header.Get("Content-Type")) {
+		return true
+	}
+	writeJSONError(w, http.StatusUnsupportedMediaType, codeUnsupportedMediaType, msgUnsupportedMedia)
+	return false
+}
+
+// Corpo acima do limite do http.MaxBytesReader vira 413; os demais erros de leitura, JSON inválido
+func writeBodyError(w http.ResponseWriter, err error) {
+	var maxErr *http.MaxBytesError
+	if errors.As(err, &maxErr) {
+		writeJSONError(w, http.StatusRequestEntityTooLarge, codeBodyTooLarge, fmt.Sprintf(msgBodyTooLarge, maxErr.Limit))
+		return
+	}
+	// Cobre sintaxe inválida, tipos errados e campos desconhecidos (DisallowUnknownFields)
+	writeJSONError(w, http.StatusBadRequest, codeInvalidJSON, msgInvalidJSON+err.Error())
+}
+
+// Escolhe XML apenas quando o cliente pede application/xml explicitamente; */* e afins continuam em JSON
+func prefersXML(accept string) bool {
+	xmlQ, jsonQ := -1.0, -1.0
+	xmlIdx, jsonIdx := 0, 0
+	for i, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+
+		q := 1.0
+		if v, ok := params["q"]; ok {
+			if parsed, err := strconv.ParseFloat(v, 64); err == nil {
+				q = parsed
+			}
+		}
+
+		switch mediaType {
+		case "application/xml":
+			if q > xmlQ {
+				xmlQ, xmlIdx = q, i
+			}
+		case "application/json":
+			if q > jsonQ {
+				jsonQ, jsonIdx = q, i
+			}
+		}
+	}
+
+	if xmlQ <= 0 {
+		return false
+	}
+	if jsonQ < 0 {
+		return true
+	}
+	return xmlQ > jsonQ || (xmlQ == jsonQ && xmlIdx < jsonIdx)
+}
+
+// Respostas de clima de sucesso podem ser reaproveitadas por clientes e CDNs durante maxAge.
+// O corpo é JSON ou XML conforme o Accept, que por isso entra no Vary
+func setCacheControl(w http.ResponseWriter, maxAge time.Duration) {
+	w.Header().Set("Cache-Control", "public, max-age="+strconv.Itoa(int(maxAge.Seconds())))
+	w.Header().Add("Vary", "Accept")
+}
+
+func writeResponse(w http.ResponseWriter, r *http.Request, status int, v any) {
+	if prefersXML(r.Header.Get("Accept")) {
+		w.Header().Set("Content-Type", "application/xml")
+		w.WriteHeader(status)
+		w.Write([]byte(xml.Header))
+		xml.NewEncoder(w).Encode(v)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
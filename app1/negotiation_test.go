@@ -0,0 +1,82 @@
+package main
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestPrefersXML(t *testing.T) {
+	tests := []struct {
+		accept string
+		want   bool
+	}{
+		{accept: "", want: false},
+		{accept: "*/*", want: false},
+		{accept: "application/json", want: false},
+		{accept: "text/html", want: false},
+		{accept: "application/xml", want: true},
+		{accept: "application/xml, */*", want: true},
+		{accept: "application/json, application/xml", want: false},
+		{accept: "application/xml, application/json", want: true},
+		{accept: "application/json;q=0.5, application/xml", want: true},
+		{accept: "application/xml;q=0", want: false},
+	}
+
+	for _, tt := range tests {
+		if got := prefersXML(tt.accept); got != tt.want {
+			t.Errorf("accept %q: expected %v, but got %v", tt.accept, tt.want, got)
+		}
+	}
+}
+
+func TestWriteResponse_Encodings(t *testing.T) {
+	payload := Response{City: "São Paulo", TempC: ptr(25.5), TempF: ptr(77.9), TempK: ptr(298.65)}
+
+	t.Run("json", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		writeResponse(rec, httptest.NewRequest(http.MethodGet, "/", nil), http.StatusOK, payload)
+
+		if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+			t.Errorf("expected Content-Type 'application/json', but got '%s'", ct)
+		}
+
+		var got Response
+		if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+			t.Fatalf("expected a JSON body, but got: %v", err)
+		}
+		if got.City != payload.City || *got.TempC != *payload.TempC || *got.TempF != *payload.TempF || *got.TempK != *payload.TempK {
+			t.Errorf("expected %+v, but got %+v", payload, got)
+		}
+	})
+
+	t.Run("xml", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Accept", "application/xml")
+
+		rec := httptest.NewRecorder()
+		writeResponse(rec, req, http.StatusOK, payload)
+
+		if ct := rec.Header().Get("Content-Type"); ct != "application/xml" {
+			t.Errorf("expected Content-Type 'application/xml', but got '%s'", ct)
+		}
+
+		body := rec.Body.String()
+		for _, fragment := range []string{"<weather>", "<city>São Paulo</city>", "<temp_C>25.5</temp_C>", "<temp_F>77.9</temp_F>", "<temp_K>298.65</temp_K>"} {
+			if !strings.Contains(body, fragment) {
+				t.Errorf("expected XML body to contain '%s', but got %s", fragment, body)
+			}
+		}
+
+		var got Response
+		if err := xml.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+			t.Fatalf("expected a valid XML body, but got: %v", err)
+		}
+		if got.TempK == nil || *got.TempK != *payload.TempK {
+			t.Errorf("expected TempK %v, but got %v", *payload.TempK, got.TempK)
+		}
+	})
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/pprof"
+)
+
+// Só na interface local por padrão: os perfis expõem detalhes internos do processo
+const defaultPprofAddr = "localhost:6060"
+
+// Mux próprio, para os perfis não aparecerem na porta do serviço
+func pprofHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	return mux
+}
+
+// Listener administrativo com ENABLE_PPROF=true. Sem WriteTimeout, que cortaria o /profile (30s por padrão);
+// uma falha aqui só gera log, sem derrubar o serviço
+func startPprofServer(logger *slog.Logger, addr string) {
+	server := &http.Server{
+		Addr:              addr,
+		Handler:           pprofHandler(),
+		ReadHeaderTimeout: defaultServerReadHeaderTimeout,
+	}
+
+	go func() {
+		logger.Info("pprof server listening", "addr", addr)
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Error("can not start pprof server", "addr", addr, "error", err)
+		}
+	}()
+}
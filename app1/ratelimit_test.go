@@ -0,0 +1,69 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRateLimit_BlocksOverBurst(t *testing.T) {
+	app := newTestApplication()
+	app.limiter = newRateLimiter(1, 2)
+	now := time.Now()
+	app.limiter.now = func() time.Time { return now }
+
+	handler := app.rateLimit(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	send := func(ip string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/weather-by-cep", nil)
+		req.Header.Set("X-Forwarded-For", ip)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	for i := 0; i < 2; i++ {
+		if rec := send("10.0.0.1"); rec.Code != http.StatusOK {
+			t.Fatalf("request %d: expected status 200, but got %d", i, rec.Code)
+		}
+	}
+
+	rec := send("10.0.0.1")
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected status 429, but got %d", rec.Code)
+	}
+
+	if got := rec.Header().Get("Retry-After"); got != "1" {
+		t.Errorf("expected Retry-After '1', but got '%s'", got)
+	}
+
+	if rec := send("10.0.0.2"); rec.Code != http.StatusOK {
+		t.Errorf("expected other IPs to be unaffected, but got %d", rec.Code)
+	}
+
+	now = now.Add(time.Second)
+	if rec := send("10.0.0.1"); rec.Code != http.StatusOK {
+		t.Errorf("expected token to be refilled, but got %d", rec.Code)
+	}
+}
+
+func TestRateLimiter_CleansUpIdleBuckets(t *testing.T) {
+	limiter := newRateLimiter(1, 1)
+	now := time.Now()
+	limiter.now = func() time.Time { return now }
+
+	limiter.allow("10.0.0.1")
+	now = now.Add(rateLimitIdleTTL)
+	limiter.allow("10.0.0.2")
+
+	if _, ok := limiter.buckets["10.0.0.1"]; ok {
+		t.Error("expected idle bucket to be removed")
+	}
+
+	if len(limiter.buckets) != 1 {
+		t.Errorf("expected 1 bucket, but got %d", len(limiter.buckets))
+	}
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"net/http"
+	"net/url"
+)
+
+const redactedValue = "***"
+
+// Parâmetros de query que carregam credenciais (ex.: chave da WeatherAPI)
+var sensitiveQueryParams = []string{"key"}
+
+var sensitiveHeaders = []string{"Authorization", "Proxy-Authorization"}
+
+// Mascara credenciais da URL antes de ir para o log
+func redactURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return redactedValue
+	}
+
+	q := u.Query()
+	changed := false
+	for _, param := range sensitiveQueryParams {
+		if q.Has(param) {
+			q.Set(param, redactedValue)
+			changed = true
+		}
+	}
+	if changed {
+		u.RawQuery = q.Encode()
+	}
+	if u.User != nil {
+		u.User = url.User(redactedValue)
+	}
+	return u.String()
+}
+
+// Cópia dos cabeçalhos com os valores sensíveis mascarados
+func redactHeaders(h http.Header) http.Header {
+	redacted := h.Clone()
+	for _, name := range sensitiveHeaders {
+		if _, ok := redacted[http.CanonicalHeaderKey(name)]; ok {
+			redacted.Set(name, redactedValue)
+		}
+	}
+	return redacted
+}
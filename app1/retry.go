@@ -0,0 +1,30 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"l02-01/backoff"
+)
+
+const (
+	// Novas tentativas além da primeira chamada ao app2
+	defaultApp2MaxRetries = 2
+	defaultRetryBaseDelay = 100 * time.Millisecond
+	defaultRetryMaxDelay  = time.Second
+)
+
+// maxRetries conta só as novas tentativas, além da primeira chamada
+func newRetryPolicy(maxRetries int) backoff.Policy {
+	return backoff.Policy{MaxAttempts: maxRetries + 1, BaseDelay: defaultRetryBaseDelay, MaxDelay: defaultRetryMaxDelay}
+}
+
+// Erros de conexão e 5xx do app2 são transitórios; 4xx (CEP não encontrado, inválido...) não mudam com nova tentativa.
+// Um 5xx com Retry-After (limite da WeatherAPI) também não: o app2 pediu para esperar mais que o backoff
+func retryable(ctx context.Context, resp *http.Response, err error) bool {
+	if err != nil {
+		return ctx.Err() == nil
+	}
+	return resp.StatusCode >= http.StatusInternalServerError && resp.Header.Get("Retry-After") == ""
+}
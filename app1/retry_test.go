@@ -0,0 +1,124 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestFetchWeather_RetriesTransientFailure(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			writeJSONError(w, http.StatusServiceUnavailable, codeUpstreamError, "unavailable")
+			return
+		}
+		w.Write([]byte(`{"city": "São Paulo"}`))
+	}))
+	defer server.Close()
+	t.Setenv("APP2_BASE_URL", server.URL)
+
+	app := newTestApplication()
+	app.app2Retry.BaseDelay = time.Millisecond
+
+	rec := httptest.NewRecorder()
+	app.handler(rec, httptest.NewRequest(http.MethodGet, "/weather-by-cep?cep=01001000", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200 after the retry, but got %d: %s", rec.Code, rec.Body.String())
+	}
+	if got := calls.Load(); got != 2 {
+		t.Errorf("expected 2 calls, but got %d", got)
+	}
+}
+
+func TestFetchWeather_RetryLimits(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		retries  int
+		expected int32
+	}{
+		{"gives up after max retries", http.StatusBadGateway, 2, 3},
+		{"retries disabled", http.StatusBadGateway, 0, 1},
+		{"no retry on not found", http.StatusNotFound, 2, 1},
+		{"no retry on invalid zipcode", http.StatusUnprocessableEntity, 2, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls.Add(1)
+				writeJSONError(w, tt.status, codeUpstreamError, "boom")
+			}))
+			defer server.Close()
+			t.Setenv("APP2_BASE_URL", server.URL)
+
+			app := newTestApplication()
+			app.app2Retry = newRetryPolicy(tt.retries)
+			app.app2Retry.BaseDelay = time.Millisecond
+
+			rec := httptest.NewRecorder()
+			app.handler(rec, httptest.NewRequest(http.MethodGet, "/weather-by-cep?cep=01001000", nil))
+
+			if rec.Code != tt.status {
+				t.Errorf("expected status %d, but got %d", tt.status, rec.Code)
+			}
+			if got := calls.Load(); got != tt.expected {
+				t.Errorf("expected %d calls, but got %d", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestFetchWeather_RetryRespectsDeadline(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeJSONError(w, http.StatusBadGateway, codeUpstreamError, "boom")
+	}))
+	defer server.Close()
+	t.Setenv("APP2_BASE_URL", server.URL)
+
+	app := newTestApplication()
+	app.app2Retry = newRetryPolicy(10)
+	app.app2Retry.BaseDelay = time.Second
+	app.app2Retry.MaxDelay = time.Second
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	rec := httptest.NewRecorder()
+	app.handler(rec, httptest.NewRequest(http.MethodGet, "/weather-by-cep?cep=01001000", nil).WithContext(ctx))
+
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("expected to stop at the request deadline, but took %s", elapsed)
+	}
+}
+
+func TestFetchWeather_RelaysRetryAfter(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Header().Set("Retry-After", "30")
+		writeJSONError(w, http.StatusServiceUnavailable, codeRateLimited, "rate limited")
+	}))
+	defer server.Close()
+	t.Setenv("APP2_BASE_URL", server.URL)
+
+	rec := httptest.NewRecorder()
+	newTestApplication().handler(rec, httptest.NewRequest(http.MethodGet, "/weather-by-cep?cep=01001000", nil))
+
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected status 503, but got %d", rec.Code)
+	}
+	if got := rec.Header().Get("Retry-After"); got != "30" {
+		t.Errorf("expected Retry-After '30', but got '%s'", got)
+	}
+	if got := calls.Load(); got != 1 {
+		t.Errorf("expected no retry with Retry-After, but got %d calls", got)
+	}
+}
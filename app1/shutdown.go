@@ -0,0 +1,42 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+)
+
+// Subconjunto do *http.Server usado no desligamento
+type drainer interface {
+	Shutdown(ctx context.Context) error
+}
+
+// Desliga na ordem: para de aceitar conexões e drena as requisições em andamento (até drainTimeout)
+// e só então descarrega a telemetria, para que os spans gerados durante a drenagem sejam exportados
+func gracefulShutdown(logger *slog.Logger, server drainer, shutdownTelemetry func(context.Context) error, drainTimeout, telemetryTimeout time.Duration) error {
+	logger.Info("shutting down server", "drain_timeout", drainTimeout.String())
+	drainCtx, cancelDrain := context.WithTimeout(context.Background(), drainTimeout)
+	defer cancelDrain()
+
+	serverErr := server.Shutdown(drainCtx)
+	if serverErr != nil {
+		logger.Error("failed to shut down server gracefully", "error", serverErr)
+	} else {
+		logger.Info("server shut down")
+	}
+
+	// Prazo próprio: a drenagem pode ter consumido todo o drainTimeout
+	logger.Info("shutting down telemetry")
+	telemetryCtx, cancelTelemetry := context.WithTimeout(context.Background(), telemetryTimeout)
+	defer cancelTelemetry()
+
+	telemetryErr := shutdownTelemetry(telemetryCtx)
+	if telemetryErr != nil {
+		logger.Error("failed to shutdown telemetry gracefully", "error", telemetryErr)
+	} else {
+		logger.Info("telemetry shut down")
+	}
+
+	return errors.Join(serverErr, telemetryErr)
+}
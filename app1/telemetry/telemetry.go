@@ -2,55 +2,372 @@ package telemetry
 
 import (
 	"context"
+	"errors"
 	"log"
+	"math"
+	"net/url"
 	"os"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 
+	"l02-01/version"
+
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/baggage"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
 	"go.opentelemetry.io/otel/exporters/otlp/otlptrace"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
 	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/metric"
+	metricnoop "go.opentelemetry.io/otel/metric/noop"
 	"go.opentelemetry.io/otel/propagation"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
 	"go.opentelemetry.io/otel/sdk/resource"
 	tracesdk "go.opentelemetry.io/otel/sdk/trace"
 	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
 	"go.opentelemetry.io/otel/trace"
+	tracenoop "go.opentelemetry.io/otel/trace/noop"
+)
+
+const (
+	protocolHTTP = "http/protobuf"
+	protocolGRPC = "grpc"
 )
 
-func InitTelemetry(serviceName, tracerName string) (trace.Tracer, func(context.Context) error, error) {
-	jaegerEndpoint := os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT")
-	if jaegerEndpoint == "" {
-		jaegerEndpoint = "jaeger:4318"
+// Copia os membros do baggage (ex.: o CEP adicionado pelo app1) como atributos de cada span iniciado,
+// para que apareçam em todos os spans do trace sem serem repassados explicitamente
+type baggageSpanProcessor struct{}
+
+func (baggageSpanProcessor) OnStart(ctx context.Context, s tracesdk.ReadWriteSpan) {
+	for _, member := range baggage.FromContext(ctx).Members() {
+		s.SetAttributes(attribute.String(member.Key(), member.Value()))
 	}
+}
 
-	// Usando OTLP (OpenTelemetry Protocol) versão HTTP.
-	exporter, err := otlptrace.New(
-		context.Background(),
-		otlptracehttp.NewClient(
-			otlptracehttp.WithInsecure(),
-			otlptracehttp.WithEndpoint(jaegerEndpoint),
-			otlptracehttp.WithTimeout(5*time.Second),
-		),
-	)
+func (baggageSpanProcessor) OnEnd(tracesdk.ReadOnlySpan) {}
+
+func (baggageSpanProcessor) Shutdown(context.Context) error { return nil }
+
+func (baggageSpanProcessor) ForceFlush(context.Context) error { return nil }
+
+// OTEL_EXPORTER_OTLP_PROTOCOL: "http/protobuf" (padrão) ou "grpc"
+func parseProtocol(v string) (string, bool) {
+	switch strings.ToLower(strings.TrimSpace(v)) {
+	case "", protocolHTTP:
+		return protocolHTTP, true
+	case protocolGRPC:
+		return protocolGRPC, true
+	default:
+		return protocolHTTP, false
+	}
+}
+
+type exporterConfig struct {
+	protocol string
+	endpoint string
+	insecure bool
+	headers  map[string]string
+}
+
+// OTEL_EXPORTER_OTLP_INSECURE: sem valor mantém a conexão sem TLS, como era antes da opção existir
+func parseInsecure(v string) (bool, bool) {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return true, true
+	}
+
+	insecure, err := strconv.ParseBool(v)
 	if err != nil {
-		return nil, nil, err
+		return true, false
 	}
+	return insecure, true
+}
+
+// OTEL_EXPORTER_OTLP_HEADERS: pares "chave=valor" separados por vírgula, com valores URL-encoded
+func parseHeaders(v string) (map[string]string, bool) {
+	headers := make(map[string]string)
+	ok := true
+	for _, pair := range strings.Split(v, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+
+		key, value, found := strings.Cut(pair, "=")
+		key = strings.TrimSpace(key)
+		if !found || key == "" {
+			ok = false
+			continue
+		}
+
+		decoded, err := url.PathUnescape(strings.TrimSpace(value))
+		if err != nil {
+			ok = false
+			continue
+		}
+		headers[key] = decoded
+	}
+	return headers, ok
+}
+
+func newTraceClient(cfg exporterConfig) otlptrace.Client {
+	if cfg.protocol == protocolGRPC {
+		endpoint := cfg.endpoint
+		if endpoint == "" {
+			endpoint = "jaeger:4317"
+		}
+		opts := []otlptracegrpc.Option{
+			otlptracegrpc.WithEndpoint(endpoint),
+			otlptracegrpc.WithTimeout(5 * time.Second),
+			otlptracegrpc.WithHeaders(cfg.headers),
+		}
+		// Sem WithInsecure o exporter usa TLS com os certificados do sistema
+		if cfg.insecure {
+			opts = append(opts, otlptracegrpc.WithInsecure())
+		}
+		return otlptracegrpc.NewClient(opts...)
+	}
+
+	endpoint := cfg.endpoint
+	if endpoint == "" {
+		endpoint = "jaeger:4318"
+	}
+	opts := []otlptracehttp.Option{
+		otlptracehttp.WithEndpoint(endpoint),
+		otlptracehttp.WithTimeout(5 * time.Second),
+		otlptracehttp.WithHeaders(cfg.headers),
+	}
+	if cfg.insecure {
+		opts = append(opts, otlptracehttp.WithInsecure())
+	}
+	return otlptracehttp.NewClient(opts...)
+}
+
+// OTEL_SDK_DISABLED: apenas "true" (sem diferenciar maiúsculas) desliga o SDK, como na especificação
+func parseSDKDisabled(v string) (bool, bool) {
+	switch strings.ToLower(strings.TrimSpace(v)) {
+	case "":
+		return false, true
+	case "true":
+		return true, true
+	case "false":
+		return false, true
+	default:
+		return false, false
+	}
+}
+
+// OTEL_METRICS_EXPORTER: "none" (padrão, o Jaeger não recebe métricas) ou "otlp"
+func parseMetricsExporter(v string) (bool, bool) {
+	switch strings.ToLower(strings.TrimSpace(v)) {
+	case "", "none":
+		return false, true
+	case "otlp":
+		return true, true
+	default:
+		return false, false
+	}
+}
+
+// Mesmo coletor, protocolo e cabeçalhos dos traces
+func newMetricExporter(ctx context.Context, cfg exporterConfig) (sdkmetric.Exporter, error) {
+	if cfg.protocol == protocolGRPC {
+		endpoint := cfg.endpoint
+		if endpoint == "" {
+			endpoint = "jaeger:4317"
+		}
+		opts := []otlpmetricgrpc.Option{
+			otlpmetricgrpc.WithEndpoint(endpoint),
+			otlpmetricgrpc.WithTimeout(5 * time.Second),
+			otlpmetricgrpc.WithHeaders(cfg.headers),
+		}
+		if cfg.insecure {
+			opts = append(opts, otlpmetricgrpc.WithInsecure())
+		}
+		return otlpmetricgrpc.New(ctx, opts...)
+	}
+
+	endpoint := cfg.endpoint
+	if endpoint == "" {
+		endpoint = "jaeger:4318"
+	}
+	opts := []otlpmetrichttp.Option{
+		otlpmetrichttp.WithEndpoint(endpoint),
+		otlpmetrichttp.WithTimeout(5 * time.Second),
+		otlpmetrichttp.WithHeaders(cfg.headers),
+	}
+	if cfg.insecure {
+		opts = append(opts, otlpmetrichttp.WithInsecure())
+	}
+	return otlpmetrichttp.New(ctx, opts...)
+}
+
+// Sem readers o provider ainda entrega um Meter válido, que apenas não exporta nada
+func newMeterProvider(res *resource.Resource, readers ...sdkmetric.Reader) *sdkmetric.MeterProvider {
+	opts := []sdkmetric.Option{sdkmetric.WithResource(res)}
+	for _, reader := range readers {
+		opts = append(opts, sdkmetric.WithReader(reader))
+	}
+	return sdkmetric.NewMeterProvider(opts...)
+}
 
+// OTEL_TRACES_SAMPLER_ARG: fração (0.0-1.0) dos traces raiz amostrados; sem valor, amostra tudo.
+// ParentBased mantém a decisão do serviço chamador para os spans seguintes
+func newSampler(v string) (tracesdk.Sampler, bool) {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return tracesdk.ParentBased(tracesdk.AlwaysSample()), true
+	}
+
+	ratio, err := strconv.ParseFloat(v, 64)
+	if err != nil || math.IsNaN(ratio) {
+		return tracesdk.ParentBased(tracesdk.AlwaysSample()), false
+	}
+
+	ratio = math.Min(math.Max(ratio, 0), 1)
+	return tracesdk.ParentBased(tracesdk.TraceIDRatioBased(ratio)), true
+}
+
+// OTEL_RESOURCE_ATTRIBUTES: mesmo formato de OTEL_EXPORTER_OTLP_HEADERS, ordenado pela chave
+func parseResourceAttributes(v string) ([]attribute.KeyValue, bool) {
+	pairs, ok := parseHeaders(v)
+	attrs := make([]attribute.KeyValue, 0, len(pairs))
+	for key, value := range pairs {
+		attrs = append(attrs, attribute.String(key, value))
+	}
+	sort.Slice(attrs, func(i, j int) bool { return attrs[i].Key < attrs[j].Key })
+	return attrs, ok
+}
+
+// Precedência: OTEL_SERVICE_NAME, depois service.name de OTEL_RESOURCE_ATTRIBUTES e, por fim, o nome do código
+func resolveServiceName(fallback, envName string, attrs []attribute.KeyValue) string {
+	if name := strings.TrimSpace(envName); name != "" {
+		return name
+	}
+	for _, attr := range attrs {
+		if attr.Key == semconv.ServiceNameKey && attr.Value.AsString() != "" {
+			return attr.Value.AsString()
+		}
+	}
+	return fallback
+}
+
+// Atributos vazios são omitidos, mantendo o resource anterior quando o build não injeta a versão.
+// Os extras (OTEL_RESOURCE_ATTRIBUTES) sobrescrevem os padrões, exceto service.name
+func newResource(serviceName, environment string, extra ...attribute.KeyValue) *resource.Resource {
+	attrs := []attribute.KeyValue{
+		attribute.String("application.origin", "app1"),
+	}
+	if version.Version != "" {
+		attrs = append(attrs, semconv.ServiceVersionKey.String(version.Version))
+	}
+	if version.Commit != "" {
+		attrs = append(attrs, attribute.String("build.commit", version.Commit))
+	}
+	if version.BuildTime != "" {
+		attrs = append(attrs, attribute.String("build.time", version.BuildTime))
+	}
+	// Permite filtrar os traces por ambiente (ex.: production, staging) no Jaeger
+	if environment != "" {
+		attrs = append(attrs, semconv.DeploymentEnvironmentKey.String(environment))
+	}
+	// Em chaves repetidas vale o último valor
+	attrs = append(attrs, extra...)
+	attrs = append(attrs, semconv.ServiceNameKey.String(serviceName))
+
+	return resource.NewWithAttributes(semconv.SchemaURL, attrs...)
+}
+
+func InitTelemetry(serviceName, tracerName, meterName string) (trace.Tracer, metric.Meter, func(context.Context) error, error) {
+	disabled, ok := parseSDKDisabled(os.Getenv("OTEL_SDK_DISABLED"))
+	if !ok {
+		log.Printf("Invalid OTEL_SDK_DISABLED %q, telemetry enabled", os.Getenv("OTEL_SDK_DISABLED"))
+	}
+	// Nenhum exporter é criado; o propagador continua repassando o trace recebido nas chamadas seguintes
+	if disabled {
+		otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+		log.Printf("Telemetry disabled by OTEL_SDK_DISABLED for service: %s", serviceName)
+		return tracenoop.NewTracerProvider().Tracer(tracerName), metricnoop.NewMeterProvider().Meter(meterName), func(context.Context) error { return nil }, nil
+	}
+
+	protocol, ok := parseProtocol(os.Getenv("OTEL_EXPORTER_OTLP_PROTOCOL"))
+	if !ok {
+		log.Printf("Invalid OTEL_EXPORTER_OTLP_PROTOCOL %q, using %s", os.Getenv("OTEL_EXPORTER_OTLP_PROTOCOL"), protocol)
+	}
+
+	insecure, ok := parseInsecure(os.Getenv("OTEL_EXPORTER_OTLP_INSECURE"))
+	if !ok {
+		log.Printf("Invalid OTEL_EXPORTER_OTLP_INSECURE %q, using insecure connection", os.Getenv("OTEL_EXPORTER_OTLP_INSECURE"))
+	}
+
+	// Os valores podem conter tokens, por isso não vão para o log
+	headers, ok := parseHeaders(os.Getenv("OTEL_EXPORTER_OTLP_HEADERS"))
+	if !ok {
+		log.Printf("Ignoring malformed entries in OTEL_EXPORTER_OTLP_HEADERS")
+	}
+
+	resourceAttrs, ok := parseResourceAttributes(os.Getenv("OTEL_RESOURCE_ATTRIBUTES"))
+	if !ok {
+		log.Printf("Ignoring malformed entries in OTEL_RESOURCE_ATTRIBUTES")
+	}
+	serviceName = resolveServiceName(serviceName, os.Getenv("OTEL_SERVICE_NAME"), resourceAttrs)
+
+	sampler, ok := newSampler(os.Getenv("OTEL_TRACES_SAMPLER_ARG"))
+	if !ok {
+		log.Printf("Invalid OTEL_TRACES_SAMPLER_ARG %q, sampling all traces", os.Getenv("OTEL_TRACES_SAMPLER_ARG"))
+	}
+
+	metricsEnabled, ok := parseMetricsExporter(os.Getenv("OTEL_METRICS_EXPORTER"))
+	if !ok {
+		log.Printf("Invalid OTEL_METRICS_EXPORTER %q, metrics export disabled", os.Getenv("OTEL_METRICS_EXPORTER"))
+	}
+
+	cfg := exporterConfig{
+		protocol: protocol,
+		endpoint: os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT"),
+		insecure: insecure,
+		headers:  headers,
+	}
+
+	// Usando OTLP (OpenTelemetry Protocol) via HTTP ou gRPC.
+	exporter, err := otlptrace.New(context.Background(), newTraceClient(cfg))
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	res := newResource(serviceName, os.Getenv("DEPLOYMENT_ENVIRONMENT"), resourceAttrs...)
 	tp := tracesdk.NewTracerProvider(
+		tracesdk.WithSpanProcessor(baggageSpanProcessor{}),
 		tracesdk.WithBatcher(exporter),
-		tracesdk.WithResource(resource.NewWithAttributes(
-			semconv.SchemaURL,
-			semconv.ServiceNameKey.String(serviceName),
-			attribute.String("application.origin", "app1"),
-		)),
+		tracesdk.WithSampler(sampler),
+		tracesdk.WithResource(res),
 	)
 
+	var readers []sdkmetric.Reader
+	if metricsEnabled {
+		metricExporter, err := newMetricExporter(context.Background(), cfg)
+		if err != nil {
+			tp.Shutdown(context.Background())
+			return nil, nil, nil, err
+		}
+		readers = append(readers, sdkmetric.NewPeriodicReader(metricExporter))
+	}
+	mp := newMeterProvider(res, readers...)
+
 	otel.SetTracerProvider(tp)
+	otel.SetMeterProvider(mp)
 	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
 	otel.SetErrorHandler(otel.ErrorHandlerFunc(func(err error) {
 		log.Printf("OpenTelemetry Error: %v", err)
 	}))
 
 	log.Printf("Telemetry initialized for service: %s", serviceName)
-	return tp.Tracer(tracerName), tp.Shutdown, nil
+	// Descarrega os dois pipelines mesmo que um deles falhe
+	shutdown := func(ctx context.Context) error {
+		return errors.Join(tp.Shutdown(ctx), mp.Shutdown(ctx))
+	}
+	return tp.Tracer(tracerName), mp.Meter(meterName), shutdown, nil
 }
@@ -0,0 +1,18 @@
+package version
+
+// Sobrescritos no build: go build -ldflags "-X l02-01/version.Version=1.2.3 -X l02-01/version.Commit=abc123 -X l02-01/version.BuildTime=2026-01-01T00:00:00Z"
+var (
+	Version   = "dev"
+	Commit    = "unknown"
+	BuildTime = "unknown"
+)
+
+type Info struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	BuildTime string `json:"buildTime"`
+}
+
+func Get() Info {
+	return Info{Version: Version, Commit: Commit, BuildTime: BuildTime}
+}
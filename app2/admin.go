@@ -0,0 +1,78 @@
+package main
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+)
+
+// Só na interface local por padrão: os perfis e as rotas /admin expõem detalhes internos do processo
+const defaultAdminAddr = "localhost:6060"
+
+const adminTokenHeader = "X-Admin-Token"
+
+type cacheFlushResponse struct {
+	// Entradas removidas por cache de CEP (viacep e viacep_not_found)
+	Evicted map[string]int `json:"evicted"`
+}
+
+// Mux do listener administrativo: pprof com ENABLE_PPROF e as rotas /admin com ADMIN_TOKEN
+func (app *application) adminHandler(pprofEnabled bool) http.Handler {
+	mux := http.NewServeMux()
+	if pprofEnabled {
+		registerPprof(mux)
+	}
+	if app.adminToken != "" {
+		mux.Handle("/admin/cache/flush", chain(http.HandlerFunc(app.flushCachesHandler),
+			app.requireAdminToken,
+			methods(http.MethodPost),
+		))
+	}
+	return mux
+}
+
+// Exige o segredo compartilhado no X-Admin-Token, comparado em tempo constante
+func (app *application) requireAdminToken(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := r.Header.Get(adminTokenHeader)
+		if token == "" || subtle.ConstantTimeCompare([]byte(token), []byte(app.adminToken)) != 1 {
+			app.logger.Warn("admin request rejected", "path", r.URL.Path, "remote_addr", r.RemoteAddr)
+			writeJSONError(w, http.StatusUnauthorized, codeUnauthorized, localize(r, msgUnauthorized))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Esvazia os caches de CEP em memória sem reiniciar o serviço, por exemplo após dados errados do ViaCEP.
+// O clima não tem cache em memória no app2, então não há o que esvaziar do lado da WeatherAPI
+func (app *application) flushCachesHandler(w http.ResponseWriter, r *http.Request) {
+	resp := cacheFlushResponse{Evicted: make(map[string]int, len(app.caches))}
+	for name, flush := range app.caches {
+		resp.Evicted[name] = flush()
+	}
+	app.logger.Info("cep caches flushed", "evicted", resp.Evicted)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// Sem WriteTimeout, que cortaria o /debug/pprof/profile (30s por padrão); uma falha aqui só gera log,
+// sem derrubar o serviço
+func startAdminServer(logger *slog.Logger, addr string, handler http.Handler) {
+	server := &http.Server{
+		Addr:              addr,
+		Handler:           handler,
+		ReadHeaderTimeout: defaultServerReadHeaderTimeout,
+	}
+
+	go func() {
+		logger.Info("admin server listening", "addr", addr)
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Error("can not start admin server", "addr", addr, "error", err)
+		}
+	}()
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"l02-02/viacep"
+
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+func TestAdminHandler_FlushCaches(t *testing.T) {
+	var calls atomic.Int32
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Write([]byte(`{"cep": "01001-000", "localidade": "São Paulo", "uf": "SP"}`))
+	}))
+	defer upstream.Close()
+
+	client := viacep.NewClient(slog.New(slog.DiscardHandler), noop.NewTracerProvider().Tracer("test"),
+		viacep.WithBaseURL(upstream.URL),
+		viacep.WithCache(time.Hour, 10),
+	)
+	client.FindAddressByCep(context.Background(), "01001-000")
+
+	viaCep, weather := healthyMocks()
+	app := newTestApplication(viaCep, weather)
+	app.adminToken = "secret"
+	app.caches = map[string]func() int{"viacep": client.FlushCache, "viacep_not_found": client.FlushNegativeCache}
+
+	req := httptest.NewRequest(http.MethodPost, "/admin/cache/flush", nil)
+	req.Header.Set(adminTokenHeader, "secret")
+	rec := httptest.NewRecorder()
+	app.adminHandler(false).ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, but got %d", rec.Code)
+	}
+	var body cacheFlushResponse
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatalf("expected a JSON body, but got: %v", err)
+	}
+	if body.Evicted["viacep"] != 1 || body.Evicted["viacep_not_found"] != 0 {
+		t.Errorf("expected 1 evicted address and no negative entries, but got %v", body.Evicted)
+	}
+
+	// Sem a entrada no cache, a próxima consulta volta ao ViaCEP
+	client.FindAddressByCep(context.Background(), "01001-000")
+	if got := calls.Load(); got != 2 {
+		t.Errorf("expected 2 upstream calls, but got %d", got)
+	}
+}
+
+func TestAdminHandler_RejectsRequests(t *testing.T) {
+	tests := []struct {
+		name     string
+		method   string
+		token    string
+		expected int
+	}{
+		{"missing secret", http.MethodPost, "", http.StatusUnauthorized},
+		{"wrong secret", http.MethodPost, "wrong", http.StatusUnauthorized},
+		{"wrong method", http.MethodGet, "secret", http.StatusMethodNotAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			flushed := false
+			viaCep, weather := healthyMocks()
+			app := newTestApplication(viaCep, weather)
+			app.adminToken = "secret"
+			app.caches = map[string]func() int{"viacep": func() int { flushed = true; return 0 }}
+
+			req := httptest.NewRequest(tt.method, "/admin/cache/flush", nil)
+			if tt.token != "" {
+				req.Header.Set(adminTokenHeader, tt.token)
+			}
+			rec := httptest.NewRecorder()
+			app.adminHandler(false).ServeHTTP(rec, req)
+
+			if rec.Code != tt.expected {
+				t.Errorf("expected status %d, but got %d", tt.expected, rec.Code)
+			}
+			if flushed {
+				t.Error("expected the caches to be kept")
+			}
+		})
+	}
+}
+
+func TestAdminHandler_DisabledWithoutToken(t *testing.T) {
+	viaCep, weather := healthyMocks()
+	rec := httptest.NewRecorder()
+	newTestApplication(viaCep, weather).adminHandler(true).ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/admin/cache/flush", nil))
+
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected status 404 without ADMIN_TOKEN, but got %d", rec.Code)
+	}
+}
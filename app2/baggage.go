@@ -0,0 +1,19 @@
+package main
+
+import (
+	"context"
+	"net/http"
+
+	"go.opentelemetry.io/otel/baggage"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// Chave do CEP normalizado que o app1 adiciona ao baggage
+const cepBaggageKey = "cep"
+
+// CEP do baggage recebido (vazio sem baggage). Lido direto do cabeçalho, e não do contexto, para
+// funcionar também nos middlewares que rodam antes do otelServer
+func cepFromBaggage(r *http.Request) string {
+	ctx := propagation.Baggage{}.Extract(context.Background(), propagation.HeaderCarrier(r.Header))
+	return baggage.FromContext(ctx).Member(cepBaggageKey).Value()
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel/baggage"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+func TestLogRequest_CepBaggage(t *testing.T) {
+	var buf bytes.Buffer
+	app := &application{logger: newLogger(&buf, slog.LevelInfo)}
+	handler := app.logRequest(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	// Mesmo baggage que o app1 envia, injetado pelo propagador
+	member, _ := baggage.NewMember(cepBaggageKey, "01001-000")
+	bag, _ := baggage.New(member)
+	req := httptest.NewRequest(http.MethodGet, "/get-weather-by-cep?cep=01001000", nil)
+	propagation.Baggage{}.Inject(baggage.ContextWithBaggage(context.Background(), bag), propagation.HeaderCarrier(req.Header))
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	var entry map[string]any
+	if err := json.Unmarshal([]byte(lines[len(lines)-1]), &entry); err != nil {
+		t.Fatalf("expected a JSON log line, but got %q: %v", buf.String(), err)
+	}
+	if entry["baggage_cep"] != "01001-000" {
+		t.Errorf("expected baggage_cep '01001-000', but got %v", entry["baggage_cep"])
+	}
+}
+
+func TestCepFromBaggage_Missing(t *testing.T) {
+	if got := cepFromBaggage(httptest.NewRequest(http.MethodGet, "/get-weather-by-cep", nil)); got != "" {
+		t.Errorf("expected no cep without baggage, but got '%s'", got)
+	}
+}
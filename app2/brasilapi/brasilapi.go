@@ -0,0 +1,140 @@
+package brasilapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"l02-02/httpx"
+	"l02-02/viacep"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Subconjunto do *slog.Logger usado pelo cliente
+type Logger interface {
+	WarnContext(ctx context.Context, msg string, args ...any)
+	ErrorContext(ctx context.Context, msg string, args ...any)
+}
+
+type Client struct {
+	httpClient *http.Client
+	baseURL    string
+	logger     Logger
+	tracer     trace.Tracer
+}
+
+type brasilApiResponse struct {
+	Cep          string   `json:"cep"`
+	State        string   `json:"state"`
+	City         string   `json:"city"`
+	Neighborhood string   `json:"neighborhood"`
+	Street       string   `json:"street"`
+	Location     location `json:"location"`
+}
+
+// A v2 devolve as coordenadas como texto e omite ambas quando não as conhece
+type location struct {
+	Coordinates struct {
+		Latitude  string `json:"latitude"`
+		Longitude string `json:"longitude"`
+	} `json:"coordinates"`
+}
+
+func NewClient(logger Logger, tracer trace.Tracer) *Client {
+	return &Client{
+		httpClient: httpx.NewInstrumentedClient(httpx.WithTimeout(5 * time.Second)),
+		baseURL:    "https://brasilapi.com.br",
+		logger:     logger,
+		tracer:     tracer,
+	}
+}
+
+// Mesmo contrato do viacep.Client: os erros retornados são os do pacote viacep
+func (c *Client) FindAddressByCep(ctx context.Context, cep string) (*viacep.ViaCepResponse, error) {
+	ctx, span := c.tracer.Start(ctx, "BrasilAPI.FindAddressByCep")
+	span.SetAttributes(attribute.String("cep.value", cep))
+	defer span.End()
+
+	url := fmt.Sprintf("%s/api/cep/v2/%s", c.baseURL, cep)
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "invalid request")
+		return nil, err
+	}
+	httpx.AcceptGzip(req)
+
+	start := time.Now()
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "BrasilAPI request failed")
+		c.logger.ErrorContext(ctx, "error requesting from BrasilAPI", "cep", cep, "duration_ms", time.Since(start).Milliseconds(), "error", err)
+		if httpx.IsTimeout(err) {
+			return nil, viacep.ErrTimeout
+		}
+		return nil, viacep.ErrInternal
+	}
+	defer resp.Body.Close()
+
+	span.SetAttributes(semconv.HTTPStatusCodeKey.Int(resp.StatusCode))
+	if resp.StatusCode == http.StatusNotFound {
+		span.AddEvent("BrasilAPI returned not found")
+		return nil, viacep.ErrCepNotFound
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		span.AddEvent("BrasilAPI returned non-OK status")
+		span.SetStatus(codes.Error, fmt.Sprintf("BrasilAPI returned status %d", resp.StatusCode))
+		return nil, viacep.ErrInternal
+	}
+
+	var data brasilApiResponse
+	body, err := httpx.ResponseBody(resp, httpx.DefaultMaxResponseBytes)
+	if err == nil {
+		err = json.NewDecoder(body).Decode(&data)
+	}
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "invalid BrasilAPI response")
+		c.logger.ErrorContext(ctx, "error decoding BrasilAPI response", "cep", cep, "status", resp.StatusCode, "error", err)
+		return nil, viacep.ErrInternal
+	}
+
+	return &viacep.ViaCepResponse{
+		Cep:         formatCep(data.Cep),
+		Street:      data.Street,
+		City:        data.City,
+		State:       data.State,
+		Coordinates: data.Location.coordinates(),
+	}, nil
+}
+
+// nil quando alguma das coordenadas está ausente ou inválida
+func (l location) coordinates() *viacep.Coordinates {
+	lat, err := strconv.ParseFloat(l.Coordinates.Latitude, 64)
+	if err != nil {
+		return nil
+	}
+	lon, err := strconv.ParseFloat(l.Coordinates.Longitude, 64)
+	if err != nil {
+		return nil
+	}
+	return &viacep.Coordinates{Lat: lat, Lon: lon}
+}
+
+// A BrasilAPI devolve o CEP sem hífen
+func formatCep(cep string) string {
+	if len(cep) == 8 {
+		return cep[:5] + "-" + cep[5:]
+	}
+	return cep
+}
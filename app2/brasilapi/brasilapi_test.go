@@ -0,0 +1,101 @@
+package brasilapi
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"l02-02/viacep"
+
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+type mockLogger struct{}
+
+func (m *mockLogger) WarnContext(ctx context.Context, msg string, args ...any) {}
+
+func (m *mockLogger) ErrorContext(ctx context.Context, msg string, args ...any) {}
+
+func TestFindAddressByCep_Success(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/cep/v2/01001-000" {
+			t.Errorf("expected path '/api/cep/v2/01001-000', but got '%s'", r.URL.Path)
+		}
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"cep": "01001000", "state": "SP", "city": "São Paulo", "street": "Praça da Sé"}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(&mockLogger{}, noop.NewTracerProvider().Tracer("test"))
+	client.baseURL = server.URL
+
+	address, err := client.FindAddressByCep(context.Background(), "01001-000")
+	if err != nil {
+		t.Fatalf("expected no error, but got: %v", err)
+	}
+
+	if address.City != "São Paulo" {
+		t.Errorf("expected city 'São Paulo', but got '%s'", address.City)
+	}
+
+	if address.Cep != "01001-000" {
+		t.Errorf("expected cep '01001-000', but got '%s'", address.Cep)
+	}
+}
+
+func TestFindAddressByCep_NotFound(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"name": "CepPromiseError", "type": "service_error"}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(&mockLogger{}, noop.NewTracerProvider().Tracer("test"))
+	client.baseURL = server.URL
+
+	_, err := client.FindAddressByCep(context.Background(), "99999-999")
+	if err != viacep.ErrCepNotFound {
+		t.Errorf("expected error '%v', but got '%v'", viacep.ErrCepNotFound, err)
+	}
+}
+
+func TestFindAddressByCep_Coordinates(t *testing.T) {
+	tests := []struct {
+		name     string
+		location string
+		expected *viacep.Coordinates
+	}{
+		{"present", `{"type": "Point", "coordinates": {"longitude": "-46.6339", "latitude": "-23.5503"}}`, &viacep.Coordinates{Lat: -23.5503, Lon: -46.6339}},
+		{"absent", `{"type": "Point", "coordinates": {}}`, nil},
+		{"invalid", `{"type": "Point", "coordinates": {"longitude": "x", "latitude": "-23.5503"}}`, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(`{"cep": "01001000", "state": "SP", "city": "São Paulo", "location": ` + tt.location + `}`))
+			}))
+			defer server.Close()
+
+			client := NewClient(&mockLogger{}, noop.NewTracerProvider().Tracer("test"))
+			client.baseURL = server.URL
+
+			address, err := client.FindAddressByCep(context.Background(), "01001-000")
+			if err != nil {
+				t.Fatalf("expected no error, but got: %v", err)
+			}
+
+			if tt.expected == nil {
+				if address.Coordinates != nil {
+					t.Errorf("expected no coordinates, but got %+v", address.Coordinates)
+				}
+				return
+			}
+
+			if address.Coordinates == nil || *address.Coordinates != *tt.expected {
+				t.Errorf("expected coordinates %+v, but got %+v", tt.expected, address.Coordinates)
+			}
+		})
+	}
+}
@@ -0,0 +1,33 @@
+package cep
+
+import (
+	"errors"
+	"strings"
+)
+
+var ErrInvalidCep = errors.New("invalid zipcode")
+
+// Aceita "01001-000", "01001000", "01.001-000", " 01001 000 "... e devolve sempre no formato NNNNN-NNN.
+// Apenas espaços, pontos e hífens são descartados; qualquer outro caractere invalida o CEP.
+func NormalizeCEP(raw string) (string, error) {
+	digits := make([]byte, 0, 8)
+	for _, r := range raw {
+		switch {
+		case r >= '0' && r <= '9':
+			digits = append(digits, byte(r))
+		case r == ' ' || r == '.' || r == '-':
+		default:
+			return "", ErrInvalidCep
+		}
+	}
+
+	if len(digits) != 8 {
+		return "", ErrInvalidCep
+	}
+
+	var b strings.Builder
+	b.Write(digits[:5])
+	b.WriteByte('-')
+	b.Write(digits[5:])
+	return b.String(), nil
+}
@@ -0,0 +1,229 @@
+package circuitbreaker
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var ErrOpen = errors.New("circuit breaker is open")
+
+// Quantidade de resultados recentes usados na taxa de sucesso do Snapshot
+const statsWindow = 100
+
+type State int
+
+const (
+	Closed State = iota
+	Open
+	HalfOpen
+)
+
+func (s State) String() string {
+	switch s {
+	case Open:
+		return "open"
+	case HalfOpen:
+		return "half-open"
+	default:
+		return "closed"
+	}
+}
+
+// Período de estado em que uma chamada foi liberada; muda a cada transição
+type Generation uint64
+
+type Transition struct {
+	From State
+	To   State
+}
+
+func (t Transition) Changed() bool {
+	return t.From != t.To
+}
+
+// Abre após "threshold" falhas consecutivas e, passado o "cooldown", libera uma única chamada de teste
+type Breaker struct {
+	mu        sync.Mutex
+	threshold int
+	cooldown  time.Duration
+	state     State
+	gen       Generation
+	failures  int
+	openedAt  time.Time
+	probing   bool
+	now       func() time.Time
+	// Últimos resultados em um buffer circular, para a taxa de sucesso
+	outcomes    [statsWindow]bool
+	calls       int
+	next        int
+	lastError   string
+	lastErrorAt time.Time
+}
+
+// Estado e contadores em memória, para exposição em health checks
+type Snapshot struct {
+	State               State
+	ConsecutiveFailures int
+	// Sobre as últimas Calls chamadas (no máximo statsWindow); 1 sem chamadas registradas
+	SuccessRate float64
+	Calls       int
+	LastError   string
+	LastErrorAt time.Time
+}
+
+func New(threshold int, cooldown time.Duration) *Breaker {
+	return &Breaker{
+		threshold: threshold,
+		cooldown:  cooldown,
+		now:       time.Now,
+	}
+}
+
+func (b *Breaker) State() State {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state
+}
+
+// Retorna ErrOpen enquanto o circuito estiver aberto (ou com a chamada de teste em andamento).
+// A geração devolvida acompanha o resultado em Record, RecordError, RecordResponse ou Abandon
+func (b *Breaker) Allow() (Transition, Generation, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	t := Transition{From: b.state, To: b.state}
+	switch b.state {
+	case Open:
+		if b.now().Sub(b.openedAt) < b.cooldown {
+			return t, b.gen, ErrOpen
+		}
+		b.setState(HalfOpen)
+		b.probing = true
+	case HalfOpen:
+		if b.probing {
+			return t, b.gen, ErrOpen
+		}
+		b.probing = true
+	}
+
+	t.To = b.state
+	return t, b.gen, nil
+}
+
+// Registra o resultado de uma chamada liberada por Allow
+func (b *Breaker) Record(gen Generation, success bool) Transition {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.record(gen, success)
+}
+
+// Registra uma falha junto com o motivo, exposto no Snapshot
+func (b *Breaker) RecordError(gen Generation, err error) Transition {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if err != nil {
+		b.lastError = err.Error()
+		b.lastErrorAt = b.now()
+	}
+	return b.record(gen, false)
+}
+
+// Resultado de uma requisição HTTP: erro de rede ou status 5xx contam como falha
+func (b *Breaker) RecordResponse(gen Generation, resp *http.Response, err error) Transition {
+	if err != nil {
+		return b.RecordError(gen, err)
+	}
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return b.RecordError(gen, fmt.Errorf("upstream returned status %d", resp.StatusCode))
+	}
+	return b.Record(gen, true)
+}
+
+// Descarta uma chamada liberada por Allow sem registrar resultado (ex.: cancelada pelo chamador),
+// devolvendo a vaga da chamada de teste
+func (b *Breaker) Abandon(gen Generation) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if gen == b.gen {
+		b.probing = false
+	}
+}
+
+// Resultados de uma geração anterior (ou que chegam com o circuito aberto) entram só nas
+// estatísticas do Snapshot: não fecham o circuito nem estendem o cooldown
+func (b *Breaker) record(gen Generation, success bool) Transition {
+	b.outcomes[b.next] = success
+	b.next = (b.next + 1) % statsWindow
+	if b.calls < statsWindow {
+		b.calls++
+	}
+
+	t := Transition{From: b.state, To: b.state}
+	if gen != b.gen || b.state == Open {
+		return t
+	}
+
+	b.probing = false
+	if success {
+		b.failures = 0
+		b.setState(Closed)
+	} else {
+		b.failures++
+		if b.state == HalfOpen || b.failures >= b.threshold {
+			b.setState(Open)
+			b.openedAt = b.now()
+		}
+	}
+
+	t.To = b.state
+	return t
+}
+
+func (b *Breaker) setState(s State) {
+	if s != b.state {
+		b.state = s
+		b.gen++
+	}
+}
+
+func (b *Breaker) Snapshot() Snapshot {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	s := Snapshot{
+		State:               b.state,
+		ConsecutiveFailures: b.failures,
+		SuccessRate:         1,
+		Calls:               b.calls,
+		LastError:           b.lastError,
+		LastErrorAt:         b.lastErrorAt,
+	}
+	if b.calls > 0 {
+		successes := 0
+		for _, ok := range b.outcomes[:b.calls] {
+			if ok {
+				successes++
+			}
+		}
+		s.SuccessRate = float64(successes) / float64(b.calls)
+	}
+	return s
+}
+
+func RecordTransition(span trace.Span, t Transition) {
+	if !t.Changed() {
+		return
+	}
+	span.AddEvent("circuit_breaker.state_change", trace.WithAttributes(
+		attribute.String("circuit_breaker.from", t.From.String()),
+		attribute.String("circuit_breaker.to", t.To.String()),
+	))
+}
@@ -0,0 +1,210 @@
+package circuitbreaker
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestBreaker_FullCycle(t *testing.T) {
+	b := New(2, time.Minute)
+	now := time.Now()
+	b.now = func() time.Time { return now }
+
+	for i := 0; i < 2; i++ {
+		_, gen, err := b.Allow()
+		if err != nil {
+			t.Fatalf("expected call %d to be allowed, but got: %v", i, err)
+		}
+		b.Record(gen, false)
+	}
+
+	if b.State() != Open {
+		t.Fatalf("expected state 'open', but got '%s'", b.State())
+	}
+
+	if _, _, err := b.Allow(); err != ErrOpen {
+		t.Errorf("expected error '%v', but got '%v'", ErrOpen, err)
+	}
+
+	now = now.Add(2 * time.Minute)
+	transition, gen, err := b.Allow()
+	if err != nil {
+		t.Fatalf("expected probe to be allowed, but got: %v", err)
+	}
+
+	if transition.From != Open || transition.To != HalfOpen {
+		t.Errorf("expected transition open->half-open, but got %s->%s", transition.From, transition.To)
+	}
+
+	if _, _, err := b.Allow(); err != ErrOpen {
+		t.Errorf("expected concurrent probe to be rejected, but got '%v'", err)
+	}
+
+	transition = b.Record(gen, true)
+	if transition.From != HalfOpen || transition.To != Closed {
+		t.Errorf("expected transition half-open->closed, but got %s->%s", transition.From, transition.To)
+	}
+
+	if _, _, err := b.Allow(); err != nil {
+		t.Errorf("expected call to be allowed after closing, but got: %v", err)
+	}
+}
+
+func TestBreaker_FailedProbeReopens(t *testing.T) {
+	b := New(1, time.Minute)
+	now := time.Now()
+	b.now = func() time.Time { return now }
+
+	_, gen, _ := b.Allow()
+	b.Record(gen, false)
+
+	now = now.Add(2 * time.Minute)
+	_, gen, _ = b.Allow()
+	b.Record(gen, false)
+
+	if b.State() != Open {
+		t.Fatalf("expected state 'open', but got '%s'", b.State())
+	}
+
+	if _, _, err := b.Allow(); err != ErrOpen {
+		t.Errorf("expected error '%v', but got '%v'", ErrOpen, err)
+	}
+}
+
+func TestBreaker_SuccessResetsFailures(t *testing.T) {
+	b := New(2, time.Minute)
+
+	for _, success := range []bool{false, true, false} {
+		_, gen, _ := b.Allow()
+		b.Record(gen, success)
+	}
+
+	if b.State() != Closed {
+		t.Errorf("expected state 'closed', but got '%s'", b.State())
+	}
+}
+
+func TestBreaker_LateResultAfterOpen(t *testing.T) {
+	tests := []struct {
+		name    string
+		success bool
+	}{
+		{"late success does not close", true},
+		{"late failure does not extend the cooldown", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := New(1, time.Minute)
+			now := time.Now()
+			b.now = func() time.Time { return now }
+
+			// Liberada com o circuito fechado, termina só depois que ele abre
+			_, late, _ := b.Allow()
+			_, gen, _ := b.Allow()
+			b.Record(gen, false)
+
+			now = now.Add(30 * time.Second)
+			if transition := b.Record(late, tt.success); transition.Changed() {
+				t.Errorf("expected no transition, but got %s->%s", transition.From, transition.To)
+			}
+			if b.State() != Open {
+				t.Fatalf("expected state 'open', but got '%s'", b.State())
+			}
+
+			now = now.Add(31 * time.Second)
+			if _, _, err := b.Allow(); err != nil {
+				t.Errorf("expected probe after the original cooldown, but got: %v", err)
+			}
+		})
+	}
+}
+
+func TestBreaker_LateResultDuringProbe(t *testing.T) {
+	b := New(1, time.Minute)
+	now := time.Now()
+	b.now = func() time.Time { return now }
+
+	_, late, _ := b.Allow()
+	_, gen, _ := b.Allow()
+	b.Record(gen, false)
+
+	now = now.Add(2 * time.Minute)
+	_, probe, _ := b.Allow()
+
+	b.Record(late, true)
+	if b.State() != HalfOpen {
+		t.Fatalf("expected state 'half-open', but got '%s'", b.State())
+	}
+	if _, _, err := b.Allow(); err != ErrOpen {
+		t.Errorf("expected probe to remain in flight, but got '%v'", err)
+	}
+
+	if transition := b.Record(probe, true); transition.To != Closed {
+		t.Errorf("expected the probe to close the breaker, but got '%s'", transition.To)
+	}
+}
+
+func TestBreaker_AbandonReleasesProbe(t *testing.T) {
+	b := New(1, time.Minute)
+	now := time.Now()
+	b.now = func() time.Time { return now }
+
+	_, gen, _ := b.Allow()
+	b.Record(gen, false)
+
+	now = now.Add(2 * time.Minute)
+	_, probe, _ := b.Allow()
+	b.Abandon(probe)
+
+	if _, _, err := b.Allow(); err != nil {
+		t.Errorf("expected a new probe after abandoning the previous one, but got: %v", err)
+	}
+}
+
+func TestBreaker_Snapshot(t *testing.T) {
+	b := New(2, time.Minute)
+	now := time.Date(2026, time.January, 1, 12, 0, 0, 0, time.UTC)
+	b.now = func() time.Time { return now }
+
+	if s := b.Snapshot(); s.SuccessRate != 1 || s.Calls != 0 {
+		t.Errorf("expected success rate 1 without calls, but got %v over %d calls", s.SuccessRate, s.Calls)
+	}
+
+	_, gen, _ := b.Allow()
+	b.Record(gen, true)
+	b.RecordResponse(gen, &http.Response{StatusCode: http.StatusBadGateway}, nil)
+	b.RecordError(gen, errors.New("connection refused"))
+	s := b.Snapshot()
+
+	if s.State != Open {
+		t.Errorf("expected state 'open', but got '%s'", s.State)
+	}
+	if s.ConsecutiveFailures != 2 || s.Calls != 3 {
+		t.Errorf("expected 2 consecutive failures over 3 calls, but got %d over %d", s.ConsecutiveFailures, s.Calls)
+	}
+	if s.SuccessRate != 1.0/3 {
+		t.Errorf("expected success rate 1/3, but got %v", s.SuccessRate)
+	}
+	if s.LastError != "connection refused" || !s.LastErrorAt.Equal(now) {
+		t.Errorf("expected the last error at %v, but got '%s' at %v", now, s.LastError, s.LastErrorAt)
+	}
+}
+
+func TestBreaker_SnapshotWindow(t *testing.T) {
+	b := New(statsWindow*2, time.Minute)
+	_, gen, _ := b.Allow()
+	for range statsWindow {
+		b.Record(gen, false)
+	}
+	for range statsWindow / 2 {
+		b.Record(gen, true)
+	}
+
+	// Só os últimos statsWindow resultados contam
+	if s := b.Snapshot(); s.Calls != statsWindow || s.SuccessRate != 0.5 {
+		t.Errorf("expected success rate 0.5 over %d calls, but got %v over %d", statsWindow, s.SuccessRate, s.Calls)
+	}
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// Limites do http.Server contra clientes lentos e conexões presas
+	defaultServerReadHeaderTimeout = 5 * time.Second
+	defaultServerReadTimeout       = 10 * time.Second
+	defaultServerWriteTimeout      = 15 * time.Second
+	defaultServerIdleTimeout       = 60 * time.Second
+
+	// Drenagem das requisições em andamento e descarga dos spans no desligamento
+	defaultShutdownTimeout          = 5 * time.Second
+	defaultTelemetryShutdownTimeout = 10 * time.Second
+
+	defaultReadyTimeout      = 2 * time.Second
+	defaultViaCepTimeout     = 5 * time.Second
+	defaultWeatherApiTimeout = 5 * time.Second
+
+	// Requisições simultâneas a cada API externa (0 desabilita o limite)
+	defaultUpstreamMaxConcurrency = 50
+
+	// Tempo fora do rodízio de uma chave da WeatherAPI que recebeu 429/403
+	defaultWeatherApiKeyCooldown = time.Minute
+
+	defaultTempPrecision = 2
+	maxTempPrecision     = 6
+
+	// Validade das respostas de clima no Cache-Control
+	defaultCacheMaxAge = 300 * time.Second
+)
+
+// Lista separada por vírgulas, sem espaços em volta e sem itens vazios
+func parseList(v string) []string {
+	var keys []string
+	for _, k := range strings.Split(v, ",") {
+		if k = strings.TrimSpace(k); k != "" {
+			keys = append(keys, k)
+		}
+	}
+	return keys
+}
+
+// URL base opcional de uma API externa (proxy ou espelho); vazia mantém o padrão do cliente
+func envBaseURL(key string) (string, error) {
+	v := os.Getenv(key)
+	if v == "" {
+		return "", nil
+	}
+	if u, err := url.Parse(v); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", fmt.Errorf("%s must be an absolute http(s) URL, got %q", key, v)
+	}
+	return v, nil
+}
+
+// Durações no formato do time.ParseDuration ("500ms", "5s"...); valores inválidos, zero ou negativos caem no padrão
+func envDuration(logger *slog.Logger, key string, def time.Duration) time.Duration {
+	v := os.Getenv(key)
+	if v == "" {
+		return def
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d <= 0 {
+		logger.Warn("invalid env value, using default", "key", key, "value", v, "default", def.String())
+		return def
+	}
+	return d
+}
+
+// Aceita os formatos do strconv.ParseBool ("true", "1", "false"...)
+func envBool(logger *slog.Logger, key string, def bool) bool {
+	v := os.Getenv(key)
+	if v == "" {
+		return def
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		logger.Warn("invalid env value, using default", "key", key, "value", v, "default", def)
+		return def
+	}
+	return b
+}
+
+func envInt(logger *slog.Logger, key string, def int) int {
+	v := os.Getenv(key)
+	if v == "" {
+		return def
+	}
+	i, err := strconv.Atoi(v)
+	if err != nil {
+		logger.Warn("invalid env value, using default", "key", key, "value", v, "default", def)
+		return def
+	}
+	return i
+}
+
+func newHTTPServer(logger *slog.Logger, addr string, handler http.Handler) *http.Server {
+	return &http.Server{
+		Addr:              addr,
+		Handler:           handler,
+		ReadHeaderTimeout: envDuration(logger, "SERVER_READ_HEADER_TIMEOUT", defaultServerReadHeaderTimeout),
+		ReadTimeout:       envDuration(logger, "SERVER_READ_TIMEOUT", defaultServerReadTimeout),
+		WriteTimeout:      envDuration(logger, "SERVER_WRITE_TIMEOUT", defaultServerWriteTimeout),
+		IdleTimeout:       envDuration(logger, "SERVER_IDLE_TIMEOUT", defaultServerIdleTimeout),
+	}
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"l02-02/viacep"
+	"l02-02/weatherapi"
+
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+func TestEnvDuration(t *testing.T) {
+	logger := slog.New(slog.DiscardHandler)
+
+	tests := []struct {
+		name  string
+		value string
+		want  time.Duration
+	}{
+		{name: "unset", value: "", want: 5 * time.Second},
+		{name: "valid", value: "750ms", want: 750 * time.Millisecond},
+		{name: "minutes", value: "1m", want: time.Minute},
+		{name: "invalid", value: "five seconds", want: 5 * time.Second},
+		{name: "missing unit", value: "5", want: 5 * time.Second},
+		{name: "zero", value: "0s", want: 5 * time.Second},
+		{name: "negative", value: "-1s", want: 5 * time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("TEST_TIMEOUT", tt.value)
+			if got := envDuration(logger, "TEST_TIMEOUT", 5*time.Second); got != tt.want {
+				t.Errorf("expected %s, but got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestNewHTTPServer_Timeouts(t *testing.T) {
+	logger := slog.New(slog.DiscardHandler)
+
+	t.Run("defaults", func(t *testing.T) {
+		server := newHTTPServer(logger, ":0", http.NotFoundHandler())
+
+		if server.ReadHeaderTimeout != 5*time.Second || server.ReadTimeout != 10*time.Second ||
+			server.WriteTimeout != 15*time.Second || server.IdleTimeout != 60*time.Second {
+			t.Errorf("unexpected defaults: header=%s read=%s write=%s idle=%s",
+				server.ReadHeaderTimeout, server.ReadTimeout, server.WriteTimeout, server.IdleTimeout)
+		}
+	})
+
+	t.Run("from env", func(t *testing.T) {
+		t.Setenv("SERVER_READ_HEADER_TIMEOUT", "2s")
+		t.Setenv("SERVER_READ_TIMEOUT", "3s")
+		t.Setenv("SERVER_WRITE_TIMEOUT", "30s")
+		t.Setenv("SERVER_IDLE_TIMEOUT", "invalid")
+		server := newHTTPServer(logger, ":0", http.NotFoundHandler())
+
+		if server.ReadHeaderTimeout != 2*time.Second || server.ReadTimeout != 3*time.Second ||
+			server.WriteTimeout != 30*time.Second || server.IdleTimeout != 60*time.Second {
+			t.Errorf("unexpected timeouts: header=%s read=%s write=%s idle=%s",
+				server.ReadHeaderTimeout, server.ReadTimeout, server.WriteTimeout, server.IdleTimeout)
+		}
+	})
+}
+
+func TestParseList(t *testing.T) {
+	tests := []struct {
+		value string
+		want  []string
+	}{
+		{"", nil},
+		{"key-1", []string{"key-1"}},
+		{"key-1, key-2,,key-3 ", []string{"key-1", "key-2", "key-3"}},
+	}
+
+	for _, tt := range tests {
+		if got := parseList(tt.value); !slices.Equal(got, tt.want) {
+			t.Errorf("%q: expected %v, but got %v", tt.value, tt.want, got)
+		}
+	}
+}
+
+func TestEnvBaseURL(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		wantErr bool
+	}{
+		{"unset", "", false},
+		{"http", "http://mirror.local:8080/viacep", false},
+		{"https", "https://proxy.example.com", false},
+		{"relative", "/viacep", true},
+		{"without scheme", "mirror.local:8080", true},
+		{"unsupported scheme", "ftp://mirror.local", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("TEST_BASE_URL", tt.value)
+			got, err := envBaseURL("TEST_BASE_URL")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error: %v, but got: %v", tt.wantErr, err)
+			}
+			if !tt.wantErr && got != tt.value {
+				t.Errorf("expected '%s', but got '%s'", tt.value, got)
+			}
+		})
+	}
+}
+
+func TestEnvBaseURL_ClientOptions(t *testing.T) {
+	var gotViaCep, gotWeatherApi string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/viacep/") {
+			gotViaCep = r.URL.Path
+			w.Write([]byte(`{"localidade": "São Paulo", "uf": "SP"}`))
+			return
+		}
+		gotWeatherApi = r.URL.Path
+		w.Write([]byte(`{"current": {"temp_c": 25, "temp_f": 77}}`))
+	}))
+	defer server.Close()
+
+	t.Setenv("VIACEP_BASE_URL", server.URL+"/viacep/")
+	t.Setenv("WEATHERAPI_BASE_URL", server.URL+"/weatherapi")
+	viaCepBaseURL, _ := envBaseURL("VIACEP_BASE_URL")
+	weatherApiBaseURL, _ := envBaseURL("WEATHERAPI_BASE_URL")
+
+	logger := slog.New(slog.DiscardHandler)
+	tracer := noop.NewTracerProvider().Tracer("test")
+	if _, err := viacep.NewClient(logger, tracer, viacep.WithBaseURL(viaCepBaseURL)).FindAddressByCep(context.Background(), "01001000"); err != nil {
+		t.Fatalf("expected no error, but got: %v", err)
+	}
+	if _, err := weatherapi.NewClient("fake-api-key", logger, tracer, weatherapi.WithBaseURL(weatherApiBaseURL)).FindTemperatureByCity(context.Background(), "São Paulo"); err != nil {
+		t.Fatalf("expected no error, but got: %v", err)
+	}
+
+	if gotViaCep != "/viacep/ws/01001000/json/" {
+		t.Errorf("expected ViaCEP request at the configured base URL, but got '%s'", gotViaCep)
+	}
+	if gotWeatherApi != "/weatherapi/current.json" {
+		t.Errorf("expected WeatherAPI request at the configured base URL, but got '%s'", gotWeatherApi)
+	}
+}
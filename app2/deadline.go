@@ -0,0 +1,43 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Prazo absoluto (RFC 3339, UTC) enviado pelo app1, o mesmo cabeçalho que ele define
+const requestDeadlineHeader = "X-Request-Deadline"
+
+// Tempo máximo de uma requisição no app2, com ou sem prazo recebido
+const defaultRequestTimeout = 10 * time.Second
+
+// Limita o contexto da requisição (e das chamadas às APIs externas) ao menor prazo entre o
+// recebido no X-Request-Deadline e o máximo do app2; um cabeçalho inválido é ignorado
+func (app *application) requestDeadline(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		deadline := time.Now().Add(app.requestTimeout)
+		if v := r.Header.Get(requestDeadlineHeader); v != "" {
+			if forwarded, err := time.Parse(time.RFC3339Nano, v); err == nil && forwarded.Before(deadline) {
+				deadline = forwarded
+			}
+		}
+
+		ctx, cancel := context.WithDeadline(r.Context(), deadline)
+		defer cancel()
+		trace.SpanFromContext(ctx).SetAttributes(attribute.Int64("request.budget_ms", time.Until(deadline).Milliseconds()))
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// Equivalente do requestDeadline no gRPC, onde o prazo do cliente já chega pelo grpc-timeout
+func capDeadline(ctx context.Context, max time.Duration) (context.Context, context.CancelFunc) {
+	deadline := time.Now().Add(max)
+	if parent, ok := ctx.Deadline(); ok && parent.Before(deadline) {
+		deadline = parent
+	}
+	return context.WithDeadline(ctx, deadline)
+}
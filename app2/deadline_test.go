@@ -0,0 +1,141 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"l02-02/viacep"
+	"l02-02/weatherpb"
+
+	"go.opentelemetry.io/otel/trace/noop"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Provedor que só responde quando o prazo do contexto acaba, registrando quanto esperou
+type deadlineProvider struct {
+	waited chan time.Duration
+}
+
+func (p *deadlineProvider) FindAddressByCep(ctx context.Context, cep string) (*viacep.ViaCepResponse, error) {
+	start := time.Now()
+	select {
+	case <-ctx.Done():
+	case <-time.After(5 * time.Second):
+	}
+	p.waited <- time.Since(start)
+	return nil, viacep.ErrTimeout
+}
+
+func TestRequestDeadline_RespectsForwardedDeadline(t *testing.T) {
+	// ViaCEP lento: só responde depois de 5s ou quando a requisição é cancelada
+	upstreamDone := make(chan error, 1)
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+			upstreamDone <- r.Context().Err()
+		case <-time.After(5 * time.Second):
+			upstreamDone <- nil
+			w.Write([]byte(`{"cep": "01001-000", "localidade": "São Paulo"}`))
+		}
+	}))
+	defer upstream.Close()
+
+	// A mesma cadeia do main (singleflight, fallback e cliente com cache e novas tentativas)
+	logger := slog.New(slog.DiscardHandler)
+	viaCepClient := viacep.NewClient(logger, noop.NewTracerProvider().Tracer("test"),
+		viacep.WithBaseURL(upstream.URL),
+		viacep.WithCache(time.Hour, 10),
+		viacep.WithRetry(3, 10*time.Millisecond),
+	)
+	fallback := &mockViaCepClient{address: &viacep.ViaCepResponse{City: "São Paulo"}}
+	_, weather := healthyMocks()
+	server := httptest.NewServer(newTestApplication(newCepProvider(logger, viaCepClient, fallback), weather).routes())
+	defer server.Close()
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL+"/get-weather-by-cep?cep=01001000", nil)
+	req.Header.Set(requestDeadlineHeader, time.Now().Add(100*time.Millisecond).UTC().Format(time.RFC3339Nano))
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("expected no error, but got: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusGatewayTimeout {
+		t.Errorf("expected status 504, but got %d", resp.StatusCode)
+	}
+	select {
+	case err := <-upstreamDone:
+		if err == nil {
+			t.Error("expected the upstream request to be cancelled, but it completed")
+		}
+	case <-time.After(time.Second):
+		t.Error("expected the upstream request to be cancelled at the forwarded deadline")
+	}
+	if fallback.gotCep != "" {
+		t.Errorf("expected no fallback after the deadline, but it was queried for '%s'", fallback.gotCep)
+	}
+}
+
+func TestRequestDeadline(t *testing.T) {
+	tests := []struct {
+		name      string
+		header    string
+		max       time.Duration
+		remaining time.Duration
+	}{
+		{"forwarded deadline is smaller", time.Now().Add(2 * time.Second).UTC().Format(time.RFC3339Nano), 5 * time.Second, 2 * time.Second},
+		{"own max is smaller", time.Now().Add(time.Minute).UTC().Format(time.RFC3339Nano), 5 * time.Second, 5 * time.Second},
+		{"no header", "", 5 * time.Second, 5 * time.Second},
+		{"invalid header", "amanhã", 5 * time.Second, 5 * time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			viaCep, weather := healthyMocks()
+			app := newTestApplication(viaCep, weather)
+			app.requestTimeout = tt.max
+
+			var remaining time.Duration
+			handler := app.requestDeadline(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				deadline, ok := r.Context().Deadline()
+				if !ok {
+					t.Fatal("expected a deadline")
+				}
+				remaining = time.Until(deadline)
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, "/get-weather-by-cep?cep=01001000", nil)
+			if tt.header != "" {
+				req.Header.Set(requestDeadlineHeader, tt.header)
+			}
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+
+			if remaining > tt.remaining || remaining < tt.remaining-time.Second {
+				t.Errorf("expected remaining time close to %s, but got %s", tt.remaining, remaining)
+			}
+		})
+	}
+}
+
+func TestGRPC_CapsDeadline(t *testing.T) {
+	provider := &deadlineProvider{waited: make(chan time.Duration, 1)}
+	_, weather := healthyMocks()
+	app := newTestApplication(provider, weather)
+	app.requestTimeout = 100 * time.Millisecond
+	client := newTestGRPCClient(t, app)
+
+	// Sem prazo do cliente, vale o máximo do app2
+	_, err := client.GetWeatherByCep(context.Background(), &weatherpb.GetWeatherByCepRequest{Cep: "01001000"})
+	if got := status.Code(err); got != codes.DeadlineExceeded {
+		t.Errorf("expected code %s, but got %s", codes.DeadlineExceeded, got)
+	}
+	if waited := <-provider.waited; waited > time.Second {
+		t.Errorf("expected the lookup to stop at the app2 max, but it waited %s", waited)
+	}
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"l02-02/viacep"
+)
+
+// Códigos estáveis, para os clientes não dependerem do texto da mensagem
+const (
+	codeMissingCep       = "missing_cep"
+	codeInvalidZipcode   = "invalid_zipcode"
+	codeCepNotFound      = "cep_not_found"
+	codeUpstreamError    = "upstream_error"
+	codeInternalError    = "internal_error"
+	codeMethodNotAllowed = "method_not_allowed"
+	codeTimeout          = "timeout"
+	codeRateLimited      = "rate_limited"
+	codeOverloaded       = "overloaded"
+	codeUnauthorized     = "unauthorized"
+
+	codeInvalidForecastDays = "invalid_forecast_days"
+	codeInvalidDate         = "invalid_date"
+	// O ViaCEP encontrou o CEP, mas sem cidade (alguns CEPs especiais): não há clima a consultar
+	codeCepWithoutCity = "cep_without_city"
+)
+
+// Também é ErrCepNotFound, para as métricas e demais tratamentos de CEP não encontrado
+var errCepWithoutCity = fmt.Errorf("%w: empty city", viacep.ErrCepNotFound)
+
+type errorResponse struct {
+	Error string `json:"error"`
+	Code  string `json:"code"`
+}
+
+func writeJSONError(w http.ResponseWriter, status int, code, msg string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	// Erros nunca devem ser reaproveitados por clientes ou CDNs
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(errorResponse{Error: msg, Code: code})
+}
+
+// Endereço sem cidade vira errCepWithoutCity, em vez de seguir para a WeatherAPI com uma consulta vazia
+func checkAddressCity(address *viacep.ViaCepResponse, err error) error {
+	if err == nil && strings.TrimSpace(address.City) == "" {
+		return errCepWithoutCity
+	}
+	return err
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"l02-02/cep"
+	"l02-02/limiter"
+	"l02-02/viacep"
+	"l02-02/weatherapi"
+	"l02-02/weatherpb"
+
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const defaultGRPCPort = "50051"
+
+// Mesmos clientes e validações do handler HTTP, sem forecast, histórico e modo degradado
+type weatherGRPCServer struct {
+	weatherpb.UnimplementedWeatherServiceServer
+	app *application
+}
+
+// O StatsHandler do otelgrpc cria o span do servidor e extrai o contexto propagado pelo cliente
+func newGRPCServer(app *application) *grpc.Server {
+	server := grpc.NewServer(grpc.StatsHandler(otelgrpc.NewServerHandler()))
+	weatherpb.RegisterWeatherServiceServer(server, &weatherGRPCServer{app: app})
+	return server
+}
+
+func (s *weatherGRPCServer) GetWeatherByCep(ctx context.Context, req *weatherpb.GetWeatherByCepRequest) (*weatherpb.WeatherReply, error) {
+	app := s.app
+	ctx, cancel := capDeadline(ctx, app.requestTimeout)
+	defer cancel()
+	ctx, span := app.tracer.Start(ctx, "GetWeatherByCep")
+	defer span.End()
+
+	lang := grpcLanguage(ctx)
+	if req.GetCep() == "" {
+		return nil, status.Error(codes.InvalidArgument, msgMissingCep.in(lang))
+	}
+
+	zipcode, err := cep.NormalizeCEP(req.GetCep())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, msgInvalidZipcode.in(lang))
+	}
+	span.SetAttributes(attribute.String("cep.value", zipcode))
+
+	address, err := app.viaCepClient.FindAddressByCep(ctx, zipcode)
+	err = checkAddressCity(address, err)
+	if err != nil {
+		if errors.Is(err, errCepWithoutCity) {
+			span.AddEvent("cep without city")
+			return nil, status.Error(codes.NotFound, msgCepWithoutCity.in(lang))
+		}
+		if errors.Is(err, viacep.ErrCepNotFound) {
+			span.AddEvent("cep not found")
+			return nil, status.Error(codes.NotFound, msgCepNotFound.in(lang))
+		}
+		if errors.Is(err, viacep.ErrInvalidCep) {
+			span.AddEvent("cep rejected by provider")
+			return nil, status.Error(codes.InvalidArgument, msgInvalidZipcode.in(lang))
+		}
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, "address lookup failed")
+		app.logger.ErrorContext(ctx, "can not find CEP", "cep", zipcode, "error", err)
+		return nil, upstreamStatus(err, lang)
+	}
+
+	weather, err := app.findWeather(ctx, address, 0, time.Time{})
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, "temperature lookup failed")
+		app.logger.ErrorContext(ctx, "internal error while fetching temperature", "cep", zipcode, "city", address.City, "error", err)
+		return nil, upstreamStatus(err, lang)
+	}
+
+	reply := &weatherpb.WeatherReply{
+		City:      address.City,
+		State:     address.State,
+		Street:    address.Street,
+		Cep:       address.Cep,
+		TempC:     roundTemp(weather.Current.TempC, app.tempPrecision),
+		TempF:     roundTemp(weather.Current.TempF, app.tempPrecision),
+		TempK:     roundTemp(weather.Current.TempC+273.15, app.tempPrecision),
+		WindKph:   weather.Current.WindKph,
+		Condition: weather.Current.Condition.Text,
+		Region:    weather.Location.Region,
+		Country:   weather.Location.Country,
+	}
+	if h := weather.Current.Humidity; h != nil {
+		reply.Humidity = ptr(int32(*h))
+	}
+
+	return reply, nil
+}
+
+// Equivalente gRPC do writeUpstreamError: timeout vira DeadlineExceeded, limites (da WeatherAPI ou de concorrência) Unavailable, o resto Internal
+func upstreamStatus(err error, lang string) error {
+	if errors.Is(err, viacep.ErrTimeout) || errors.Is(err, weatherapi.ErrTimeout) {
+		return status.Error(codes.DeadlineExceeded, msgTimeout.in(lang))
+	}
+	if errors.Is(err, weatherapi.ErrRateLimited) {
+		return status.Error(codes.Unavailable, msgRateLimited.in(lang))
+	}
+	if errors.Is(err, limiter.ErrOverloaded) {
+		return status.Error(codes.Unavailable, msgOverloaded.in(lang))
+	}
+	return status.Error(codes.Internal, msgInternal.in(lang))
+}
+
+// Idioma das mensagens de erro pelo metadado accept-language, como o cabeçalho do HTTP
+func grpcLanguage(ctx context.Context) string {
+	md, _ := metadata.FromIncomingContext(ctx)
+	return negotiateLanguage(strings.Join(md.Get("accept-language"), ","))
+}
+
+// Adapta o *grpc.Server ao drainer: GracefulStop até o prazo do contexto e, depois dele, Stop
+type grpcDrainer struct {
+	server *grpc.Server
+}
+
+func (d grpcDrainer) Shutdown(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		d.server.GracefulStop()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		d.server.Stop()
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"l02-02/limiter"
+	"l02-02/viacep"
+	"l02-02/weatherapi"
+	"l02-02/weatherpb"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// Sobe o servidor em memória e devolve um cliente conectado a ele
+func newTestGRPCClient(t *testing.T, app *application) weatherpb.WeatherServiceClient {
+	t.Helper()
+
+	listener := bufconn.Listen(1 << 20)
+	server := newGRPCServer(app)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("expected no error, but got: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	return weatherpb.NewWeatherServiceClient(conn)
+}
+
+func TestGRPC_GetWeatherByCep(t *testing.T) {
+	viaCep, weather := healthyMocks()
+	viaCep.address.State = "SP"
+	viaCep.address.Cep = "01001-000"
+	weather.weather.Current.Humidity = ptr(60)
+	client := newTestGRPCClient(t, newTestApplication(viaCep, weather))
+
+	reply, err := client.GetWeatherByCep(context.Background(), &weatherpb.GetWeatherByCepRequest{Cep: "01001000"})
+	if err != nil {
+		t.Fatalf("expected no error, but got: %v", err)
+	}
+
+	if reply.GetCity() != "São Paulo" || reply.GetState() != "SP" || reply.GetCep() != "01001-000" {
+		t.Errorf("expected São Paulo/SP/01001-000, but got %s/%s/%s", reply.GetCity(), reply.GetState(), reply.GetCep())
+	}
+	if reply.GetTempC() != 25 || reply.GetTempF() != 77 || reply.GetTempK() != 298.15 {
+		t.Errorf("expected temperatures 25/77/298.15, but got %v/%v/%v", reply.GetTempC(), reply.GetTempF(), reply.GetTempK())
+	}
+	if reply.Humidity == nil || reply.GetHumidity() != 60 {
+		t.Errorf("expected humidity 60, but got %v", reply.Humidity)
+	}
+	if reply.WindKph != nil {
+		t.Errorf("expected no wind, but got %v", reply.GetWindKph())
+	}
+}
+
+func TestGRPC_GetWeatherByCep_Errors(t *testing.T) {
+	tests := []struct {
+		name       string
+		cep        string
+		viaCepErr  error
+		weatherErr error
+		code       codes.Code
+	}{
+		{"missing cep", "", nil, nil, codes.InvalidArgument},
+		{"invalid zipcode", "123", nil, nil, codes.InvalidArgument},
+		{"cep not found", "01001000", viacep.ErrCepNotFound, nil, codes.NotFound},
+		{"cep rejected by provider", "01001000", viacep.ErrInvalidCep, nil, codes.InvalidArgument},
+		{"address upstream failure", "01001000", viacep.ErrInternal, nil, codes.Internal},
+		{"weather upstream failure", "01001000", nil, weatherapi.ErrInternal, codes.Internal},
+		{"weather upstream timeout", "01001000", nil, weatherapi.ErrTimeout, codes.DeadlineExceeded},
+		{"weather rate limited", "01001000", nil, &weatherapi.RateLimitError{}, codes.Unavailable},
+		{"weather upstream overloaded", "01001000", nil, limiter.ErrOverloaded, codes.Unavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			viaCep, weather := healthyMocks()
+			viaCep.err = tt.viaCepErr
+			weather.err = tt.weatherErr
+			client := newTestGRPCClient(t, newTestApplication(viaCep, weather))
+
+			_, err := client.GetWeatherByCep(context.Background(), &weatherpb.GetWeatherByCepRequest{Cep: tt.cep})
+			if got := status.Code(err); got != tt.code {
+				t.Errorf("expected code %s, but got %s (%v)", tt.code, got, err)
+			}
+		})
+	}
+}
+
+func TestGRPC_GetWeatherByCep_CepWithoutCity(t *testing.T) {
+	viaCep, weather := healthyMocks()
+	viaCep.address = &viacep.ViaCepResponse{Cep: "01001-000", City: " "}
+	weather.err = weatherapi.ErrInternal
+	client := newTestGRPCClient(t, newTestApplication(viaCep, weather))
+
+	_, err := client.GetWeatherByCep(context.Background(), &weatherpb.GetWeatherByCepRequest{Cep: "01001000"})
+	if got := status.Code(err); got != codes.NotFound {
+		t.Errorf("expected code %s, but got %s (%v)", codes.NotFound, got, err)
+	}
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRoutes_GzipResponse(t *testing.T) {
+	viaCep, weather := healthyMocks()
+	app := newTestApplication(viaCep, weather)
+	app.gzipMinSize = 1
+	server := httptest.NewServer(app.routes())
+	defer server.Close()
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL+"/get-weather-by-cep?cep=01001000", nil)
+	// Definido à mão, o transporte não descomprime a resposta sozinho
+	req.Header.Set("Accept-Encoding", "gzip")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("expected no error, but got: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status 200, but got %d", resp.StatusCode)
+	}
+	if got := resp.Header.Get("Content-Encoding"); got != "gzip" {
+		t.Fatalf("expected Content-Encoding 'gzip', but got '%s'", got)
+	}
+
+	gz, err := gzip.NewReader(resp.Body)
+	if err != nil {
+		t.Fatalf("expected a gzip body, but got: %v", err)
+	}
+	var body response
+	if err := json.NewDecoder(gz).Decode(&body); err != nil {
+		t.Fatalf("expected JSON after decompressing, but got: %v", err)
+	}
+	if body.City != "São Paulo" || body.TempC == nil || *body.TempC != 25 {
+		t.Errorf("expected the weather for São Paulo, but got %+v", body)
+	}
+}
+
+func TestGzipResponses_SmallBody(t *testing.T) {
+	handler := gzipResponses(defaultGzipMinSize)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `{"city": "São Paulo"}`)
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/get-weather-by-cep", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if got := rec.Header().Get("Content-Encoding"); got != "" {
+		t.Errorf("expected no Content-Encoding, but got '%s'", got)
+	}
+	if !strings.Contains(rec.Body.String(), "São Paulo") {
+		t.Errorf("expected the body unchanged, but got %q", rec.Body.String())
+	}
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"l02-02/circuitbreaker"
+)
+
+type healthResponse struct {
+	Status       string                      `json:"status"`
+	Dependencies map[string]dependencyHealth `json:"dependencies,omitempty"`
+}
+
+type dependencyHealth struct {
+	Circuit             string  `json:"circuit"`
+	ConsecutiveFailures int     `json:"consecutive_failures"`
+	SuccessRate         float64 `json:"success_rate"`
+	Calls               int     `json:"calls"`
+	LastError           string  `json:"last_error,omitempty"`
+	// Ponteiro para omitir enquanto não houver falha registrada
+	LastErrorAt *time.Time `json:"last_error_at,omitempty"`
+}
+
+// Estado dos circuit breakers das dependências, lido dos contadores em memória: ao contrário do
+// /ready, não consulta as APIs externas. Responde sempre 200; com algum circuito fora de closed, o
+// status é "degraded"
+func (app *application) healthHandler(w http.ResponseWriter, r *http.Request) {
+	resp := healthResponse{Status: "ok", Dependencies: make(map[string]dependencyHealth, len(app.breakers))}
+	for name, breaker := range app.breakers {
+		if breaker == nil {
+			continue
+		}
+		snapshot := breaker.Snapshot()
+		resp.Dependencies[name] = newDependencyHealth(snapshot)
+		if snapshot.State != circuitbreaker.Closed {
+			resp.Status = "degraded"
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	json.NewEncoder(w).Encode(resp)
+}
+
+func newDependencyHealth(s circuitbreaker.Snapshot) dependencyHealth {
+	d := dependencyHealth{
+		Circuit:             s.State.String(),
+		ConsecutiveFailures: s.ConsecutiveFailures,
+		SuccessRate:         s.SuccessRate,
+		Calls:               s.Calls,
+		LastError:           s.LastError,
+	}
+	if !s.LastErrorAt.IsZero() {
+		at := s.LastErrorAt.UTC()
+		d.LastErrorAt = &at
+	}
+	return d
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"l02-02/circuitbreaker"
+	"l02-02/viacep"
+
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+func TestHealthHandler_TrippedBreaker(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer upstream.Close()
+
+	client := viacep.NewClient(slog.New(slog.DiscardHandler), noop.NewTracerProvider().Tracer("test"),
+		viacep.WithBaseURL(upstream.URL),
+		viacep.WithCircuitBreaker(1, time.Minute),
+	)
+	client.FindAddressByCep(context.Background(), "01001-000")
+
+	viaCep, weather := healthyMocks()
+	app := newTestApplication(viaCep, weather)
+	app.breakers = map[string]*circuitbreaker.Breaker{
+		"viacep":     client.Breaker(),
+		"weatherapi": circuitbreaker.New(5, time.Minute),
+	}
+
+	rec := httptest.NewRecorder()
+	app.routes().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/health", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, but got %d", rec.Code)
+	}
+
+	var body healthResponse
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatalf("expected a JSON body, but got: %v", err)
+	}
+	if body.Status != "degraded" {
+		t.Errorf("expected status 'degraded', but got '%s'", body.Status)
+	}
+
+	dep := body.Dependencies["viacep"]
+	if dep.Circuit != "open" || dep.ConsecutiveFailures != 1 || dep.SuccessRate != 0 {
+		t.Errorf("expected an open circuit with 1 failure, but got %+v", dep)
+	}
+	if dep.LastError == "" || dep.LastErrorAt == nil {
+		t.Errorf("expected the last error to be reported, but got %+v", dep)
+	}
+
+	if got := body.Dependencies["weatherapi"]; got.Circuit != "closed" || got.SuccessRate != 1 {
+		t.Errorf("expected a closed circuit without calls, but got %+v", got)
+	}
+}
+
+func TestHealthHandler_AllClosed(t *testing.T) {
+	viaCep, weather := healthyMocks()
+	app := newTestApplication(viaCep, weather)
+	app.breakers = map[string]*circuitbreaker.Breaker{"viacep": circuitbreaker.New(5, time.Minute)}
+
+	rec := httptest.NewRecorder()
+	app.healthHandler(rec, httptest.NewRequest(http.MethodGet, "/health", nil))
+
+	var body healthResponse
+	json.NewDecoder(rec.Body).Decode(&body)
+	if body.Status != "ok" {
+		t.Errorf("expected status 'ok', but got '%s'", body.Status)
+	}
+}
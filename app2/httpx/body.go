@@ -0,0 +1,60 @@
+package httpx
+
+import (
+	"compress/gzip"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// Teto padrão do corpo das respostas das APIs externas, já descomprimido
+const DefaultMaxResponseBytes = 1 << 20
+
+var ErrResponseTooLarge = errors.New("response body exceeds the maximum size")
+
+// Pede as respostas em gzip. Com o cabeçalho definido à mão o transporte deixa de descomprimir
+// sozinho, então o corpo deve ser lido com ResponseBody
+func AcceptGzip(req *http.Request) {
+	req.Header.Set("Accept-Encoding", "gzip")
+}
+
+// Corpo da resposta descomprimido quando veio em gzip e limitado a max bytes (max <= 0 usa o padrão);
+// além do limite a leitura falha com ErrResponseTooLarge. Fechar o resp.Body continua com o chamador
+func ResponseBody(resp *http.Response, max int64) (io.Reader, error) {
+	if max <= 0 {
+		max = DefaultMaxResponseBytes
+	}
+
+	var body io.Reader = resp.Body
+	if strings.EqualFold(strings.TrimSpace(resp.Header.Get("Content-Encoding")), "gzip") {
+		gz, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			return nil, err
+		}
+		body = gz
+	}
+
+	// Um byte além do limite basta para saber que o corpo é maior
+	return &maxBytesReader{r: io.LimitReader(body, max+1), remaining: max}, nil
+}
+
+type maxBytesReader struct {
+	r         io.Reader
+	remaining int64
+}
+
+// remaining < 0 marca que o limite já foi ultrapassado
+func (m *maxBytesReader) Read(p []byte) (int, error) {
+	if m.remaining < 0 {
+		return 0, ErrResponseTooLarge
+	}
+	n, err := m.r.Read(p)
+	if int64(n) > m.remaining {
+		n = int(m.remaining)
+		m.remaining = -1
+		return n, ErrResponseTooLarge
+	}
+	m.remaining -= int64(n)
+	return n, err
+}
@@ -0,0 +1,71 @@
+package httpx
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func gzipBytes(t *testing.T, s string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	gz.Write([]byte(s))
+	gz.Close()
+	return buf.Bytes()
+}
+
+func TestResponseBody(t *testing.T) {
+	tests := []struct {
+		name     string
+		encoding string
+		body     []byte
+		max      int64
+		expected string
+		err      error
+	}{
+		{"plain", "", []byte(`{"cep": "01001-000"}`), 0, `{"cep": "01001-000"}`, nil},
+		{"gzip", "gzip", gzipBytes(t, `{"cep": "01001-000"}`), 0, `{"cep": "01001-000"}`, nil},
+		{"exactly at the limit", "", []byte("12345"), 5, "12345", nil},
+		{"too large", "", []byte("123456"), 5, "12345", ErrResponseTooLarge},
+		// O limite vale para o corpo descomprimido, não para o que trafegou
+		{"gzip too large", "gzip", gzipBytes(t, strings.Repeat("a", 4096)), 1024, strings.Repeat("a", 1024), ErrResponseTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := &http.Response{Header: http.Header{}, Body: io.NopCloser(bytes.NewReader(tt.body))}
+			if tt.encoding != "" {
+				resp.Header.Set("Content-Encoding", tt.encoding)
+			}
+
+			body, err := ResponseBody(resp, tt.max)
+			if err != nil {
+				t.Fatalf("expected no error, but got: %v", err)
+			}
+			got, err := io.ReadAll(body)
+
+			if !errors.Is(err, tt.err) {
+				t.Errorf("expected error '%v', but got '%v'", tt.err, err)
+			}
+			if string(got) != tt.expected {
+				t.Errorf("expected body %q, but got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestResponseBody_InvalidGzip(t *testing.T) {
+	resp := &http.Response{
+		Header: http.Header{"Content-Encoding": []string{"gzip"}},
+		Body:   io.NopCloser(strings.NewReader(`{"cep": "01001-000"}`)),
+	}
+
+	if _, err := ResponseBody(resp, 0); err == nil {
+		t.Error("expected an error for a body that is not gzip")
+	}
+}
@@ -0,0 +1,181 @@
+package httpx
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	defaultTimeout = 10 * time.Second
+
+	// O http.DefaultTransport mantém só 2 conexões ociosas por host, o que gera churn sob carga
+	defaultMaxIdleConns        = 100
+	defaultMaxIdleConnsPerHost = 20
+	defaultIdleConnTimeout     = 90 * time.Second
+
+	redactedValue = "***"
+)
+
+// Pool de conexões do transporte; campos zerados mantêm os padrões
+type TransportConfig struct {
+	MaxIdleConns        int
+	MaxIdleConnsPerHost int
+	IdleConnTimeout     time.Duration
+}
+
+type config struct {
+	timeout        time.Duration
+	transport      TransportConfig
+	base           http.RoundTripper
+	redactedParams []string
+	middlewares    []func(http.RoundTripper) http.RoundTripper
+}
+
+type Option func(*config)
+
+// Timeout total de cada requisição (http.Client.Timeout)
+func WithTimeout(d time.Duration) Option {
+	return func(c *config) {
+		if d > 0 {
+			c.timeout = d
+		}
+	}
+}
+
+func WithTransportConfig(cfg TransportConfig) Option {
+	return func(c *config) {
+		if cfg.MaxIdleConns > 0 {
+			c.transport.MaxIdleConns = cfg.MaxIdleConns
+		}
+		if cfg.MaxIdleConnsPerHost > 0 {
+			c.transport.MaxIdleConnsPerHost = cfg.MaxIdleConnsPerHost
+		}
+		if cfg.IdleConnTimeout > 0 {
+			c.transport.IdleConnTimeout = cfg.IdleConnTimeout
+		}
+	}
+}
+
+// Substitui o transporte de base; as opções de pool deixam de ser aplicadas
+func WithBaseTransport(rt http.RoundTripper) Option {
+	return func(c *config) {
+		c.base = rt
+	}
+}
+
+// Parâmetros de query mascarados nos atributos de URL do span (ex.: chaves de API)
+func WithRedactedQueryParams(params ...string) Option {
+	return func(c *config) {
+		c.redactedParams = append(c.redactedParams, params...)
+	}
+}
+
+// Envolve o transporte de base, dentro do span do otelhttp; aplicados na ordem informada
+func WithMiddleware(mw func(http.RoundTripper) http.RoundTripper) Option {
+	return func(c *config) {
+		c.middlewares = append(c.middlewares, mw)
+	}
+}
+
+// http.Client com otelhttp, redação de URL e pool de conexões configurados em um só lugar
+func NewInstrumentedClient(opts ...Option) *http.Client {
+	c := &config{
+		timeout: defaultTimeout,
+		transport: TransportConfig{
+			MaxIdleConns:        defaultMaxIdleConns,
+			MaxIdleConnsPerHost: defaultMaxIdleConnsPerHost,
+			IdleConnTimeout:     defaultIdleConnTimeout,
+		},
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	transport := c.base
+	if transport == nil {
+		// Clone para não alterar o http.DefaultTransport compartilhado
+		t := http.DefaultTransport.(*http.Transport).Clone()
+		t.MaxIdleConns = c.transport.MaxIdleConns
+		t.MaxIdleConnsPerHost = c.transport.MaxIdleConnsPerHost
+		t.IdleConnTimeout = c.transport.IdleConnTimeout
+		transport = t
+	}
+
+	for _, mw := range c.middlewares {
+		transport = mw(transport)
+	}
+
+	if len(c.redactedParams) > 0 {
+		transport = &redactingTransport{base: transport, params: c.redactedParams}
+	}
+
+	return &http.Client{
+		Transport: otelhttp.NewTransport(transport),
+		Timeout:   c.timeout,
+	}
+}
+
+// Necessário para sobrescrever a URL registrada pelo otelhttp no span
+type redactingTransport struct {
+	base   http.RoundTripper
+	params []string
+}
+
+func (t *redactingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.URL != nil {
+		if sanitized, ok := RedactQuery(req.URL, t.params...); ok {
+			trace.SpanFromContext(req.Context()).SetAttributes(
+				semconv.HTTPURLKey.String(sanitized),
+				semconv.URLFullKey.String(sanitized),
+			)
+		}
+	}
+
+	return t.base.RoundTrip(req)
+}
+
+// URL com os parâmetros informados mascarados; ok é false quando nenhum deles está presente
+func RedactQuery(u *url.URL, params ...string) (string, bool) {
+	query := u.Query()
+	found := false
+	for _, p := range params {
+		if query.Get(p) != "" {
+			query.Set(p, redactedValue)
+			found = true
+		}
+	}
+	if !found {
+		return u.String(), false
+	}
+
+	sanitized := *u
+	sanitized.RawQuery = query.Encode()
+	return sanitized.String(), true
+}
+
+// Prazo do contexto ou timeout do cliente/conexão esgotado, em vez de uma falha de conexão
+func IsTimeout(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// Classe do status para rótulos de métricas ("2xx", "4xx", "5xx"...), ou "error" sem resposta
+func StatusClass(resp *http.Response, err error) string {
+	if err != nil || resp == nil {
+		return "error"
+	}
+	return strconv.Itoa(resp.StatusCode/100) + "xx"
+}
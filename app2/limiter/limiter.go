@@ -0,0 +1,78 @@
+package limiter
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var ErrOverloaded = errors.New("too many concurrent requests to the upstream")
+
+// Semáforo das requisições simultâneas a uma API externa, compartilhado por todas as chamadas do cliente
+type Limiter struct {
+	slots    chan struct{}
+	failFast bool
+}
+
+// Com failFast, Acquire devolve ErrOverloaded em vez de esperar uma vaga
+func New(max int, failFast bool) *Limiter {
+	return &Limiter{
+		slots:    make(chan struct{}, max),
+		failFast: failFast,
+	}
+}
+
+// Ocupa uma vaga até a chamada de release (que pode ser chamada mais de uma vez).
+// A espera por uma vaga vira um evento no span e respeita o prazo do contexto
+func (l *Limiter) Acquire(ctx context.Context, span trace.Span) (func(), error) {
+	select {
+	case l.slots <- struct{}{}:
+		return l.releaser(), nil
+	default:
+	}
+
+	if l.failFast {
+		span.AddEvent("concurrency_limit.rejected", trace.WithAttributes(attribute.Int("concurrency_limit.max", cap(l.slots))))
+		return nil, ErrOverloaded
+	}
+
+	start := time.Now()
+	select {
+	case l.slots <- struct{}{}:
+		span.AddEvent("concurrency_limit.wait", trace.WithAttributes(
+			attribute.Int("concurrency_limit.max", cap(l.slots)),
+			attribute.Int64("concurrency_limit.wait_ms", time.Since(start).Milliseconds()),
+		))
+		return l.releaser(), nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (l *Limiter) releaser() func() {
+	var once sync.Once
+	return func() {
+		once.Do(func() { <-l.slots })
+	}
+}
+
+// Devolve a vaga só quando o corpo da resposta for fechado, já que a conexão segue ocupada até lá
+func ReleaseOnClose(body io.ReadCloser, release func()) io.ReadCloser {
+	return &releasingBody{ReadCloser: body, release: release}
+}
+
+type releasingBody struct {
+	io.ReadCloser
+	release func()
+}
+
+func (b *releasingBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.release()
+	return err
+}
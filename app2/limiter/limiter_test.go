@@ -0,0 +1,100 @@
+package limiter
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+var span = noop.Span{}
+
+func TestLimiter_WaitsForFreeSlot(t *testing.T) {
+	l := New(1, false)
+
+	release, err := l.Acquire(context.Background(), span)
+	if err != nil {
+		t.Fatalf("expected no error, but got: %v", err)
+	}
+
+	acquired := make(chan struct{})
+	go func() {
+		if _, err := l.Acquire(context.Background(), span); err == nil {
+			close(acquired)
+		}
+	}()
+
+	select {
+	case <-acquired:
+		t.Fatal("expected the second call to wait while the slot is taken")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	release()
+	select {
+	case <-acquired:
+	case <-time.After(time.Second):
+		t.Error("expected the second call to get the slot after the release")
+	}
+}
+
+func TestLimiter_FailFast(t *testing.T) {
+	l := New(1, true)
+
+	if _, err := l.Acquire(context.Background(), span); err != nil {
+		t.Fatalf("expected no error, but got: %v", err)
+	}
+	if _, err := l.Acquire(context.Background(), span); err != ErrOverloaded {
+		t.Errorf("expected error '%v', but got '%v'", ErrOverloaded, err)
+	}
+}
+
+func TestLimiter_WaitRespectsContext(t *testing.T) {
+	l := New(1, false)
+	if _, err := l.Acquire(context.Background(), span); err != nil {
+		t.Fatalf("expected no error, but got: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := l.Acquire(ctx, span); err != context.DeadlineExceeded {
+		t.Errorf("expected error '%v', but got '%v'", context.DeadlineExceeded, err)
+	}
+}
+
+func TestLimiter_ReleaseIsIdempotent(t *testing.T) {
+	l := New(2, true)
+
+	release, _ := l.Acquire(context.Background(), span)
+	if _, err := l.Acquire(context.Background(), span); err != nil {
+		t.Fatalf("expected no error, but got: %v", err)
+	}
+
+	// Chamar release duas vezes não pode liberar a vaga de outra chamada
+	release()
+	release()
+	if _, err := l.Acquire(context.Background(), span); err != nil {
+		t.Fatalf("expected no error, but got: %v", err)
+	}
+	if _, err := l.Acquire(context.Background(), span); err != ErrOverloaded {
+		t.Errorf("expected error '%v', but got '%v'", ErrOverloaded, err)
+	}
+}
+
+func TestReleaseOnClose(t *testing.T) {
+	l := New(1, true)
+	release, _ := l.Acquire(context.Background(), span)
+
+	body := ReleaseOnClose(io.NopCloser(strings.NewReader("{}")), release)
+	if _, err := l.Acquire(context.Background(), span); err != ErrOverloaded {
+		t.Fatalf("expected the slot to stay taken until the body is closed, but got '%v'", err)
+	}
+
+	body.Close()
+	if _, err := l.Acquire(context.Background(), span); err != nil {
+		t.Errorf("expected the slot to be free after closing the body, but got: %v", err)
+	}
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Sugestão de espera devolvida no Retry-After das requisições rejeitadas
+const loadShedRetryAfter = time.Second
+
+// Acima de maxInFlight requisições em andamento, rejeita as novas com 503 na hora em vez de
+// enfileirá-las, preservando a latência das já aceitas (maxInFlight <= 0 desabilita)
+func (app *application) shedLoad(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if app.maxInFlight <= 0 {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if app.inFlight.Add(1) > int64(app.maxInFlight) {
+			app.inFlight.Add(-1)
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(loadShedRetryAfter.Seconds()))))
+			writeJSONError(w, http.StatusServiceUnavailable, codeOverloaded, localize(r, msgOverloaded))
+			return
+		}
+		defer app.inFlight.Add(-1)
+
+		next.ServeHTTP(w, r)
+	})
+}
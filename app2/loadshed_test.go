@@ -0,0 +1,77 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+func TestShedLoad_RejectsBeyondLimit(t *testing.T) {
+	viaCep, weather := healthyMocks()
+	app := newTestApplication(viaCep, weather)
+	app.maxInFlight = 2
+
+	started := make(chan struct{})
+	unblock := make(chan struct{})
+	handler := app.shedLoad(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		started <- struct{}{}
+		<-unblock
+	}))
+
+	var wg sync.WaitGroup
+	for range app.maxInFlight {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/get-weather-by-cep?cep=01001000", nil))
+		}()
+		<-started
+	}
+
+	for range 3 {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/get-weather-by-cep?cep=01001000", nil))
+		if rec.Code != http.StatusServiceUnavailable {
+			t.Errorf("expected status 503, but got %d", rec.Code)
+		}
+		if got := rec.Header().Get("Retry-After"); got != "1" {
+			t.Errorf("expected Retry-After '1', but got '%s'", got)
+		}
+		var body errorResponse
+		if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+			t.Fatalf("expected a JSON error body, but got: %v", err)
+		}
+		if body.Code != codeOverloaded {
+			t.Errorf("expected code '%s', but got '%s'", codeOverloaded, body.Code)
+		}
+	}
+
+	close(unblock)
+	wg.Wait()
+
+	// Com as vagas livres de novo, as requisições voltam a ser aceitas
+	go func() { <-started }()
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/get-weather-by-cep?cep=01001000", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("expected status 200 after the in-flight requests finished, but got %d", rec.Code)
+	}
+}
+
+func TestShedLoad_DisabledByDefault(t *testing.T) {
+	viaCep, weather := healthyMocks()
+	server := httptest.NewServer(newTestApplication(viaCep, weather).routes())
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/get-weather-by-cep?cep=01001000")
+	if err != nil {
+		t.Fatalf("expected no error, but got: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected status 200, but got %d", resp.StatusCode)
+	}
+}
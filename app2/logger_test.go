@@ -0,0 +1,118 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestNewLogger_JSONOutput(t *testing.T) {
+	var buf bytes.Buffer
+	logger := newLogger(&buf, slog.LevelInfo)
+
+	logger.Error("internal error while fetching temperature", "cep", "01001-000", "city", "São Paulo", "status", 500, "duration_ms", 12)
+
+	var entry map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("expected a JSON log line, but got %q: %v", buf.String(), err)
+	}
+
+	expected := map[string]any{
+		"level":       "ERROR",
+		"msg":         "internal error while fetching temperature",
+		"cep":         "01001-000",
+		"city":        "São Paulo",
+		"status":      float64(500),
+		"duration_ms": float64(12),
+	}
+	for key, want := range expected {
+		if entry[key] != want {
+			t.Errorf("expected %s=%v, but got %v", key, want, entry[key])
+		}
+	}
+}
+
+func TestNewLogger_ContextFields(t *testing.T) {
+	var buf bytes.Buffer
+	logger := newLogger(&buf, slog.LevelInfo)
+
+	sc := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{0x4b, 0xf9, 0x2f, 0x35, 0x77, 0xb3, 0x4d, 0xa6, 0xa3, 0xce, 0x92, 0x9d, 0x0e, 0x0e, 0x47, 0x36},
+		SpanID:     trace.SpanID{0x00, 0xf0, 0x67, 0xaa, 0x0b, 0xa9, 0x02, 0xb7},
+		TraceFlags: trace.FlagsSampled,
+	})
+	ctx := trace.ContextWithSpanContext(context.Background(), sc)
+	ctx = context.WithValue(ctx, requestIDKey, "req-123")
+
+	logger.With("cep", "01001-000").ErrorContext(ctx, "can not find CEP")
+
+	var entry map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("expected a JSON log line, but got %q: %v", buf.String(), err)
+	}
+
+	expected := map[string]any{
+		"trace_id":   "4bf92f3577b34da6a3ce929d0e0e4736",
+		"span_id":    "00f067aa0ba902b7",
+		"request_id": "req-123",
+		"cep":        "01001-000",
+	}
+	for key, want := range expected {
+		if entry[key] != want {
+			t.Errorf("expected %s=%v, but got %v", key, want, entry[key])
+		}
+	}
+}
+
+func TestNewLogger_NoContextFields(t *testing.T) {
+	var buf bytes.Buffer
+	logger := newLogger(&buf, slog.LevelInfo)
+
+	logger.Info("server listening")
+
+	var entry map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("expected a JSON log line, but got %q: %v", buf.String(), err)
+	}
+	for _, key := range []string{"trace_id", "span_id", "request_id"} {
+		if _, ok := entry[key]; ok {
+			t.Errorf("expected no %s without a context, but got %v", key, entry[key])
+		}
+	}
+}
+
+func TestNewLogger_RespectsLevel(t *testing.T) {
+	var buf bytes.Buffer
+	logger := newLogger(&buf, slog.LevelWarn)
+
+	logger.Info("should be dropped")
+	if buf.Len() != 0 {
+		t.Errorf("expected info to be filtered at warn level, but got %q", buf.String())
+	}
+}
+
+func TestParseLogLevel(t *testing.T) {
+	tests := []struct {
+		value  string
+		want   slog.Level
+		wantOk bool
+	}{
+		{value: "", want: slog.LevelInfo, wantOk: true},
+		{value: "debug", want: slog.LevelDebug, wantOk: true},
+		{value: "INFO", want: slog.LevelInfo, wantOk: true},
+		{value: "warn", want: slog.LevelWarn, wantOk: true},
+		{value: "error", want: slog.LevelError, wantOk: true},
+		{value: "verbose", want: slog.LevelInfo, wantOk: false},
+	}
+
+	for _, tt := range tests {
+		got, ok := parseLogLevel(tt.value)
+		if got != tt.want || ok != tt.wantOk {
+			t.Errorf("value %q: expected (%v, %v), but got (%v, %v)", tt.value, tt.want, tt.wantOk, got, ok)
+		}
+	}
+}
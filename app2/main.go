@@ -3,75 +3,231 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"encoding/xml"
 	"errors"
-	"log"
+	"log/slog"
+	"math"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
-	"regexp"
+	"sort"
+	"strconv"
+	"sync/atomic"
 	"syscall"
 	"time"
 
+	"l02-02/brasilapi"
+	"l02-02/cep"
+	"l02-02/circuitbreaker"
+	"l02-02/httpx"
+	"l02-02/limiter"
+	"l02-02/metrics"
 	"l02-02/telemetry"
+	"l02-02/version"
 	"l02-02/viacep"
 	"l02-02/weatherapi"
 
 	"github.com/joho/godotenv"
-	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
 	"go.opentelemetry.io/otel/trace"
 )
 
-const (
-	regextCepPattern     = `^[0-9]{5}-[0-9]{3}$`
-	InternalErrorMessage = "ocorreu um erro ao processar sua requisição"
-)
-
 type application struct {
-	viaCepClient     viacep.ViaCepClient
+	viaCepClient     viacep.CepProvider
 	weatherApiClient weatherapi.WeatherApiClient
-	logger           *log.Logger
+	logger           *slog.Logger
 	tracer           trace.Tracer
+	readyTimeout     time.Duration
+	metrics          *metrics.Metrics
+	// Com o clima indisponível, responde 206 só com o endereço
+	degradedMode bool
+	// Casas decimais das temperaturas na resposta
+	tempPrecision int
+	// max-age do Cache-Control das respostas de sucesso
+	cacheMaxAge time.Duration
+	// Limite de requisições em andamento do shedLoad (0 desabilita)
+	maxInFlight int
+	inFlight    atomic.Int64
+	// Teto do prazo de cada requisição, mesmo que o X-Request-Deadline recebido seja maior
+	requestTimeout time.Duration
+	// ETag nas respostas de sucesso, com 304 para o If-None-Match correspondente
+	etagEnabled bool
+	// Tamanho mínimo do corpo para a compressão gzip
+	gzipMinSize int
+	// Circuit breakers das dependências, expostos no /health
+	breakers map[string]*circuitbreaker.Breaker
+	// Verificações do /ready, direto nos clientes: o cache, o fallback e o circuit breaker da cadeia
+	// de consulta responderiam por uma dependência fora do ar
+	readyChecks map[string]func(context.Context) error
+	// Log de todos os cabeçalhos recebidos, além da linha de acesso
+	debugHeaders bool
+	// Segredo exigido pelas rotas /admin do listener administrativo (vazio desabilita)
+	adminToken string
+	// Caches esvaziados pelo /admin/cache/flush; cada função devolve quantas entradas removeu
+	caches map[string]func() int
+}
+
+type readyResponse struct {
+	Status string   `json:"status"`
+	Failed []string `json:"failed,omitempty"`
 }
 
 type response struct {
-	City  string  `json:"city"`
-	TempC float64 `json:"temp_C"`
-	TempF float64 `json:"temp_F"`
-	TempK float64 `json:"temp_K"`
+	XMLName xml.Name `json:"-" xml:"weather"`
+	City    string   `json:"city" xml:"city"`
+	State   string   `json:"state,omitempty" xml:"state,omitempty"`
+	Street  string   `json:"street,omitempty" xml:"street,omitempty"`
+	Cep     string   `json:"cep,omitempty" xml:"cep,omitempty"`
+	// Ausentes apenas no modo degradado, quando o clima não pôde ser obtido
+	TempC *float64 `json:"temp_C,omitempty" xml:"temp_C,omitempty"`
+	TempF *float64 `json:"temp_F,omitempty" xml:"temp_F,omitempty"`
+	TempK *float64 `json:"temp_K,omitempty" xml:"temp_K,omitempty"`
+	// Omitidos quando a WeatherAPI não os informa
+	Humidity  *int     `json:"humidity,omitempty" xml:"humidity,omitempty"`
+	WindKph   *float64 `json:"wind_kph,omitempty" xml:"wind_kph,omitempty"`
+	Condition string   `json:"condition,omitempty" xml:"condition,omitempty"`
+	// Desambiguam cidades homônimas em estados diferentes
+	Region  string `json:"region,omitempty" xml:"region,omitempty"`
+	Country string `json:"country,omitempty" xml:"country,omitempty"`
+	// Com WEATHERAPI_AQI_ENABLED no app2
+	AirQuality *airQuality `json:"air_quality,omitempty" xml:"air_quality,omitempty"`
+	// Com ?forecast_days=N
+	Forecast []forecastDay `json:"forecast,omitempty" xml:"forecast>day,omitempty"`
+	// Com ?date=AAAA-MM-DD; as temperaturas acima passam a ser as médias do dia
+	History *forecastDay `json:"history,omitempty" xml:"history,omitempty"`
+	Partial bool         `json:"partial,omitempty" xml:"partial,omitempty"`
 }
 
-var cepRegex = regexp.MustCompile(regextCepPattern)
+type forecastDay struct {
+	Date         string  `json:"date" xml:"date"`
+	MaxTempC     float64 `json:"max_temp_C" xml:"max_temp_C"`
+	MinTempC     float64 `json:"min_temp_C" xml:"min_temp_C"`
+	MaxTempF     float64 `json:"max_temp_F" xml:"max_temp_F"`
+	MinTempF     float64 `json:"min_temp_F" xml:"min_temp_F"`
+	ChanceOfRain int     `json:"chance_of_rain" xml:"chance_of_rain"`
+	Condition    string  `json:"condition,omitempty" xml:"condition,omitempty"`
+}
+
+// Resumo da qualidade do ar; us_epa_index vai de 1 (boa) a 6 (perigosa)
+type airQuality struct {
+	PM25       float64 `json:"pm2_5" xml:"pm2_5"`
+	PM10       float64 `json:"pm10" xml:"pm10"`
+	USEPAIndex int     `json:"us_epa_index" xml:"us_epa_index"`
+}
 
 func main() {
-	logger := log.New(os.Stderr, "INFO: ", log.Ldate|log.Ltime|log.Lshortfile)
 	godotenv.Load()
 
-	tracer, shutdown, err := telemetry.InitTelemetry("app2-service", "app2-tracer")
+	level, ok := parseLogLevel(os.Getenv("LOG_LEVEL"))
+	logger := newLogger(os.Stderr, level)
+	// Redireciona também o pacote log (usado pela telemetria) para o mesmo handler
+	slog.SetDefault(logger)
+	if !ok {
+		logger.Warn("invalid LOG_LEVEL, using info", "value", os.Getenv("LOG_LEVEL"))
+	}
+
+	tracer, meter, shutdown, err := telemetry.InitTelemetry("app2-service", "app2-tracer", "app2-meter")
 	if err != nil {
-		log.Fatalf("INFO: failed to initialize telemetry: %v", err)
-	}
-	defer func() {
-		logger.Println("INFO: shutting down telemetry...")
-		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-		defer cancel()
-		if err := shutdown(ctx); err != nil {
-			logger.Printf("ERROR: fail to shutdown telemetry gracefully: %v", err)
-		}
-		logger.Println("INFO: telemetry gone.")
-	}()
+		logger.Error("failed to initialize telemetry", "error", err)
+		os.Exit(1)
+	}
 
 	// API Weather (need env)
-	weatherAPIKey := os.Getenv("WEATHER_API_KEY")
-	if weatherAPIKey == "" {
-		log.Fatal("ERROR: The env variable WEATHER_API_KEY is required.")
-		return
+	// WEATHER_API_KEYS (separadas por vírgula) tem precedência sobre WEATHER_API_KEY
+	weatherAPIKeys := parseList(os.Getenv("WEATHER_API_KEYS"))
+	if len(weatherAPIKeys) == 0 {
+		weatherAPIKeys = parseList(os.Getenv("WEATHER_API_KEY"))
+	}
+	if len(weatherAPIKeys) == 0 {
+		logger.Error("the env variable WEATHER_API_KEY or WEATHER_API_KEYS is required")
+		os.Exit(1)
 	}
 
-	app := &application{
-		viaCepClient:     viacep.NewClient(logger, tracer),
-		weatherApiClient: weatherapi.NewClient(weatherAPIKey, logger, tracer),
-		logger:           logger,
-		tracer:           tracer,
+	viaCepBaseURL, viaCepErr := envBaseURL("VIACEP_BASE_URL")
+	weatherApiBaseURL, weatherApiErr := envBaseURL("WEATHERAPI_BASE_URL")
+	if err := errors.Join(viaCepErr, weatherApiErr); err != nil {
+		logger.Error("invalid configuration", "error", err)
+		os.Exit(1)
+	}
+
+	appMetrics := metrics.New(metrics.WithMeter(meter))
+	upstreamFailFast := envBool(logger, "UPSTREAM_CONCURRENCY_FAIL_FAST", false)
+	upstreamMaxBody := int64(envInt(logger, "UPSTREAM_MAX_RESPONSE_BYTES", httpx.DefaultMaxResponseBytes))
+
+	viaCepClient := viacep.NewClient(logger, tracer,
+		viacep.WithCache(24*time.Hour, 1000),
+		viacep.WithNegativeCache(5*time.Minute, 1000),
+		viacep.WithRetry(3, 100*time.Millisecond),
+		viacep.WithCircuitBreaker(5, 30*time.Second),
+		viacep.WithMetrics(appMetrics),
+		viacep.WithTimeout(envDuration(logger, "VIACEP_TIMEOUT", defaultViaCepTimeout)),
+		viacep.WithBaseURL(viaCepBaseURL),
+		viacep.WithConcurrencyLimit(envInt(logger, "VIACEP_MAX_CONCURRENCY", defaultUpstreamMaxConcurrency), upstreamFailFast),
+		viacep.WithMaxResponseBytes(upstreamMaxBody),
+	)
+
+	weatherApiClient := weatherapi.NewClient(weatherAPIKeys[0], logger, tracer,
+		weatherapi.WithAPIKeys(weatherAPIKeys, envDuration(logger, "WEATHER_API_KEY_COOLDOWN", defaultWeatherApiKeyCooldown)),
+		weatherapi.WithRetry(3, 100*time.Millisecond),
+		weatherapi.WithCircuitBreaker(5, 30*time.Second),
+		weatherapi.WithMetrics(appMetrics),
+		weatherapi.WithTimeout(envDuration(logger, "WEATHERAPI_TIMEOUT", defaultWeatherApiTimeout)),
+		weatherapi.WithBaseURL(weatherApiBaseURL),
+		weatherapi.WithConcurrencyLimit(envInt(logger, "WEATHERAPI_MAX_CONCURRENCY", defaultUpstreamMaxConcurrency), upstreamFailFast),
+		weatherapi.WithMaxResponseBytes(upstreamMaxBody),
+		weatherapi.WithAQI(envBool(logger, "WEATHERAPI_AQI_ENABLED", false)),
+		weatherapi.WithSanityCheck(envBool(logger, "WEATHERAPI_SANITY_CHECK_ENABLED", false)),
+	)
+
+	// Opcional para não bloquear o desenvolvimento offline: uma chave recusada impede a subida,
+	// em vez de aparecer como 500 na primeira requisição
+	if envBool(logger, "WEATHER_API_KEY_VALIDATION", false) {
+		ctx, cancel := context.WithTimeout(context.Background(), defaultWeatherApiTimeout)
+		err := weatherApiClient.ValidateKeys(ctx)
+		cancel()
+		if errors.Is(err, weatherapi.ErrInvalidAPIKey) {
+			logger.Error("WeatherAPI rejected the API key", "error", err)
+			os.Exit(1)
+		}
+		if err != nil {
+			logger.Warn("could not validate the WeatherAPI key", "error", err)
+		}
+	}
+
+	app := newApplication(
+		newCepProvider(logger, viaCepClient, brasilapi.NewClient(logger, tracer)),
+		weatherapi.NewSingleflightClient(weatherApiClient),
+		logger,
+		tracer,
+	)
+	app.metrics = appMetrics
+	app.breakers = map[string]*circuitbreaker.Breaker{
+		"viacep":     viaCepClient.Breaker(),
+		"weatherapi": weatherApiClient.Breaker(),
+	}
+	app.readyChecks = map[string]func(context.Context) error{
+		"viacep":     viaCepClient.Ping,
+		"weatherapi": weatherApiClient.Ping,
+	}
+	app.caches = map[string]func() int{
+		"viacep":           viaCepClient.FlushCache,
+		"viacep_not_found": viaCepClient.FlushNegativeCache,
+	}
+	app.adminToken = os.Getenv("ADMIN_TOKEN")
+	app.readyTimeout = envDuration(logger, "READY_CHECK_TIMEOUT", defaultReadyTimeout)
+	app.degradedMode = envBool(logger, "DEGRADED_MODE_ENABLED", false)
+	app.cacheMaxAge = envDuration(logger, "CACHE_MAX_AGE", defaultCacheMaxAge)
+	app.maxInFlight = envInt(logger, "MAX_IN_FLIGHT_REQUESTS", 0)
+	app.requestTimeout = envDuration(logger, "REQUEST_TIMEOUT", defaultRequestTimeout)
+	app.etagEnabled = envBool(logger, "ETAG_ENABLED", false)
+	app.gzipMinSize = envInt(logger, "GZIP_MIN_SIZE", defaultGzipMinSize)
+	app.debugHeaders = envBool(logger, "DEBUG_LOG_HEADERS", false)
+	app.tempPrecision = envInt(logger, "TEMP_PRECISION", defaultTempPrecision)
+	if app.tempPrecision < 0 || app.tempPrecision > maxTempPrecision {
+		logger.Warn("TEMP_PRECISION out of range, using default", "value", app.tempPrecision, "max", maxTempPrecision)
+		app.tempPrecision = defaultTempPrecision
 	}
 
 	port := os.Getenv("PORT")
@@ -79,13 +235,36 @@ func main() {
 		port = "8080"
 	}
 
-	otelHandler := otelhttp.NewHandler(http.HandlerFunc(app.handler), "/app2-server")
-	mux := http.NewServeMux()
-	mux.Handle("/get-weather-by-cep", app.logRequest(otelHandler))
+	server := newHTTPServer(logger, ":"+port, app.routes())
 
-	server := &http.Server{
-		Addr:    ":" + port,
-		Handler: mux,
+	// Perfis do net/http/pprof e rotas /admin em um listener separado, ambos desligados por padrão.
+	// PPROF_ADDR continua valendo quando ADMIN_ADDR não é definido
+	pprofEnabled := envBool(logger, "ENABLE_PPROF", false)
+	if pprofEnabled || app.adminToken != "" {
+		adminAddr := os.Getenv("ADMIN_ADDR")
+		if adminAddr == "" {
+			adminAddr = os.Getenv("PPROF_ADDR")
+		}
+		if adminAddr == "" {
+			adminAddr = defaultAdminAddr
+		}
+		startAdminServer(logger, adminAddr, app.adminHandler(pprofEnabled))
+	}
+
+	grpcPort := os.Getenv("GRPC_PORT")
+	if grpcPort == "" {
+		grpcPort = defaultGRPCPort
+	}
+	grpcListener, err := net.Listen("tcp", ":"+grpcPort)
+	if err != nil {
+		logger.Error("can not listen for gRPC", "port", grpcPort, "error", err)
+		os.Exit(1)
+	}
+	grpcServer := newGRPCServer(app)
+
+	// Aquece o cache de endereços em segundo plano, sem atrasar a subida do servidor
+	if ceps := parseList(os.Getenv("PREFETCH_CEPS")); len(ceps) > 0 {
+		go app.prefetch(context.Background(), ceps, envInt(logger, "PREFETCH_CONCURRENCY", defaultPrefetchConcurrency))
 	}
 
 	// (Ctrl+C)
@@ -93,23 +272,72 @@ func main() {
 	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
 
 	go func() {
-		app.logger.Printf("Server listernig port %s", port)
+		app.logger.Info("server listening", "port", port)
 		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
-			log.Fatalf("Can not start server: %v", err)
+			app.logger.Error("can not start server", "error", err)
+			os.Exit(1)
+		}
+	}()
+
+	go func() {
+		app.logger.Info("gRPC server listening", "port", grpcPort)
+		if err := grpcServer.Serve(grpcListener); err != nil {
+			app.logger.Error("can not start gRPC server", "error", err)
+			os.Exit(1)
 		}
 	}()
 
 	<-stop
 
-	logger.Println("INFO: shutting down server...")
-	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
+	if err := gracefulShutdown(logger, drainers{server, grpcDrainer{grpcServer}}, shutdown,
+		envDuration(logger, "SHUTDOWN_TIMEOUT", defaultShutdownTimeout),
+		envDuration(logger, "TELEMETRY_SHUTDOWN_TIMEOUT", defaultTelemetryShutdownTimeout),
+	); err != nil {
+		os.Exit(1)
+	}
+}
+
+// BrasilAPI (fallback) quando o ViaCEP (primary) estiver indisponível; o singleflight fica por fora
+// para que consultas simultâneas do mesmo CEP passem uma única vez pela cadeia toda
+func newCepProvider(logger *slog.Logger, primary, fallback viacep.CepProvider) viacep.CepProvider {
+	return viacep.NewSingleflightProvider(viacep.NewFallbackProvider(logger, primary, fallback))
+}
 
-	if err := server.Shutdown(shutdownCtx); err != nil {
-		log.Fatalf("Fail shutindown server gracefully: %v", err)
+// Dependências obrigatórias; os demais campos ficam com os padrões e podem ser ajustados depois
+func newApplication(viaCep viacep.CepProvider, weather weatherapi.WeatherApiClient, logger *slog.Logger, tracer trace.Tracer) *application {
+	return &application{
+		viaCepClient:     viaCep,
+		weatherApiClient: weather,
+		logger:           logger,
+		tracer:           tracer,
+		readyTimeout:     defaultReadyTimeout,
+		metrics:          metrics.New(),
+		tempPrecision:    defaultTempPrecision,
+		cacheMaxAge:      defaultCacheMaxAge,
+		requestTimeout:   defaultRequestTimeout,
+		gzipMinSize:      defaultGzipMinSize,
 	}
+}
 
-	log.Println("INFO: server gone.")
+func (app *application) routes() http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("/get-weather-by-cep", chain(http.HandlerFunc(app.handler),
+		app.instrumentRoute("/get-weather-by-cep"),
+		app.requestID,
+		methods(http.MethodGet),
+		app.logRequest,
+		gzipResponses(app.gzipMinSize),
+		app.shedLoad,
+		otelServer("/app2-server"),
+		app.requestDeadline,
+		traceID,
+	))
+	mux.HandleFunc("/health", app.healthHandler)
+	mux.HandleFunc("/ready", app.readyHandler)
+	mux.HandleFunc("/version", versionHandler)
+	mux.Handle("/metrics", app.metrics.Handler())
+
+	return app.recoverPanics(mux)
 }
 
 func (app *application) logRequest(next http.Handler) http.Handler {
@@ -119,17 +347,31 @@ func (app *application) logRequest(next http.Handler) http.Handler {
 			ip = r.RemoteAddr
 		}
 
-		// DEBUG: Imprime todos os cabeçalhos recebidos
-		app.logger.Println("--- Headers Recebidos ---")
-		for name, values := range r.Header {
-			for _, value := range values {
-				app.logger.Printf("Header: %s: %s", name, value)
-			}
+		// DEBUG: Imprime todos os cabeçalhos recebidos (só com DEBUG_LOG_HEADERS, pelo volume de log)
+		if app.debugHeaders {
+			app.logger.InfoContext(r.Context(), "headers received", "headers", redactHeaders(r.Header))
+		}
+
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+
+		// Log de acesso: uma linha por requisição, depois da resposta
+		args := []any{
+			"ip", ip,
+			"method", r.Method,
+			"path", r.URL.Path,
+			"url", redactURL(r.URL.RequestURI()),
+			"user_agent", r.UserAgent(),
+			"status", rec.status,
+			"duration_ms", time.Since(start).Milliseconds(),
+			"bytes", rec.bytes,
 		}
-		app.logger.Println("-------------------------")
-		// Algo como log de acesso
-		app.logger.Printf("Request: IP=%s Method=%s URL=%s User-Agent=\"%s\"", ip, r.Method, r.URL.RequestURI(), r.UserAgent())
-		next.ServeHTTP(w, r)
+		// CEP normalizado pelo app1, propagado no baggage
+		if cep := cepFromBaggage(r); cep != "" {
+			args = append(args, "baggage_cep", cep)
+		}
+		app.logger.InfoContext(r.Context(), "request", args...)
 	})
 }
 
@@ -137,45 +379,305 @@ func (app *application) handler(w http.ResponseWriter, r *http.Request) {
 	ctx, span := app.tracer.Start(r.Context(), "/get-weather-by-cep")
 	defer span.End()
 
-	cep := r.URL.Query().Get("cep")
-	if cep == "" {
-		http.Error(w, "parâmetro 'cep' é obrigatório", http.StatusBadRequest)
+	rawCep := r.URL.Query().Get("cep")
+	if rawCep == "" {
+		writeJSONError(w, http.StatusBadRequest, codeMissingCep, localize(r, msgMissingCep))
+		return
+	}
+
+	zipcode, err := cep.NormalizeCEP(rawCep)
+	if err != nil {
+		app.metrics.ObserveCepLookup(metrics.CepInvalid)
+		writeJSONError(w, http.StatusUnprocessableEntity, codeInvalidZipcode, localize(r, msgInvalidZipcode))
+		return
+	}
+
+	days, ok := parseForecastDays(r.URL.Query().Get("forecast_days"))
+	if !ok {
+		writeJSONError(w, http.StatusBadRequest, codeInvalidForecastDays, localize(r, msgInvalidForecastDays, weatherapi.MaxForecastDays))
 		return
 	}
 
-	if !cepRegex.MatchString(cep) {
-		http.Error(w, "inválid zipcode", http.StatusUnprocessableEntity)
+	date, ok := parseHistoryDate(r.URL.Query().Get("date"), time.Now())
+	if !ok {
+		writeJSONError(w, http.StatusBadRequest, codeInvalidDate, localize(r, msgInvalidDate, weatherapi.MinHistoryDate.Format(weatherapi.DateLayout)))
+		return
+	}
+	if !date.IsZero() && days > 0 {
+		writeJSONError(w, http.StatusBadRequest, codeInvalidDate, localize(r, msgDateWithForecast))
 		return
 	}
 
 	// 1.
-	address, err := app.viaCepClient.FindAddressByCep(ctx, cep)
+	address, err := app.viaCepClient.FindAddressByCep(ctx, zipcode)
+	err = checkAddressCity(address, err)
+	app.metrics.ObserveCepLookup(cepOutcome(err))
 	if err != nil {
-		if err == viacep.ErrCepNotFound {
-			http.Error(w, viacep.ErrCepNotFound.Error(), http.StatusNotFound)
+		if err == errCepWithoutCity {
+			span.AddEvent("cep without city")
+			writeJSONError(w, http.StatusNotFound, codeCepWithoutCity, localize(r, msgCepWithoutCity))
+		} else if err == viacep.ErrCepNotFound {
+			// Condição tratada: o status do span fica como não definido
+			span.AddEvent("cep not found")
+			writeJSONError(w, http.StatusNotFound, codeCepNotFound, localize(r, msgCepNotFound))
+		} else if err == viacep.ErrInvalidCep {
+			span.AddEvent("cep rejected by provider")
+			writeJSONError(w, http.StatusUnprocessableEntity, codeInvalidZipcode, localize(r, msgInvalidZipcode))
 		} else {
-			app.logger.Printf("Error can not find CEP: %v", err)
-			http.Error(w, InternalErrorMessage, http.StatusInternalServerError)
+			span.RecordError(err)
+			span.SetStatus(codes.Error, "address lookup failed")
+			app.logger.ErrorContext(ctx, "can not find CEP", "cep", zipcode, "error", err)
+			writeUpstreamError(w, r, err)
 		}
 		return
 	}
 
 	// 2.
-	weather, err := app.weatherApiClient.FindTemperatureByCity(ctx, address.City)
+	weather, err := app.findWeather(ctx, address, days, date)
+	if err != nil && app.degradedMode {
+		// Endereço sem as temperaturas, em vez de um 500
+		span.RecordError(err)
+		span.AddEvent("degraded.weather_unavailable", trace.WithAttributes(attribute.String("city", address.City)))
+		app.logger.WarnContext(ctx, "weather unavailable, returning partial response", "cep", zipcode, "city", address.City, "error", err)
+		// Sem cache, para que a próxima consulta já traga o clima
+		w.Header().Set("Cache-Control", "no-store")
+		writeResponse(w, r, http.StatusPartialContent, response{
+			City:    address.City,
+			State:   address.State,
+			Street:  address.Street,
+			Cep:     address.Cep,
+			Partial: true,
+		})
+		return
+	}
 	if err != nil {
-		app.logger.Printf("Internal error while fetching temperature for the city %s: %v", address.City, err)
-		http.Error(w, InternalErrorMessage, http.StatusInternalServerError)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "temperature lookup failed")
+		app.logger.ErrorContext(ctx, "internal error while fetching temperature", "cep", zipcode, "city", address.City, "error", err)
+		writeUpstreamError(w, r, err)
 		return
 	}
 
 	// 3.
 	response := response{
-		City:  address.City,
-		TempC: weather.Current.TempC,
-		TempF: weather.Current.TempF,
-		TempK: weather.Current.TempC + 273.15, // Kelvin
+		City:   address.City,
+		State:  address.State,
+		Street: address.Street,
+		Cep:    address.Cep,
+		TempC:  ptr(roundTemp(weather.Current.TempC, app.tempPrecision)),
+		TempF:  ptr(roundTemp(weather.Current.TempF, app.tempPrecision)),
+		TempK:  ptr(roundTemp(weather.Current.TempC+273.15, app.tempPrecision)), // Kelvin
+
+		Humidity:  weather.Current.Humidity,
+		WindKph:   weather.Current.WindKph,
+		Condition: weather.Current.Condition.Text,
+		Region:    weather.Location.Region,
+		Country:   weather.Location.Country,
+
+		AirQuality: newAirQuality(weather.Current.AirQuality),
+		Forecast:   app.newForecast(weather.Forecast),
+	}
+
+	if !date.IsZero() {
+		// O history.json não tem o bloco current: as temperaturas são as médias do dia
+		if weather.Forecast == nil || len(weather.Forecast.ForecastDay) == 0 {
+			span.SetStatus(codes.Error, "empty history response")
+			app.logger.ErrorContext(ctx, "WeatherAPI history without days", "cep", zipcode, "date", date.Format(weatherapi.DateLayout))
+			writeJSONError(w, http.StatusInternalServerError, codeUpstreamError, localize(r, msgInternal))
+			return
+		}
+		day := weather.Forecast.ForecastDay[0].Day
+		response.TempC = ptr(roundTemp(day.AvgTempC, app.tempPrecision))
+		response.TempF = ptr(roundTemp(day.AvgTempF, app.tempPrecision))
+		response.TempK = ptr(roundTemp(day.AvgTempC+273.15, app.tempPrecision))
+		response.Condition = day.Condition.Text
+		response.History, response.Forecast = &response.Forecast[0], nil
+	}
+
+	// Valores já arredondados, os mesmos da resposta; permitem achar leituras anômalas nos traces
+	span.SetAttributes(
+		attribute.Float64("weather.temp_c", *response.TempC),
+		attribute.Float64("weather.temp_f", *response.TempF),
+		attribute.Float64("weather.temp_k", *response.TempK),
+	)
+
+	setCacheControl(w, app.cacheMaxAge)
+	if app.etagEnabled {
+		writeResponseWithETag(w, r, zipcode, response)
+		return
+	}
+	writeResponse(w, r, http.StatusOK, response)
+}
+
+// Timeout das APIs externas vira 504, para não ser confundido com uma falha do serviço;
+// o limite da WeatherAPI vira 503 com o Retry-After recebido, para o cliente esperar antes de tentar de novo;
+// o limite de requisições simultâneas também vira 503
+func writeUpstreamError(w http.ResponseWriter, r *http.Request, err error) {
+	if errors.Is(err, viacep.ErrTimeout) || errors.Is(err, weatherapi.ErrTimeout) {
+		writeJSONError(w, http.StatusGatewayTimeout, codeTimeout, localize(r, msgTimeout))
+		return
+	}
+	if errors.Is(err, limiter.ErrOverloaded) {
+		writeJSONError(w, http.StatusServiceUnavailable, codeOverloaded, localize(r, msgOverloaded))
+		return
+	}
+	var rateLimited *weatherapi.RateLimitError
+	if errors.As(err, &rateLimited) {
+		if rateLimited.RetryAfter > 0 {
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(rateLimited.RetryAfter.Seconds()))))
+		}
+		writeJSONError(w, http.StatusServiceUnavailable, codeRateLimited, localize(r, msgRateLimited))
+		return
+	}
+	writeJSONError(w, http.StatusInternalServerError, codeUpstreamError, localize(r, msgInternal))
+}
+
+func newAirQuality(aq *weatherapi.AirQuality) *airQuality {
+	if aq == nil {
+		return nil
+	}
+	return &airQuality{
+		PM25:       aq.PM25,
+		PM10:       aq.PM10,
+		USEPAIndex: aq.USEPAIndex,
+	}
+}
+
+// Prefere as coordenadas, quando o provedor de CEP as informa, ao nome da cidade; days > 0 inclui a previsão
+func (app *application) findWeather(ctx context.Context, address *viacep.ViaCepResponse, days int, date time.Time) (*weatherapi.WeatherApiResponse, error) {
+	c := address.Coordinates
+	switch {
+	case !date.IsZero():
+		return app.weatherApiClient.FindTemperatureByLocationOnDate(ctx, address.City, address.State, date)
+	case c != nil && days > 0:
+		return app.weatherApiClient.FindForecastByCoords(ctx, c.Lat, c.Lon, days)
+	case c != nil:
+		return app.weatherApiClient.FindTemperatureByCoords(ctx, c.Lat, c.Lon)
+	case days > 0:
+		return app.weatherApiClient.FindForecastByLocation(ctx, address.City, address.State, days)
+	default:
+		return app.weatherApiClient.FindTemperatureByLocation(ctx, address.City, address.State)
+	}
+}
+
+// Ausente equivale a 0 (só o clima atual); fora de 1..MaxForecastDays é inválido
+func parseForecastDays(v string) (int, bool) {
+	if v == "" {
+		return 0, true
+	}
+	days, err := strconv.Atoi(v)
+	if err != nil || !weatherapi.ValidForecastDays(days) {
+		return 0, false
+	}
+	return days, true
+}
+
+// Ausente equivale à data zero (clima atual); formato AAAA-MM-DD entre MinHistoryDate e hoje
+func parseHistoryDate(v string, now time.Time) (time.Time, bool) {
+	if v == "" {
+		return time.Time{}, true
+	}
+	date, err := time.Parse(weatherapi.DateLayout, v)
+	if err != nil || !weatherapi.ValidHistoryDate(date, now) {
+		return time.Time{}, false
+	}
+	return date, true
+}
+
+func (app *application) newForecast(f *weatherapi.Forecast) []forecastDay {
+	if f == nil {
+		return nil
+	}
+	days := make([]forecastDay, 0, len(f.ForecastDay))
+	for _, d := range f.ForecastDay {
+		days = append(days, forecastDay{
+			Date:         d.Date,
+			MaxTempC:     roundTemp(d.Day.MaxTempC, app.tempPrecision),
+			MinTempC:     roundTemp(d.Day.MinTempC, app.tempPrecision),
+			MaxTempF:     roundTemp(d.Day.MaxTempF, app.tempPrecision),
+			MinTempF:     roundTemp(d.Day.MinTempF, app.tempPrecision),
+			ChanceOfRain: d.Day.DailyChanceOfRain,
+			Condition:    d.Day.Condition.Text,
+		})
+	}
+	return days
+}
+
+// Resultado da consulta de CEP para a métrica cep_lookups_total
+func cepOutcome(err error) string {
+	switch {
+	case err == nil:
+		return metrics.CepFound
+	case errors.Is(err, viacep.ErrCepNotFound):
+		return metrics.CepNotFound
+	case errors.Is(err, viacep.ErrInvalidCep):
+		return metrics.CepInvalid
+	default:
+		return metrics.CepError
+	}
+}
+
+// Dados do build, injetados via -ldflags
+func versionHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(version.Get())
+}
+
+// Readiness: só responde 200 se ViaCEP e WeatherAPI estiverem acessíveis dentro do timeout
+func (app *application) readyHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), app.readyTimeout)
+	defer cancel()
+
+	type result struct {
+		dependency string
+		err        error
+	}
+
+	// Buffer do tamanho das verificações para que nenhuma goroutine fique presa após o timeout
+	results := make(chan result, len(app.readyChecks))
+	pending := make(map[string]bool, len(app.readyChecks))
+	for dependency, check := range app.readyChecks {
+		pending[dependency] = true
+		go func() {
+			results <- result{dependency, check(ctx)}
+		}()
+	}
+
+	failed := []string{}
+	for len(pending) > 0 {
+		select {
+		case res := <-results:
+			delete(pending, res.dependency)
+			if res.err != nil {
+				app.logger.Warn("readiness check failed", "dependency", res.dependency, "error", res.err)
+				failed = append(failed, res.dependency)
+			}
+		case <-ctx.Done():
+			for dependency := range pending {
+				app.logger.Warn("readiness check timed out", "dependency", dependency)
+				failed = append(failed, dependency)
+			}
+			pending = nil
+		}
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(response)
+	if len(failed) > 0 {
+		sort.Strings(failed)
+		w.WriteHeader(http.StatusServiceUnavailable)
+		json.NewEncoder(w).Encode(readyResponse{Status: "unavailable", Failed: failed})
+		return
+	}
+
+	json.NewEncoder(w).Encode(readyResponse{Status: "ready"})
+}
+
+// Remove o ruído de ponto flutuante (ex.: 298.6500000003)
+func roundTemp(v float64, places int) float64 {
+	p := math.Pow10(places)
+	return math.Round(v*p) / p
+}
+
+func ptr[T any](v T) *T {
+	return &v
 }
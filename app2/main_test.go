@@ -0,0 +1,1131 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"l02-02/limiter"
+	"l02-02/metrics"
+	"l02-02/version"
+	"l02-02/viacep"
+	"l02-02/viacep/viacepmock"
+	"l02-02/weatherapi"
+	"l02-02/weatherapi/weatherapimock"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+type mockViaCepClient struct {
+	address *viacep.ViaCepResponse
+	err     error
+	delay   time.Duration
+	// Último CEP consultado
+	gotCep string
+}
+
+func (m *mockViaCepClient) FindAddressByCep(ctx context.Context, cep string) (*viacep.ViaCepResponse, error) {
+	m.gotCep = cep
+	if m.delay > 0 {
+		time.Sleep(m.delay)
+	}
+	return m.address, m.err
+}
+
+type mockWeatherApiClient struct {
+	weather *weatherapi.WeatherApiResponse
+	err     error
+	delay   time.Duration
+	// Última localização consultada
+	gotCity   string
+	gotState  string
+	gotCoords *viacep.Coordinates
+	gotDays   int
+	gotDate   time.Time
+}
+
+func (m *mockWeatherApiClient) FindTemperatureByLocationOnDate(ctx context.Context, city, state string, date time.Time) (*weatherapi.WeatherApiResponse, error) {
+	m.gotDate = date
+	return m.FindTemperatureByLocation(ctx, city, state)
+}
+
+func (m *mockWeatherApiClient) FindForecastByLocation(ctx context.Context, city, state string, days int) (*weatherapi.WeatherApiResponse, error) {
+	m.gotDays = days
+	return m.FindTemperatureByLocation(ctx, city, state)
+}
+
+func (m *mockWeatherApiClient) FindForecastByCoords(ctx context.Context, lat, lon float64, days int) (*weatherapi.WeatherApiResponse, error) {
+	m.gotDays = days
+	return m.FindTemperatureByCoords(ctx, lat, lon)
+}
+
+func (m *mockWeatherApiClient) FindTemperatureByCoords(ctx context.Context, lat, lon float64) (*weatherapi.WeatherApiResponse, error) {
+	m.gotCoords = &viacep.Coordinates{Lat: lat, Lon: lon}
+	return m.weather, m.err
+}
+
+func (m *mockWeatherApiClient) FindTemperatureByCity(ctx context.Context, city string) (*weatherapi.WeatherApiResponse, error) {
+	return m.FindTemperatureByLocation(ctx, city, "")
+}
+
+func (m *mockWeatherApiClient) FindTemperatureByLocation(ctx context.Context, city, state string) (*weatherapi.WeatherApiResponse, error) {
+	m.gotCity, m.gotState = city, state
+	if m.delay > 0 {
+		time.Sleep(m.delay)
+	}
+	return m.weather, m.err
+}
+
+func newTestApplication(viaCep viacep.CepProvider, weather weatherapi.WeatherApiClient) *application {
+	app := newApplication(viaCep, weather, slog.New(slog.DiscardHandler), noop.NewTracerProvider().Tracer("test"))
+	app.readyTimeout = time.Second
+	return app
+}
+
+func healthyMocks() (*mockViaCepClient, *mockWeatherApiClient) {
+	return &mockViaCepClient{address: &viacep.ViaCepResponse{City: "São Paulo"}},
+		&mockWeatherApiClient{weather: &weatherapi.WeatherApiResponse{Current: weatherapi.CurrentWeather{TempC: 25, TempF: 77}}}
+}
+
+// Verificações do /ready que devolvem os erros informados
+func readyChecks(viaCepErr, weatherErr error) map[string]func(context.Context) error {
+	return map[string]func(context.Context) error{
+		"viacep":     func(ctx context.Context) error { return viaCepErr },
+		"weatherapi": func(ctx context.Context) error { return weatherErr },
+	}
+}
+
+func TestReadyHandler_Healthy(t *testing.T) {
+	viaCep, weather := healthyMocks()
+	app := newTestApplication(viaCep, weather)
+	app.readyChecks = readyChecks(nil, nil)
+
+	rec := httptest.NewRecorder()
+	app.readyHandler(rec, httptest.NewRequest(http.MethodGet, "/ready", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, but got %d", rec.Code)
+	}
+
+	var body readyResponse
+	json.NewDecoder(rec.Body).Decode(&body)
+	if body.Status != "ready" {
+		t.Errorf("expected status 'ready', but got '%s'", body.Status)
+	}
+}
+
+func TestReadyHandler_Degraded(t *testing.T) {
+	viaCep, weather := healthyMocks()
+	app := newTestApplication(viaCep, weather)
+	app.readyChecks = readyChecks(nil, weatherapi.ErrInternal)
+
+	rec := httptest.NewRecorder()
+	app.readyHandler(rec, httptest.NewRequest(http.MethodGet, "/ready", nil))
+
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected status 503, but got %d", rec.Code)
+	}
+
+	var body readyResponse
+	json.NewDecoder(rec.Body).Decode(&body)
+	if len(body.Failed) != 1 || body.Failed[0] != "weatherapi" {
+		t.Errorf("expected failed dependencies [weatherapi], but got %v", body.Failed)
+	}
+}
+
+func TestReadyHandler_Timeout(t *testing.T) {
+	viaCep, weather := healthyMocks()
+	app := newTestApplication(viaCep, weather)
+	app.readyChecks = readyChecks(nil, nil)
+	app.readyChecks["viacep"] = func(ctx context.Context) error {
+		time.Sleep(time.Second)
+		return nil
+	}
+	app.readyTimeout = 20 * time.Millisecond
+
+	start := time.Now()
+	rec := httptest.NewRecorder()
+	app.readyHandler(rec, httptest.NewRequest(http.MethodGet, "/ready", nil))
+
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("expected the check to stop at the timeout, but took %s", elapsed)
+	}
+
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected status 503, but got %d", rec.Code)
+	}
+
+	var body readyResponse
+	json.NewDecoder(rec.Body).Decode(&body)
+	if len(body.Failed) != 1 || body.Failed[0] != "viacep" {
+		t.Errorf("expected failed dependencies [viacep], but got %v", body.Failed)
+	}
+}
+
+func TestReadyHandler_ViaCepDownAfterSuccess(t *testing.T) {
+	var down atomic.Bool
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if down.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{"cep": "01001-000", "localidade": "São Paulo"}`))
+	}))
+	defer upstream.Close()
+
+	// Como no main: cache e circuit breaker no cliente, fallback e singleflight por fora
+	logger := slog.New(slog.DiscardHandler)
+	viaCepClient := viacep.NewClient(logger, noop.NewTracerProvider().Tracer("test"),
+		viacep.WithBaseURL(upstream.URL),
+		viacep.WithCache(time.Hour, 10),
+		viacep.WithCircuitBreaker(5, time.Minute),
+	)
+	fallback := &mockViaCepClient{address: &viacep.ViaCepResponse{City: "São Paulo"}}
+	_, weather := healthyMocks()
+	app := newTestApplication(newCepProvider(logger, viaCepClient, fallback), weather)
+	app.readyChecks = readyChecks(nil, nil)
+	app.readyChecks["viacep"] = viaCepClient.Ping
+
+	rec := httptest.NewRecorder()
+	app.readyHandler(rec, httptest.NewRequest(http.MethodGet, "/ready", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200 while ViaCEP is up, but got %d", rec.Code)
+	}
+	// Deixa o CEP no cache, que passaria a responder pelo ViaCEP
+	if _, err := app.viaCepClient.FindAddressByCep(context.Background(), "01001-000"); err != nil {
+		t.Fatalf("expected no error, but got: %v", err)
+	}
+
+	down.Store(true)
+	rec = httptest.NewRecorder()
+	app.readyHandler(rec, httptest.NewRequest(http.MethodGet, "/ready", nil))
+
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected status 503 with ViaCEP down, but got %d", rec.Code)
+	}
+	var body readyResponse
+	json.NewDecoder(rec.Body).Decode(&body)
+	if len(body.Failed) != 1 || body.Failed[0] != "viacep" {
+		t.Errorf("expected failed dependencies [viacep], but got %v", body.Failed)
+	}
+}
+
+func TestMetricsEndpoint(t *testing.T) {
+	viaCep, weather := healthyMocks()
+	app := newTestApplication(viaCep, weather)
+	app.metrics.ObserveOutbound("viacep", "2xx", 10*time.Millisecond)
+	app.metrics.ObserveOutbound("weatherapi", "5xx", 10*time.Millisecond)
+	app.metrics.ObserveCacheLookup("viacep", true)
+	app.metrics.ObserveCacheLookup("viacep", false)
+	app.metrics.ObserveCacheEvictions("viacep", 2)
+	app.metrics.SetCacheSize("viacep", 7)
+
+	handler := app.instrument("/get-weather-by-cep", http.HandlerFunc(app.handler))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/get-weather-by-cep?cep=01001-000", nil))
+
+	rec := httptest.NewRecorder()
+	app.metrics.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	body, _ := io.ReadAll(rec.Body)
+	for _, name := range []string{
+		`http_requests_total{route="/get-weather-by-cep",status="200"} 1`,
+		"http_request_duration_seconds_bucket",
+		`outbound_request_duration_seconds_count{upstream="viacep"} 1`,
+		`outbound_requests_total{status_class="2xx",upstream="viacep"} 1`,
+		`outbound_requests_total{status_class="5xx",upstream="weatherapi"} 1`,
+		`outbound_errors_total{status_class="5xx",upstream="weatherapi"} 1`,
+		`cache_hits_total{cache="viacep"} 1`,
+		`cache_misses_total{cache="viacep"} 1`,
+		`cache_evictions_total{cache="viacep"} 2`,
+		`cache_size{cache="viacep"} 7`,
+	} {
+		if !strings.Contains(string(body), name) {
+			t.Errorf("expected metrics output to contain '%s'", name)
+		}
+	}
+	if strings.Contains(string(body), `outbound_errors_total{status_class="2xx"`) {
+		t.Error("expected successful outbound calls not to count as errors")
+	}
+}
+
+func TestHandler_CepLookupMetric(t *testing.T) {
+	tests := []struct {
+		name     string
+		cep      string
+		err      error
+		expected string
+	}{
+		{"found", "01001-000", nil, metrics.CepFound},
+		{"not found", "99999-999", viacep.ErrCepNotFound, metrics.CepNotFound},
+		{"malformed", "abc", nil, metrics.CepInvalid},
+		{"rejected by provider", "00000-000", viacep.ErrInvalidCep, metrics.CepInvalid},
+		{"provider error", "01001-000", viacep.ErrInternal, metrics.CepError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			viaCep, weather := healthyMocks()
+			if tt.err != nil {
+				viaCep.err = tt.err
+			}
+			app := newTestApplication(viaCep, weather)
+
+			app.handler(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/get-weather-by-cep?cep="+tt.cep, nil))
+
+			rec := httptest.NewRecorder()
+			app.metrics.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+			expected := `cep_lookups_total{outcome="` + tt.expected + `"} 1`
+			if !strings.Contains(rec.Body.String(), expected) {
+				t.Errorf("expected metrics output to contain '%s'", expected)
+			}
+			if strings.Count(rec.Body.String(), "cep_lookups_total{") != 1 {
+				t.Errorf("expected a single outcome to be counted, but got:\n%s", rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestHandler_Success(t *testing.T) {
+	viaCep, weather := healthyMocks()
+	app := newTestApplication(viaCep, weather)
+
+	rec := httptest.NewRecorder()
+	app.handler(rec, httptest.NewRequest(http.MethodGet, "/get-weather-by-cep?cep=01001-000", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, but got %d", rec.Code)
+	}
+
+	if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("expected Content-Type 'application/json', but got '%s'", ct)
+	}
+
+	if weather.gotCity != "São Paulo" {
+		t.Errorf("expected the weather lookup for 'São Paulo', but got '%s'", weather.gotCity)
+	}
+
+	expected := `{"city":"São Paulo","temp_C":25,"temp_F":77,"temp_K":298.15}`
+	if body := strings.TrimSpace(rec.Body.String()); body != expected {
+		t.Errorf("expected body %s, but got %s", expected, body)
+	}
+}
+
+func TestHandler_CepFormats(t *testing.T) {
+	expected := `{"city":"São Paulo","temp_C":25,"temp_F":77,"temp_K":298.15}`
+
+	for _, raw := range []string{"01001000", "01001-000"} {
+		t.Run(raw, func(t *testing.T) {
+			viaCep, weather := healthyMocks()
+			app := newTestApplication(viaCep, weather)
+
+			rec := httptest.NewRecorder()
+			app.handler(rec, httptest.NewRequest(http.MethodGet, "/get-weather-by-cep?cep="+raw, nil))
+
+			if rec.Code != http.StatusOK {
+				t.Fatalf("expected status 200, but got %d", rec.Code)
+			}
+			// O ViaCEP recebe sempre o formato NNNNN-NNN, com ou sem hífen na entrada
+			if viaCep.gotCep != "01001-000" {
+				t.Errorf("expected the lookup for '01001-000', but got '%s'", viaCep.gotCep)
+			}
+			if body := strings.TrimSpace(rec.Body.String()); body != expected {
+				t.Errorf("expected body %s, but got %s", expected, body)
+			}
+		})
+	}
+}
+
+func TestHandler_WeatherDetails(t *testing.T) {
+	viaCep, _ := healthyMocks()
+	weather := &mockWeatherApiClient{weather: &weatherapi.WeatherApiResponse{Current: weatherapi.CurrentWeather{
+		TempC:     25,
+		TempF:     77,
+		Humidity:  ptr(62),
+		WindKph:   ptr(11.2),
+		Condition: weatherapi.Condition{Text: "Partly cloudy"},
+	}}}
+
+	rec := httptest.NewRecorder()
+	newTestApplication(viaCep, weather).handler(rec, httptest.NewRequest(http.MethodGet, "/get-weather-by-cep?cep=01001-000", nil))
+
+	expected := `{"city":"São Paulo","temp_C":25,"temp_F":77,"temp_K":298.15,"humidity":62,"wind_kph":11.2,"condition":"Partly cloudy"}`
+	if body := strings.TrimSpace(rec.Body.String()); body != expected {
+		t.Errorf("expected body %s, but got %s", expected, body)
+	}
+}
+
+func TestHandler_Location(t *testing.T) {
+	viaCep, _ := healthyMocks()
+	weather := &mockWeatherApiClient{weather: &weatherapi.WeatherApiResponse{
+		Location: weatherapi.Location{Name: "São Paulo", Region: "Sao Paulo", Country: "Brazil", Lat: -23.53, Lon: -46.62, Localtime: "2026-10-14 11:00"},
+		Current:  weatherapi.CurrentWeather{TempC: 25, TempF: 77},
+	}}
+
+	rec := httptest.NewRecorder()
+	newTestApplication(viaCep, weather).handler(rec, httptest.NewRequest(http.MethodGet, "/get-weather-by-cep?cep=01001-000", nil))
+
+	var body response
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatalf("expected a JSON body, but got: %v", err)
+	}
+
+	if body.Region != "Sao Paulo" || body.Country != "Brazil" {
+		t.Errorf("expected region and country from the weather location, but got %+v", body)
+	}
+}
+
+func TestHandler_PrefersCoordinates(t *testing.T) {
+	tests := []struct {
+		name   string
+		coords *viacep.Coordinates
+	}{
+		{"with coordinates", &viacep.Coordinates{Lat: -23.5503, Lon: -46.6339}},
+		{"without coordinates", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			viaCep := &mockViaCepClient{address: &viacep.ViaCepResponse{City: "São Paulo", State: "SP", Coordinates: tt.coords}}
+			_, weather := healthyMocks()
+
+			rec := httptest.NewRecorder()
+			newTestApplication(viaCep, weather).handler(rec, httptest.NewRequest(http.MethodGet, "/get-weather-by-cep?cep=01001-000", nil))
+
+			if rec.Code != http.StatusOK {
+				t.Fatalf("expected status 200, but got %d", rec.Code)
+			}
+
+			if tt.coords != nil {
+				if weather.gotCoords == nil || *weather.gotCoords != *tt.coords || weather.gotCity != "" {
+					t.Errorf("expected the lookup by %+v, but got coords %+v and city '%s'", tt.coords, weather.gotCoords, weather.gotCity)
+				}
+				return
+			}
+
+			if weather.gotCoords != nil || weather.gotCity != "São Paulo" || weather.gotState != "SP" {
+				t.Errorf("expected the fallback lookup by (São Paulo, SP), but got coords %+v and (%s, %s)", weather.gotCoords, weather.gotCity, weather.gotState)
+			}
+		})
+	}
+}
+
+func TestHandler_AirQuality(t *testing.T) {
+	viaCep, _ := healthyMocks()
+	weather := &mockWeatherApiClient{weather: &weatherapi.WeatherApiResponse{Current: weatherapi.CurrentWeather{
+		TempC:      25,
+		TempF:      77,
+		AirQuality: &weatherapi.AirQuality{PM25: 12.4, PM10: 18.9, USEPAIndex: 2},
+	}}}
+
+	rec := httptest.NewRecorder()
+	newTestApplication(viaCep, weather).handler(rec, httptest.NewRequest(http.MethodGet, "/get-weather-by-cep?cep=01001-000", nil))
+
+	expected := `"air_quality":{"pm2_5":12.4,"pm10":18.9,"us_epa_index":2}`
+	if body := rec.Body.String(); !strings.Contains(body, expected) {
+		t.Errorf("expected body to contain %s, but got %s", expected, body)
+	}
+}
+
+func TestHandler_Kelvin(t *testing.T) {
+	tests := []struct {
+		tempC float64
+		tempK float64
+	}{
+		{0, 273.15},
+		{-273.15, 0},
+		{36.5, 309.65},
+	}
+
+	for _, tt := range tests {
+		viaCep, _ := healthyMocks()
+		weather := &mockWeatherApiClient{weather: &weatherapi.WeatherApiResponse{Current: weatherapi.CurrentWeather{TempC: tt.tempC}}}
+
+		rec := httptest.NewRecorder()
+		newTestApplication(viaCep, weather).handler(rec, httptest.NewRequest(http.MethodGet, "/get-weather-by-cep?cep=01001-000", nil))
+
+		var body response
+		if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+			t.Fatalf("expected a JSON body, but got: %v", err)
+		}
+
+		if body.TempK == nil || math.Abs(*body.TempK-tt.tempK) > 1e-9 {
+			t.Errorf("expected %v K for %v C, but got %v", tt.tempK, tt.tempC, body.TempK)
+		}
+	}
+}
+
+func TestRoundTemp(t *testing.T) {
+	tests := []struct {
+		value    float64
+		places   int
+		expected float64
+	}{
+		{25.5 + 273.15, 2, 298.65},
+		{0.1 + 0.2, 2, 0.3},
+		{77.456, 1, 77.5},
+		{-3.14159, 0, -3},
+	}
+
+	for _, tt := range tests {
+		if got := roundTemp(tt.value, tt.places); got != tt.expected {
+			t.Errorf("expected roundTemp(%v, %d) = %v, but got %v", tt.value, tt.places, tt.expected, got)
+		}
+	}
+}
+
+func TestHandler_RoundsTemperatures(t *testing.T) {
+	viaCep, _ := healthyMocks()
+	weather := &mockWeatherApiClient{weather: &weatherapi.WeatherApiResponse{Current: weatherapi.CurrentWeather{TempC: 25.5000000001, TempF: 77.9000000002}}}
+
+	rec := httptest.NewRecorder()
+	newTestApplication(viaCep, weather).handler(rec, httptest.NewRequest(http.MethodGet, "/get-weather-by-cep?cep=01001-000", nil))
+
+	expected := `{"city":"São Paulo","temp_C":25.5,"temp_F":77.9,"temp_K":298.65}`
+	if body := strings.TrimSpace(rec.Body.String()); body != expected {
+		t.Errorf("expected body %s, but got %s", expected, body)
+	}
+}
+
+func TestHandler_AddressFields(t *testing.T) {
+	_, weather := healthyMocks()
+	viaCep := &mockViaCepClient{address: &viacep.ViaCepResponse{
+		Cep:    "01001-000",
+		Street: "Praça da Sé",
+		City:   "São Paulo",
+		State:  "SP",
+	}}
+	app := newTestApplication(viaCep, weather)
+
+	rec := httptest.NewRecorder()
+	app.handler(rec, httptest.NewRequest(http.MethodGet, "/get-weather-by-cep?cep=01001-000", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, but got %d", rec.Code)
+	}
+
+	var body response
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatalf("expected a JSON body, but got: %v", err)
+	}
+
+	if body.State != "SP" || body.Street != "Praça da Sé" || body.Cep != "01001-000" {
+		t.Errorf("expected address fields to flow through, but got %+v", body)
+	}
+
+	if weather.gotCity != "São Paulo" || weather.gotState != "SP" {
+		t.Errorf("expected the weather lookup for (São Paulo, SP), but got (%s, %s)", weather.gotCity, weather.gotState)
+	}
+}
+
+func TestHandler_AddressFieldsOmittedWhenEmpty(t *testing.T) {
+	viaCep, weather := healthyMocks()
+	app := newTestApplication(viaCep, weather)
+
+	rec := httptest.NewRecorder()
+	app.handler(rec, httptest.NewRequest(http.MethodGet, "/get-weather-by-cep?cep=01001-000", nil))
+
+	for _, field := range []string{`"state"`, `"street"`, `"cep"`} {
+		if strings.Contains(rec.Body.String(), field) {
+			t.Errorf("expected %s to be omitted, but got body %s", field, rec.Body.String())
+		}
+	}
+}
+
+func TestHandler_SpanStatus(t *testing.T) {
+	tests := []struct {
+		name       string
+		viaCepErr  error
+		weatherErr error
+		status     int
+		code       codes.Code
+	}{
+		{"success", nil, nil, http.StatusOK, codes.Unset},
+		{"cep not found", viacep.ErrCepNotFound, nil, http.StatusNotFound, codes.Unset},
+		{"viacep failure", viacep.ErrInternal, nil, http.StatusInternalServerError, codes.Error},
+		{"weather failure", nil, weatherapi.ErrInternal, http.StatusInternalServerError, codes.Error},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			viaCep, weather := healthyMocks()
+			viaCep.err = tt.viaCepErr
+			weather.err = tt.weatherErr
+			if tt.viaCepErr != nil {
+				viaCep.address = nil
+			}
+
+			recorder := tracetest.NewSpanRecorder()
+			app := newTestApplication(viaCep, weather)
+			app.tracer = sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)).Tracer("test")
+
+			rr := httptest.NewRecorder()
+			app.handler(rr, httptest.NewRequest(http.MethodGet, "/get-weather-by-cep?cep=01001000", nil))
+
+			if rr.Code != tt.status {
+				t.Fatalf("expected status %d, but got %d", tt.status, rr.Code)
+			}
+
+			spans := recorder.Ended()
+			if len(spans) != 1 {
+				t.Fatalf("expected 1 span, but got %d", len(spans))
+			}
+
+			if got := spans[0].Status().Code; got != tt.code {
+				t.Errorf("expected span status %s, but got %s", tt.code, got)
+			}
+		})
+	}
+}
+
+func TestHandler_CepWithoutCity(t *testing.T) {
+	tests := []struct {
+		name string
+		city string
+	}{
+		{"empty", ""},
+		{"whitespace", "   "},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			viaCep, weather := healthyMocks()
+			viaCep.address = &viacep.ViaCepResponse{Cep: "01001-000", City: tt.city}
+			// Com a consulta vazia a WeatherAPI falharia; o handler não deve chegar até ela
+			weather.err = weatherapi.ErrInternal
+			app := newTestApplication(viaCep, weather)
+
+			rr := httptest.NewRecorder()
+			app.handler(rr, httptest.NewRequest(http.MethodGet, "/get-weather-by-cep?cep=01001000", nil))
+
+			if rr.Code != http.StatusNotFound {
+				t.Fatalf("expected status 404, but got %d", rr.Code)
+			}
+			var body errorResponse
+			json.NewDecoder(rr.Body).Decode(&body)
+			if body.Code != codeCepWithoutCity {
+				t.Errorf("expected code '%s', but got '%s'", codeCepWithoutCity, body.Code)
+			}
+		})
+	}
+}
+
+func TestHandler_ErrorResponses(t *testing.T) {
+	tests := []struct {
+		name       string
+		target     string
+		viaCepErr  error
+		weatherErr error
+		status     int
+		code       string
+	}{
+		{"missing cep", "/get-weather-by-cep", nil, nil, http.StatusBadRequest, codeMissingCep},
+		{"invalid zipcode", "/get-weather-by-cep?cep=123", nil, nil, http.StatusUnprocessableEntity, codeInvalidZipcode},
+		{"cep not found", "/get-weather-by-cep?cep=01001000", viacep.ErrCepNotFound, nil, http.StatusNotFound, codeCepNotFound},
+		{"cep rejected by provider", "/get-weather-by-cep?cep=01001000", viacep.ErrInvalidCep, nil, http.StatusUnprocessableEntity, codeInvalidZipcode},
+		{"address upstream failure", "/get-weather-by-cep?cep=01001000", viacep.ErrInternal, nil, http.StatusInternalServerError, codeUpstreamError},
+		{"weather upstream failure", "/get-weather-by-cep?cep=01001000", nil, weatherapi.ErrInternal, http.StatusInternalServerError, codeUpstreamError},
+		{"address upstream timeout", "/get-weather-by-cep?cep=01001000", viacep.ErrTimeout, nil, http.StatusGatewayTimeout, codeTimeout},
+		{"weather upstream timeout", "/get-weather-by-cep?cep=01001000", nil, weatherapi.ErrTimeout, http.StatusGatewayTimeout, codeTimeout},
+		{"address upstream overloaded", "/get-weather-by-cep?cep=01001000", limiter.ErrOverloaded, nil, http.StatusServiceUnavailable, codeOverloaded},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			viaCep, weather := healthyMocks()
+			viaCep.err = tt.viaCepErr
+			weather.err = tt.weatherErr
+			if tt.viaCepErr != nil {
+				viaCep.address = nil
+			}
+
+			rr := httptest.NewRecorder()
+			newTestApplication(viaCep, weather).handler(rr, httptest.NewRequest(http.MethodGet, tt.target, nil))
+
+			if rr.Code != tt.status {
+				t.Fatalf("expected status %d, but got %d", tt.status, rr.Code)
+			}
+
+			if ct := rr.Header().Get("Content-Type"); ct != "application/json" {
+				t.Errorf("expected Content-Type 'application/json', but got '%s'", ct)
+			}
+
+			var body errorResponse
+			if err := json.NewDecoder(rr.Body).Decode(&body); err != nil {
+				t.Fatalf("expected a JSON body, but got: %v", err)
+			}
+
+			if body.Code != tt.code {
+				t.Errorf("expected code '%s', but got '%s'", tt.code, body.Code)
+			}
+
+			if body.Error == "" {
+				t.Error("expected a non-empty error message")
+			}
+		})
+	}
+}
+
+func TestHandler_DegradedMode(t *testing.T) {
+	tests := []struct {
+		name     string
+		degraded bool
+		status   int
+	}{
+		{"strict", false, http.StatusInternalServerError},
+		{"degraded", true, http.StatusPartialContent},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			viaCep := &mockViaCepClient{address: &viacep.ViaCepResponse{City: "São Paulo", State: "SP", Cep: "01001-000"}}
+			weather := &mockWeatherApiClient{err: weatherapi.ErrInternal}
+
+			recorder := tracetest.NewSpanRecorder()
+			app := newTestApplication(viaCep, weather)
+			app.tracer = sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)).Tracer("test")
+			app.degradedMode = tt.degraded
+
+			rr := httptest.NewRecorder()
+			app.handler(rr, httptest.NewRequest(http.MethodGet, "/get-weather-by-cep?cep=01001000", nil))
+
+			if rr.Code != tt.status {
+				t.Fatalf("expected status %d, but got %d", tt.status, rr.Code)
+			}
+
+			if !tt.degraded {
+				return
+			}
+
+			var body map[string]any
+			if err := json.NewDecoder(rr.Body).Decode(&body); err != nil {
+				t.Fatalf("expected a JSON body, but got: %v", err)
+			}
+
+			if body["city"] != "São Paulo" || body["partial"] != true {
+				t.Errorf("expected city and partial flag, but got %v", body)
+			}
+
+			for _, field := range []string{"temp_C", "temp_F", "temp_K"} {
+				if _, ok := body[field]; ok {
+					t.Errorf("expected %s to be omitted, but got %v", field, body[field])
+				}
+			}
+
+			events := recorder.Ended()[0].Events()
+			found := false
+			for _, e := range events {
+				found = found || e.Name == "degraded.weather_unavailable"
+			}
+			if !found {
+				t.Error("expected a 'degraded.weather_unavailable' span event")
+			}
+		})
+	}
+}
+
+func TestRoutes_GetWeatherByCep(t *testing.T) {
+	tests := []struct {
+		name   string
+		target string
+		viaCep *mockViaCepClient
+		status int
+		code   string
+	}{
+		{"success", "/get-weather-by-cep?cep=01001-000", &mockViaCepClient{address: &viacep.ViaCepResponse{City: "São Paulo"}}, http.StatusOK, ""},
+		{"invalid cep", "/get-weather-by-cep?cep=abc", &mockViaCepClient{}, http.StatusUnprocessableEntity, codeInvalidZipcode},
+		{"not found", "/get-weather-by-cep?cep=99999-999", &mockViaCepClient{err: viacep.ErrCepNotFound}, http.StatusNotFound, codeCepNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, weather := healthyMocks()
+			server := httptest.NewServer(newTestApplication(tt.viaCep, weather).routes())
+			defer server.Close()
+
+			resp, err := http.Get(server.URL + tt.target)
+			if err != nil {
+				t.Fatalf("expected no error, but got: %v", err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != tt.status {
+				t.Fatalf("expected status %d, but got %d", tt.status, resp.StatusCode)
+			}
+
+			if resp.Header.Get(requestIDHeader) == "" {
+				t.Error("expected the request ID middleware to be wired")
+			}
+
+			if tt.code == "" {
+				var body response
+				if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+					t.Fatalf("expected a JSON body, but got: %v", err)
+				}
+				if body.City != "São Paulo" || body.TempC == nil || *body.TempC != 25 {
+					t.Errorf("unexpected body: %+v", body)
+				}
+				return
+			}
+
+			var body errorResponse
+			if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+				t.Fatalf("expected a JSON body, but got: %v", err)
+			}
+			if body.Code != tt.code {
+				t.Errorf("expected code '%s', but got '%s'", tt.code, body.Code)
+			}
+		})
+	}
+}
+
+func TestHandler_ForecastDays(t *testing.T) {
+	tests := []struct {
+		name     string
+		param    string
+		status   int
+		wantDays int
+	}{
+		{"absent", "", http.StatusOK, 0},
+		{"one day", "1", http.StatusOK, 1},
+		{"max days", "3", http.StatusOK, 3},
+		{"zero", "0", http.StatusBadRequest, 0},
+		{"above max", "4", http.StatusBadRequest, 0},
+		{"not a number", "abc", http.StatusBadRequest, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			viaCep, _ := healthyMocks()
+			weather := &mockWeatherApiClient{weather: &weatherapi.WeatherApiResponse{
+				Current: weatherapi.CurrentWeather{TempC: 25, TempF: 77},
+				Forecast: &weatherapi.Forecast{ForecastDay: []weatherapi.ForecastDay{
+					{Date: "2026-10-14", Day: weatherapi.DayWeather{MaxTempC: 28.1, MaxTempF: 82.6, MinTempC: 17.4, MinTempF: 63.3, DailyChanceOfRain: 80, Condition: weatherapi.Condition{Text: "Patchy rain nearby"}}},
+				}},
+			}}
+
+			target := "/get-weather-by-cep?cep=01001-000"
+			if tt.param != "" {
+				target += "&forecast_days=" + tt.param
+			}
+
+			rec := httptest.NewRecorder()
+			newTestApplication(viaCep, weather).handler(rec, httptest.NewRequest(http.MethodGet, target, nil))
+
+			if rec.Code != tt.status {
+				t.Fatalf("expected status %d, but got %d", tt.status, rec.Code)
+			}
+
+			if tt.status != http.StatusOK {
+				var body errorResponse
+				if err := json.NewDecoder(rec.Body).Decode(&body); err != nil || body.Code != codeInvalidForecastDays {
+					t.Errorf("expected code '%s', but got '%s' (%v)", codeInvalidForecastDays, body.Code, err)
+				}
+				return
+			}
+
+			if weather.gotDays != tt.wantDays {
+				t.Errorf("expected %d forecast days requested, but got %d", tt.wantDays, weather.gotDays)
+			}
+
+			if tt.wantDays > 0 {
+				expected := `"forecast":[{"date":"2026-10-14","max_temp_C":28.1,"min_temp_C":17.4,"max_temp_F":82.6,"min_temp_F":63.3,"chance_of_rain":80,"condition":"Patchy rain nearby"}]`
+				if body := rec.Body.String(); !strings.Contains(body, expected) {
+					t.Errorf("expected body to contain %s, but got %s", expected, body)
+				}
+			}
+		})
+	}
+}
+
+func TestHandler_HistoryDate(t *testing.T) {
+	yesterday := time.Now().AddDate(0, 0, -1).Format(weatherapi.DateLayout)
+	tomorrow := time.Now().AddDate(0, 0, 1).Format(weatherapi.DateLayout)
+
+	tests := []struct {
+		name   string
+		query  string
+		status int
+	}{
+		{"valid date", "&date=2024-01-15", http.StatusOK},
+		{"yesterday", "&date=" + yesterday, http.StatusOK},
+		{"future date", "&date=" + tomorrow, http.StatusBadRequest},
+		{"before history start", "&date=2009-12-31", http.StatusBadRequest},
+		{"bad format", "&date=15/01/2024", http.StatusBadRequest},
+		{"with forecast days", "&date=2024-01-15&forecast_days=2", http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			viaCep, _ := healthyMocks()
+			weather := &mockWeatherApiClient{weather: &weatherapi.WeatherApiResponse{
+				Forecast: &weatherapi.Forecast{ForecastDay: []weatherapi.ForecastDay{
+					{Date: "2024-01-15", Day: weatherapi.DayWeather{MaxTempC: 30.2, MaxTempF: 86.4, MinTempC: 20.1, MinTempF: 68.2, AvgTempC: 24.6, AvgTempF: 76.3, DailyChanceOfRain: 90, Condition: weatherapi.Condition{Text: "Moderate rain"}}},
+				}},
+			}}
+
+			rec := httptest.NewRecorder()
+			newTestApplication(viaCep, weather).handler(rec, httptest.NewRequest(http.MethodGet, "/get-weather-by-cep?cep=01001-000"+tt.query, nil))
+
+			if rec.Code != tt.status {
+				t.Fatalf("expected status %d, but got %d", tt.status, rec.Code)
+			}
+
+			if tt.status != http.StatusOK {
+				var body errorResponse
+				if err := json.NewDecoder(rec.Body).Decode(&body); err != nil || body.Code != codeInvalidDate {
+					t.Errorf("expected code '%s', but got '%s' (%v)", codeInvalidDate, body.Code, err)
+				}
+				if !weather.gotDate.IsZero() {
+					t.Error("expected no history lookup for an invalid date")
+				}
+				return
+			}
+
+			if weather.gotDate.IsZero() {
+				t.Fatal("expected a history lookup")
+			}
+
+			var body response
+			if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+				t.Fatalf("expected a JSON body, but got: %v", err)
+			}
+
+			if body.TempC == nil || *body.TempC != 24.6 || body.TempK == nil || *body.TempK != 297.75 {
+				t.Errorf("expected the day average temperatures, but got %+v", body)
+			}
+
+			if body.History == nil || body.History.MaxTempC != 30.2 || body.History.MinTempC != 20.1 || body.Forecast != nil {
+				t.Errorf("expected the history day without forecast, but got %+v / %+v", body.History, body.Forecast)
+			}
+		})
+	}
+}
+
+func TestVersionEndpoint(t *testing.T) {
+	original := version.Get()
+	t.Cleanup(func() {
+		version.Version, version.Commit, version.BuildTime = original.Version, original.Commit, original.BuildTime
+	})
+	version.Version, version.Commit, version.BuildTime = "1.2.3", "abc123", "2026-10-14T12:00:00Z"
+
+	rec := httptest.NewRecorder()
+	newTestApplication(healthyMocks()).routes().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/version", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, but got %d", rec.Code)
+	}
+
+	expected := `{"version":"1.2.3","commit":"abc123","buildTime":"2026-10-14T12:00:00Z"}`
+	if body := strings.TrimSpace(rec.Body.String()); body != expected {
+		t.Errorf("expected body %s, but got %s", expected, body)
+	}
+}
+
+func TestHandler_TemperatureSpanAttributes(t *testing.T) {
+	viaCep, weather := healthyMocks()
+	weather.weather.Current.TempC = 21.456
+	weather.weather.Current.TempF = 70.6208
+
+	recorder := tracetest.NewSpanRecorder()
+	app := newTestApplication(viaCep, weather)
+	app.tracer = sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)).Tracer("test")
+
+	rr := httptest.NewRecorder()
+	app.handler(rr, httptest.NewRequest(http.MethodGet, "/get-weather-by-cep?cep=01001000", nil))
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, but got %d", rr.Code)
+	}
+
+	spans := recorder.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("expected 1 span, but got %d", len(spans))
+	}
+
+	attrs := attribute.NewSet(spans[0].Attributes()...)
+	expected := map[attribute.Key]float64{
+		"weather.temp_c": 21.46,
+		"weather.temp_f": 70.62,
+		"weather.temp_k": 294.61,
+	}
+	for key, want := range expected {
+		if got, ok := attrs.Value(key); !ok || got.AsFloat64() != want {
+			t.Errorf("expected %s=%v, but got %v (present: %v)", key, want, got.AsFloat64(), ok)
+		}
+	}
+}
+
+// Exemplo de uso dos mocks exportados, sem precisar reimplementar as interfaces
+func TestHandler_WithExportedMocks(t *testing.T) {
+	viaCep := &viacepmock.Provider{Address: &viacep.ViaCepResponse{City: "Curitiba", State: "PR", Cep: "80010-000"}}
+	weather := &weatherapimock.Client{Response: &weatherapi.WeatherApiResponse{Current: weatherapi.CurrentWeather{TempC: 18, TempF: 64.4}}}
+	app := newTestApplication(viaCep, weather)
+
+	rr := httptest.NewRecorder()
+	app.handler(rr, httptest.NewRequest(http.MethodGet, "/get-weather-by-cep?cep=80010000", nil))
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, but got %d", rr.Code)
+	}
+
+	if calls := viaCep.Calls(); len(calls) != 1 || calls[0] != "80010-000" {
+		t.Errorf("expected one lookup of '80010-000', but got %v", calls)
+	}
+
+	calls := weather.Calls()
+	if len(calls) != 1 {
+		t.Fatalf("expected 1 weather call, but got %d", len(calls))
+	}
+	if calls[0].Method != "FindTemperatureByLocation" || calls[0].City != "Curitiba" || calls[0].State != "PR" {
+		t.Errorf("expected FindTemperatureByLocation(Curitiba, PR), but got %+v", calls[0])
+	}
+
+	t.Run("errors", func(t *testing.T) {
+		viaCep := &viacepmock.Provider{Err: viacep.ErrCepNotFound}
+		rr := httptest.NewRecorder()
+		newTestApplication(viaCep, &weatherapimock.Client{}).handler(rr, httptest.NewRequest(http.MethodGet, "/get-weather-by-cep?cep=80010000", nil))
+
+		if rr.Code != http.StatusNotFound {
+			t.Errorf("expected status 404, but got %d", rr.Code)
+		}
+	})
+}
+
+func TestHandler_CacheControl(t *testing.T) {
+	tests := []struct {
+		name       string
+		cep        string
+		weatherErr error
+		degraded   bool
+		expected   string
+	}{
+		{"success", "01001000", nil, false, "public, max-age=120"},
+		{"invalid zipcode", "123", nil, false, "no-store"},
+		{"upstream error", "01001000", weatherapi.ErrInternal, false, "no-store"},
+		{"partial response", "01001000", weatherapi.ErrInternal, true, "no-store"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			viaCep, weather := healthyMocks()
+			weather.err = tt.weatherErr
+			app := newTestApplication(viaCep, weather)
+			app.cacheMaxAge = 2 * time.Minute
+			app.degradedMode = tt.degraded
+
+			rr := httptest.NewRecorder()
+			app.handler(rr, httptest.NewRequest(http.MethodGet, "/get-weather-by-cep?cep="+tt.cep, nil))
+
+			if got := rr.Header().Get("Cache-Control"); got != tt.expected {
+				t.Errorf("expected Cache-Control '%s', but got '%s'", tt.expected, got)
+			}
+
+			if tt.expected != "no-store" && !slices.Contains(rr.Header().Values("Vary"), "Accept") {
+				t.Errorf("expected Vary to contain 'Accept', but got %v", rr.Header().Values("Vary"))
+			}
+		})
+	}
+}
+
+func TestHandler_ETag(t *testing.T) {
+	viaCep, weather := healthyMocks()
+	app := newTestApplication(viaCep, weather)
+	app.etagEnabled = true
+
+	rr := httptest.NewRecorder()
+	app.handler(rr, httptest.NewRequest(http.MethodGet, "/get-weather-by-cep?cep=01001000", nil))
+	etag := rr.Header().Get("ETag")
+	if rr.Code != http.StatusOK || etag == "" {
+		t.Fatalf("expected status 200 with an ETag, but got %d and '%s'", rr.Code, etag)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/get-weather-by-cep?cep=01001000", nil)
+	req.Header.Set("If-None-Match", etag)
+	rr = httptest.NewRecorder()
+	app.handler(rr, req)
+	if rr.Code != http.StatusNotModified {
+		t.Fatalf("expected status 304, but got %d", rr.Code)
+	}
+	if rr.Body.Len() != 0 {
+		t.Errorf("expected an empty body, but got %q", rr.Body.String())
+	}
+	if got := rr.Header().Get("ETag"); got != etag {
+		t.Errorf("expected ETag '%s', but got '%s'", etag, got)
+	}
+	if got := rr.Header().Get("Cache-Control"); got != "public, max-age=300" {
+		t.Errorf("expected the max-age on the 304, but got '%s'", got)
+	}
+	if !slices.Contains(rr.Header().Values("Vary"), "Accept") {
+		t.Errorf("expected Vary to contain 'Accept' on the 304, but got %v", rr.Header().Values("Vary"))
+	}
+
+	// O clima mudou: o ETag antigo não vale mais
+	weather.weather.Current.TempC = 30
+	req = httptest.NewRequest(http.MethodGet, "/get-weather-by-cep?cep=01001000", nil)
+	req.Header.Set("If-None-Match", etag)
+	rr = httptest.NewRecorder()
+	app.handler(rr, req)
+	if rr.Code != http.StatusOK || rr.Header().Get("ETag") == etag {
+		t.Errorf("expected status 200 with a new ETag, but got %d and '%s'", rr.Code, rr.Header().Get("ETag"))
+	}
+}
+
+func TestHandler_NoETagByDefault(t *testing.T) {
+	viaCep, weather := healthyMocks()
+
+	rr := httptest.NewRecorder()
+	newTestApplication(viaCep, weather).handler(rr, httptest.NewRequest(http.MethodGet, "/get-weather-by-cep?cep=01001000", nil))
+
+	if got := rr.Header().Get("ETag"); got != "" {
+		t.Errorf("expected no ETag, but got '%s'", got)
+	}
+}
+
+func TestHandler_WeatherRateLimited(t *testing.T) {
+	tests := []struct {
+		name       string
+		retryAfter time.Duration
+		header     string
+	}{
+		{"with retry-after", 1500 * time.Millisecond, "2"},
+		{"without retry-after", 0, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			viaCep, weather := healthyMocks()
+			weather.err = &weatherapi.RateLimitError{RetryAfter: tt.retryAfter}
+
+			rr := httptest.NewRecorder()
+			newTestApplication(viaCep, weather).handler(rr, httptest.NewRequest(http.MethodGet, "/get-weather-by-cep?cep=01001000", nil))
+
+			if rr.Code != http.StatusServiceUnavailable {
+				t.Fatalf("expected status 503, but got %d", rr.Code)
+			}
+			if got := rr.Header().Get("Retry-After"); got != tt.header {
+				t.Errorf("expected Retry-After '%s', but got '%s'", tt.header, got)
+			}
+
+			var body errorResponse
+			if err := json.NewDecoder(rr.Body).Decode(&body); err != nil {
+				t.Fatalf("expected a JSON body, but got: %v", err)
+			}
+			if body.Code != codeRateLimited {
+				t.Errorf("expected code '%s', but got '%s'", codeRateLimited, body.Code)
+			}
+		})
+	}
+}
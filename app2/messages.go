@@ -0,0 +1,92 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Idiomas das mensagens de erro; sem Accept-Language (ou só com idiomas não suportados) vale o português
+const (
+	langPT = "pt-BR"
+	langEN = "en"
+
+	defaultLanguage = langPT
+)
+
+// Mensagem de erro nos idiomas suportados. O texto pode mudar com o idioma;
+// o código do errorResponse não muda, e é nele que os clientes devem se basear
+type message struct {
+	pt string
+	en string
+}
+
+// Catálogo das mensagens devolvidas nas respostas de erro (HTTP e gRPC)
+var (
+	msgMissingCep          = message{pt: "parâmetro 'cep' é obrigatório", en: "parameter 'cep' is required"}
+	msgInvalidZipcode      = message{pt: "CEP inválido", en: "invalid zipcode"}
+	msgInvalidForecastDays = message{pt: "forecast_days deve estar entre 1 e %d", en: "forecast_days must be between 1 and %d"}
+	msgInvalidDate         = message{pt: "date deve estar no formato AAAA-MM-DD, entre %s e hoje", en: "date must be in the YYYY-MM-DD format, between %s and today"}
+	msgDateWithForecast    = message{pt: "date e forecast_days não podem ser usados juntos", en: "date and forecast_days can not be used together"}
+	msgCepNotFound         = message{pt: "CEP não encontrado", en: "zipcode not found"}
+	msgCepWithoutCity      = message{pt: "CEP sem cidade associada", en: "zipcode has no associated city"}
+	msgInternal            = message{pt: "ocorreu um erro ao processar sua requisição", en: "an error occurred while processing your request"}
+	msgTimeout             = message{pt: "tempo esgotado ao consultar os serviços externos", en: "timed out while querying the external services"}
+	// Limite de requisições da WeatherAPI atingido
+	msgRateLimited = message{pt: "serviço de clima temporariamente indisponível, tente novamente mais tarde", en: "weather service temporarily unavailable, please try again later"}
+	// Limite de requisições simultâneas a uma API externa (UPSTREAM_CONCURRENCY_FAIL_FAST) ou ao próprio app2 atingido
+	msgOverloaded       = message{pt: "serviço sobrecarregado, tente novamente em instantes", en: "service overloaded, please try again shortly"}
+	msgMethodNotAllowed = message{pt: "método não permitido", en: "method not allowed"}
+	msgUnauthorized     = message{pt: "credencial administrativa ausente ou inválida", en: "missing or invalid admin credential"}
+	msgPanic            = message{pt: "erro interno do servidor", en: "internal server error"}
+)
+
+func (m message) in(lang string, args ...any) string {
+	text := m.pt
+	if lang == langEN {
+		text = m.en
+	}
+	if len(args) > 0 {
+		return fmt.Sprintf(text, args...)
+	}
+	return text
+}
+
+// Mensagem no idioma pedido pelo Accept-Language da requisição
+func localize(r *http.Request, m message, args ...any) string {
+	return m.in(negotiateLanguage(r.Header.Get("Accept-Language")), args...)
+}
+
+// Idioma suportado de maior peso no Accept-Language (pt-BR,pt;q=0.9,en;q=0.8); no empate, o primeiro.
+// Qualquer variante de pt ou en serve
+func negotiateLanguage(header string) string {
+	best, bestQ := defaultLanguage, 0.0
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+
+		primary, _, _ := strings.Cut(strings.ToLower(strings.TrimSpace(tag)), "-")
+		var lang string
+		switch primary {
+		case "pt":
+			lang = langPT
+		case "en":
+			lang = langEN
+		default:
+			continue
+		}
+
+		if q > bestQ {
+			best, bestQ = lang, q
+		}
+	}
+	return best
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"l02-02/viacep"
+	"l02-02/weatherpb"
+
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestNegotiateLanguage(t *testing.T) {
+	tests := []struct {
+		header string
+		want   string
+	}{
+		{"", langPT},
+		{"pt-BR", langPT},
+		{"en", langEN},
+		{"en-US,en;q=0.9", langEN},
+		{"pt-BR,pt;q=0.9,en;q=0.8", langPT},
+		{"pt;q=0.5, en;q=0.8", langEN},
+		{"fr-FR,en;q=0.5", langEN},
+		{"de, fr", langPT},
+		{"en;q=0", langPT},
+		{"EN-gb", langEN},
+		{"en;q=abc", langPT},
+	}
+
+	for _, tt := range tests {
+		if got := negotiateLanguage(tt.header); got != tt.want {
+			t.Errorf("header %q: expected '%s', but got '%s'", tt.header, tt.want, got)
+		}
+	}
+}
+
+func TestHandler_LocalizedErrors(t *testing.T) {
+	tests := []struct {
+		name           string
+		target         string
+		acceptLanguage string
+		viaCepErr      error
+		code           string
+		message        string
+	}{
+		{"default language", "/get-weather-by-cep?cep=123", "", nil, codeInvalidZipcode, "CEP inválido"},
+		{"portuguese", "/get-weather-by-cep", "pt-BR", nil, codeMissingCep, "parâmetro 'cep' é obrigatório"},
+		{"english", "/get-weather-by-cep?cep=123", "en-US,en;q=0.9", nil, codeInvalidZipcode, "invalid zipcode"},
+		{"english with arguments", "/get-weather-by-cep?cep=01001000&forecast_days=99", "en", nil, codeInvalidForecastDays, "forecast_days must be between 1 and 3"},
+		{"english upstream error", "/get-weather-by-cep?cep=01001000", "en", viacep.ErrCepNotFound, codeCepNotFound, "zipcode not found"},
+		{"unsupported language", "/get-weather-by-cep?cep=01001000", "fr", viacep.ErrCepNotFound, codeCepNotFound, "CEP não encontrado"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			viaCep, weather := healthyMocks()
+			if tt.viaCepErr != nil {
+				viaCep.address, viaCep.err = nil, tt.viaCepErr
+			}
+
+			req := httptest.NewRequest(http.MethodGet, tt.target, nil)
+			if tt.acceptLanguage != "" {
+				req.Header.Set("Accept-Language", tt.acceptLanguage)
+			}
+			rr := httptest.NewRecorder()
+			newTestApplication(viaCep, weather).handler(rr, req)
+
+			var body errorResponse
+			if err := json.NewDecoder(rr.Body).Decode(&body); err != nil {
+				t.Fatalf("expected a JSON error body, but got: %v", err)
+			}
+			// A mensagem muda com o idioma; o código, não
+			if body.Code != tt.code {
+				t.Errorf("expected code '%s', but got '%s'", tt.code, body.Code)
+			}
+			if body.Error != tt.message {
+				t.Errorf("expected message '%s', but got '%s'", tt.message, body.Error)
+			}
+		})
+	}
+}
+
+func TestGRPC_LocalizedErrors(t *testing.T) {
+	viaCep, weather := healthyMocks()
+	client := newTestGRPCClient(t, newTestApplication(viaCep, weather))
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "accept-language", "en")
+	_, err := client.GetWeatherByCep(ctx, &weatherpb.GetWeatherByCepRequest{Cep: "123"})
+	if got := status.Convert(err).Message(); got != "invalid zipcode" {
+		t.Errorf("expected message 'invalid zipcode', but got '%s'", got)
+	}
+
+	_, err = client.GetWeatherByCep(context.Background(), &weatherpb.GetWeatherByCepRequest{Cep: "123"})
+	if got := status.Convert(err).Message(); got != "CEP inválido" {
+		t.Errorf("expected message 'CEP inválido', but got '%s'", got)
+	}
+}
@@ -0,0 +1,200 @@
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/noop"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+)
+
+// Resultados da consulta de CEP, rótulo outcome de cep_lookups_total
+const (
+	CepFound    = "found"
+	CepNotFound = "not_found"
+	CepInvalid  = "invalid"
+	CepError    = "error"
+)
+
+// Registro próprio (em vez do global) para que cada instância da aplicação tenha suas métricas
+type Metrics struct {
+	registry         *prometheus.Registry
+	requestsTotal    *prometheus.CounterVec
+	requestDuration  *prometheus.HistogramVec
+	outboundDuration *prometheus.HistogramVec
+	outboundTotal    *prometheus.CounterVec
+	outboundErrors   *prometheus.CounterVec
+	// Só o resultado como rótulo: o CEP bruto tornaria a cardinalidade ilimitada
+	cepLookups *prometheus.CounterVec
+	// Caches em memória, rotulados pelo nome do cache: por ora só viacep e viacep_not_found,
+	// já que o clima não tem cache em memória e não gera essas séries
+	cacheHits      *prometheus.CounterVec
+	cacheMisses    *prometheus.CounterVec
+	cacheEvictions *prometheus.CounterVec
+	cacheSize      *prometheus.GaugeVec
+	// Espelho das métricas de requisição no OpenTelemetry (noop sem WithMeter)
+	otelRequests metric.Int64Counter
+	otelDuration metric.Float64Histogram
+	otelCep      metric.Int64Counter
+}
+
+type Option func(*Metrics)
+
+// Registra também a contagem e a duração das requisições no Meter informado
+func WithMeter(meter metric.Meter) Option {
+	return func(m *Metrics) {
+		m.useMeter(meter)
+	}
+}
+
+func New(opts ...Option) *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		requestsTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "http_requests_total",
+			Help: "Total de requisições HTTP recebidas.",
+		}, []string{"route", "status"}),
+		requestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "http_request_duration_seconds",
+			Help:    "Duração das requisições HTTP recebidas.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"route", "status"}),
+		outboundDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "outbound_request_duration_seconds",
+			Help:    "Duração das chamadas às APIs externas.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"upstream"}),
+		outboundTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "outbound_requests_total",
+			Help: "Total de chamadas às APIs externas.",
+		}, []string{"upstream", "status_class"}),
+		outboundErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "outbound_errors_total",
+			Help: "Chamadas às APIs externas com erro de rede ou status 5xx.",
+		}, []string{"upstream", "status_class"}),
+		cepLookups: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "cep_lookups_total",
+			Help: "Total de consultas de CEP por resultado.",
+		}, []string{"outcome"}),
+		cacheHits: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "cache_hits_total",
+			Help: "Consultas atendidas pelo cache em memória.",
+		}, []string{"cache"}),
+		cacheMisses: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "cache_misses_total",
+			Help: "Consultas não encontradas (ou expiradas) no cache em memória.",
+		}, []string{"cache"}),
+		cacheEvictions: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "cache_evictions_total",
+			Help: "Entradas removidas do cache em memória por expiração ou por falta de espaço.",
+		}, []string{"cache"}),
+		cacheSize: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "cache_size",
+			Help: "Entradas atualmente no cache em memória.",
+		}, []string{"cache"}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.requestsTotal,
+		m.requestDuration,
+		m.outboundDuration,
+		m.outboundTotal,
+		m.outboundErrors,
+		m.cepLookups,
+		m.cacheHits,
+		m.cacheMisses,
+		m.cacheEvictions,
+		m.cacheSize,
+	)
+
+	m.useMeter(noop.NewMeterProvider().Meter(""))
+	for _, opt := range opts {
+		opt(m)
+	}
+
+	return m
+}
+
+// Nomes próprios para não se confundir com as métricas http.server.* do otelhttp
+func (m *Metrics) useMeter(meter metric.Meter) {
+	var err error
+	m.otelRequests, err = meter.Int64Counter("app.request.count",
+		metric.WithDescription("Total de requisições HTTP recebidas."),
+		metric.WithUnit("{request}"),
+	)
+	if err != nil {
+		otel.Handle(err)
+	}
+
+	m.otelDuration, err = meter.Float64Histogram("app.request.duration",
+		metric.WithDescription("Duração das requisições HTTP recebidas."),
+		metric.WithUnit("s"),
+	)
+	if err != nil {
+		otel.Handle(err)
+	}
+
+	m.otelCep, err = meter.Int64Counter("app.cep.lookup.count",
+		metric.WithDescription("Total de consultas de CEP por resultado."),
+		metric.WithUnit("{lookup}"),
+	)
+	if err != nil {
+		otel.Handle(err)
+	}
+}
+
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+func (m *Metrics) ObserveRequest(route string, status int, duration time.Duration) {
+	labels := prometheus.Labels{"route": route, "status": strconv.Itoa(status)}
+	m.requestsTotal.With(labels).Inc()
+	m.requestDuration.With(labels).Observe(duration.Seconds())
+
+	attrs := metric.WithAttributes(semconv.HTTPRoute(route), semconv.HTTPResponseStatusCode(status))
+	m.otelRequests.Add(context.Background(), 1, attrs)
+	m.otelDuration.Record(context.Background(), duration.Seconds(), attrs)
+}
+
+// statusClass vem de httpx.StatusClass: "2xx", "4xx", "5xx"... ou "error" sem resposta
+func (m *Metrics) ObserveOutbound(upstream, statusClass string, duration time.Duration) {
+	m.outboundDuration.WithLabelValues(upstream).Observe(duration.Seconds())
+	m.outboundTotal.WithLabelValues(upstream, statusClass).Inc()
+	if statusClass == "5xx" || statusClass == "error" {
+		m.outboundErrors.WithLabelValues(upstream, statusClass).Inc()
+	}
+}
+
+// outcome deve ser uma das constantes Cep*
+func (m *Metrics) ObserveCepLookup(outcome string) {
+	m.cepLookups.WithLabelValues(outcome).Inc()
+	m.otelCep.Add(context.Background(), 1, metric.WithAttributes(attribute.String("outcome", outcome)))
+}
+
+// A taxa de acerto sai de cache_hits_total / (cache_hits_total + cache_misses_total)
+func (m *Metrics) ObserveCacheLookup(cache string, hit bool) {
+	if hit {
+		m.cacheHits.WithLabelValues(cache).Inc()
+		return
+	}
+	m.cacheMisses.WithLabelValues(cache).Inc()
+}
+
+func (m *Metrics) ObserveCacheEvictions(cache string, n int) {
+	m.cacheEvictions.WithLabelValues(cache).Add(float64(n))
+}
+
+func (m *Metrics) SetCacheSize(cache string, size int) {
+	m.cacheSize.WithLabelValues(cache).Set(float64(size))
+}
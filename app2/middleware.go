@@ -0,0 +1,128 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Aplica os middlewares na ordem em que aparecem: o primeiro é o mais externo e vê a requisição antes dos demais
+func chain(h http.Handler, mws ...func(http.Handler) http.Handler) http.Handler {
+	for i := len(mws) - 1; i >= 0; i-- {
+		h = mws[i](h)
+	}
+	return h
+}
+
+// Adaptadores dos middlewares com parâmetros para o formato do chain
+func (app *application) instrumentRoute(route string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler { return app.instrument(route, next) }
+}
+
+func methods(allowed ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler { return allowMethods(next, allowed...) }
+}
+
+func otelServer(operation string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler { return otelhttp.NewHandler(next, operation) }
+}
+
+// Captura o status e o tamanho do corpo escritos pelo handler
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+	bytes  int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	n, err := r.ResponseWriter.Write(b)
+	r.bytes += n
+	return n, err
+}
+
+// Registra contagem e duração das requisições por rota e status
+func (app *application) instrument(route string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+		app.metrics.ObserveRequest(route, rec.status, time.Since(start))
+	})
+}
+
+type contextKey string
+
+const (
+	requestIDHeader = "X-Request-ID"
+	requestIDKey    = contextKey("request_id")
+	// IDs recebidos maiores que isso (ou com caracteres não imprimíveis) são substituídos
+	maxRequestIDLength = 128
+)
+
+// Reaproveita o X-Request-ID recebido ou gera um novo, devolvendo-o no cabeçalho da resposta
+func (app *application) requestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(requestIDHeader)
+		if !validRequestID(id) {
+			id = uuid.NewString()
+		}
+
+		w.Header().Set(requestIDHeader, id)
+		ctx := context.WithValue(r.Context(), requestIDKey, id)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+func requestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey).(string)
+	return id
+}
+
+const traceIDHeader = "X-Trace-Id"
+
+// Devolve o trace ID do span ativo para o usuário informar no suporte; precisa ficar dentro do otelhttp.
+// Sem span amostrado o trace não chega ao Jaeger, então o cabeçalho é omitido
+func traceID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if sc := trace.SpanContextFromContext(r.Context()); sc.IsSampled() {
+			w.Header().Set(traceIDHeader, sc.TraceID().String())
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, c := range id {
+		if c < 0x21 || c > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+// Responde 405 com o cabeçalho Allow para métodos fora da lista
+func allowMethods(next http.Handler, methods ...string) http.Handler {
+	allow := strings.Join(methods, ", ")
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !slices.Contains(methods, r.Method) {
+			w.Header().Set("Allow", allow)
+			writeJSONError(w, http.StatusMethodNotAllowed, codeMethodNotAllowed, localize(r, msgMethodNotAllowed))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
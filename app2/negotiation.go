@@ -0,0 +1,108 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Escolhe XML apenas quando o cliente pede application/xml explicitamente; */* e afins continuam em JSON
+func prefersXML(accept string) bool {
+	xmlQ, jsonQ := -1.0, -1.0
+	xmlIdx, jsonIdx := 0, 0
+	for i, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+
+		q := 1.0
+		if v, ok := params["q"]; ok {
+			if parsed, err := strconv.ParseFloat(v, 64); err == nil {
+				q = parsed
+			}
+		}
+
+		switch mediaType {
+		case "application/xml":
+			if q > xmlQ {
+				xmlQ, xmlIdx = q, i
+			}
+		case "application/json":
+			if q > jsonQ {
+				jsonQ, jsonIdx = q, i
+			}
+		}
+	}
+
+	if xmlQ <= 0 {
+		return false
+	}
+	if jsonQ < 0 {
+		return true
+	}
+	return xmlQ > jsonQ || (xmlQ == jsonQ && xmlIdx < jsonIdx)
+}
+
+// Respostas de clima de sucesso podem ser reaproveitadas por clientes e CDNs durante maxAge.
+// O corpo é JSON ou XML conforme o Accept, que por isso entra no Vary
+func setCacheControl(w http.ResponseWriter, maxAge time.Duration) {
+	w.Header().Set("Cache-Control", "public, max-age="+strconv.Itoa(int(maxAge.Seconds())))
+	w.Header().Add("Vary", "Accept")
+}
+
+func writeResponse(w http.ResponseWriter, r *http.Request, status int, v any) {
+	contentType, body := encodeResponse(r, v)
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(status)
+	w.Write(body)
+}
+
+// Como o writeResponse com 200, mas com um ETag derivado da chave (o CEP) e do corpo;
+// se o If-None-Match trouxer o mesmo ETag, responde 304 sem corpo
+func writeResponseWithETag(w http.ResponseWriter, r *http.Request, key string, v any) {
+	contentType, body := encodeResponse(r, v)
+	sum := sha256.Sum256(append([]byte(key+"\n"), body...))
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+
+	w.Header().Set("ETag", etag)
+	if etagMatches(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(http.StatusOK)
+	w.Write(body)
+}
+
+// Corpo no formato pedido pelo Accept: XML só quando pedido explicitamente
+func encodeResponse(r *http.Request, v any) (string, []byte) {
+	var buf bytes.Buffer
+	if prefersXML(r.Header.Get("Accept")) {
+		buf.WriteString(xml.Header)
+		xml.NewEncoder(&buf).Encode(v)
+		return "application/xml", buf.Bytes()
+	}
+
+	json.NewEncoder(&buf).Encode(v)
+	return "application/json", buf.Bytes()
+}
+
+// Comparação fraca do If-None-Match (RFC 9110): ignora o prefixo W/ e aceita "*"
+func etagMatches(ifNoneMatch, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestPprofHandler(t *testing.T) {
+	viaCep, weather := healthyMocks()
+	server := httptest.NewServer(newTestApplication(viaCep, weather).adminHandler(true))
+	defer server.Close()
+
+	for _, path := range []string{"/debug/pprof/", "/debug/pprof/goroutine?debug=1", "/debug/pprof/cmdline", "/debug/pprof/profile?seconds=1"} {
+		t.Run(path, func(t *testing.T) {
+			resp, err := http.Get(server.URL + path)
+			if err != nil {
+				t.Fatalf("expected no error, but got: %v", err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != http.StatusOK {
+				t.Errorf("expected status 200, but got %d", resp.StatusCode)
+			}
+		})
+	}
+}
+
+func TestRoutes_NoPprof(t *testing.T) {
+	viaCep, weather := healthyMocks()
+	rec := httptest.NewRecorder()
+	newTestApplication(viaCep, weather).routes().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/pprof/", nil))
+
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected status 404 on the service port, but got %d", rec.Code)
+	}
+}
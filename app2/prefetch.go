@@ -0,0 +1,45 @@
+package main
+
+import (
+	"context"
+	"sync"
+
+	"l02-02/cep"
+)
+
+// Consultas simultâneas do aquecimento do cache, para não disputar as vagas das requisições reais
+const defaultPrefetchConcurrency = 4
+
+// Resolve os CEPs pelo viaCepClient para popular o cache de endereços antes das primeiras
+// requisições. Falhas só vão para o log; retorna quando todos os CEPs foram consultados
+func (app *application) prefetch(ctx context.Context, ceps []string, concurrency int) {
+	if concurrency <= 0 {
+		concurrency = defaultPrefetchConcurrency
+	}
+
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for _, raw := range ceps {
+		zipcode, err := cep.NormalizeCEP(raw)
+		if err != nil {
+			app.logger.WarnContext(ctx, "skipping invalid CEP in prefetch", "cep", raw)
+			continue
+		}
+
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			lookupCtx, cancel := context.WithTimeout(ctx, app.requestTimeout)
+			defer cancel()
+			if _, err := app.viaCepClient.FindAddressByCep(lookupCtx, zipcode); err != nil {
+				app.logger.WarnContext(ctx, "prefetch failed", "cep", zipcode, "error", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	app.logger.InfoContext(ctx, "prefetch finished", "ceps", len(ceps))
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"l02-02/viacep"
+
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+func TestPrefetch_PopulatesCache(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		if r.URL.Path != "/ws/01001-000/json/" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Write([]byte(`{"cep": "01001-000", "localidade": "São Paulo"}`))
+	}))
+	defer server.Close()
+
+	client := viacep.NewClient(slog.New(slog.DiscardHandler), noop.NewTracerProvider().Tracer("test"),
+		viacep.WithBaseURL(server.URL),
+		viacep.WithCache(time.Minute, 10),
+	)
+	_, weather := healthyMocks()
+	app := newTestApplication(client, weather)
+
+	// CEP inválido é ignorado e a falha do outro não interrompe o aquecimento
+	app.prefetch(context.Background(), []string{"01001000", "abc", "02002-000"}, 2)
+	if got := calls.Load(); got != 2 {
+		t.Fatalf("expected 2 lookups during prefetch, but got %d", got)
+	}
+
+	address, err := client.FindAddressByCep(context.Background(), "01001-000")
+	if err != nil || address.City != "São Paulo" {
+		t.Fatalf("expected the cached address, but got %v, %v", address, err)
+	}
+	if got := calls.Load(); got != 2 {
+		t.Errorf("expected the lookup to be served from the cache, but ViaCEP got %d calls", got)
+	}
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"runtime/debug"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Middleware mais externo: um panic em qualquer handler vira 500 em vez de derrubar o processo
+func (app *application) recoverPanics(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			rec := recover()
+			if rec == nil {
+				return
+			}
+			// Convenção do net/http para abortar a resposta de propósito
+			if rec == http.ErrAbortHandler {
+				panic(rec)
+			}
+
+			err := fmt.Errorf("panic: %v", rec)
+			// O requestID roda por dentro, mas já devolveu o ID no cabeçalho da resposta
+			requestID := w.Header().Get(requestIDHeader)
+			if requestID == "" {
+				requestID = r.Header.Get(requestIDHeader)
+			}
+			app.logger.Error("panic recovered",
+				"request_id", requestID,
+				"method", r.Method,
+				"url", redactURL(r.URL.RequestURI()),
+				"error", err,
+				"stack", string(debug.Stack()),
+			)
+
+			// Por ser o mais externo, normalmente não há span ativo (os dos handlers já foram encerrados);
+			// nesse caso registra em um span ligado ao trace de origem
+			span := trace.SpanFromContext(r.Context())
+			if !span.SpanContext().IsValid() {
+				ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+				_, span = app.tracer.Start(ctx, "panic", trace.WithSpanKind(trace.SpanKindServer))
+				defer span.End()
+			}
+			span.RecordError(err, trace.WithStackTrace(true))
+			span.SetStatus(codes.Error, "panic recovered")
+
+			writeJSONError(w, http.StatusInternalServerError, codeInternalError, localize(r, msgPanic))
+		}()
+
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRecoverPanics(t *testing.T) {
+	var logs strings.Builder
+	app := newTestApplication(healthyMocks())
+	app.logger = newLogger(&logs, slog.LevelInfo)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/panic", func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	})
+	mux.HandleFunc("/ok", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	server := httptest.NewServer(app.recoverPanics(app.requestID(mux)))
+	defer server.Close()
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL+"/panic", nil)
+	req.Header.Set(requestIDHeader, "panic-req-1")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("expected a response, but got: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusInternalServerError {
+		t.Fatalf("expected status 500, but got %d", resp.StatusCode)
+	}
+
+	var body errorResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("expected a JSON body, but got: %v", err)
+	}
+	if body.Code != codeInternalError {
+		t.Errorf("expected code '%s', but got '%s'", codeInternalError, body.Code)
+	}
+
+	for _, want := range []string{"panic recovered", "panic-req-1", "boom", "stack"} {
+		if !strings.Contains(logs.String(), want) {
+			t.Errorf("expected logs to contain '%s', but got: %s", want, logs.String())
+		}
+	}
+
+	// O servidor continua de pé depois do panic
+	resp, err = http.Get(server.URL + "/ok")
+	if err != nil {
+		t.Fatalf("expected the server to keep serving, but got: %v", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected status 200, but got %d", resp.StatusCode)
+	}
+}
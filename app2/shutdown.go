@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// Subconjunto do *http.Server usado no desligamento
+type drainer interface {
+	Shutdown(ctx context.Context) error
+}
+
+// Drena vários servidores (HTTP e gRPC) em paralelo, com o mesmo prazo
+type drainers []drainer
+
+func (ds drainers) Shutdown(ctx context.Context) error {
+	errs := make([]error, len(ds))
+	var wg sync.WaitGroup
+	for i, d := range ds {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = d.Shutdown(ctx)
+		}()
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+// Desliga na ordem: para de aceitar conexões e drena as requisições em andamento (até drainTimeout)
+// e só então descarrega a telemetria, para que os spans gerados durante a drenagem sejam exportados
+func gracefulShutdown(logger *slog.Logger, server drainer, shutdownTelemetry func(context.Context) error, drainTimeout, telemetryTimeout time.Duration) error {
+	logger.Info("shutting down server", "drain_timeout", drainTimeout.String())
+	drainCtx, cancelDrain := context.WithTimeout(context.Background(), drainTimeout)
+	defer cancelDrain()
+
+	serverErr := server.Shutdown(drainCtx)
+	if serverErr != nil {
+		logger.Error("failed to shut down server gracefully", "error", serverErr)
+	} else {
+		logger.Info("server shut down")
+	}
+
+	// Prazo próprio: a drenagem pode ter consumido todo o drainTimeout
+	logger.Info("shutting down telemetry")
+	telemetryCtx, cancelTelemetry := context.WithTimeout(context.Background(), telemetryTimeout)
+	defer cancelTelemetry()
+
+	telemetryErr := shutdownTelemetry(telemetryCtx)
+	if telemetryErr != nil {
+		logger.Error("failed to shutdown telemetry gracefully", "error", telemetryErr)
+	} else {
+		logger.Info("telemetry shut down")
+	}
+
+	return errors.Join(serverErr, telemetryErr)
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+type fakeDrainer struct {
+	shutdown func(ctx context.Context) error
+}
+
+func (f *fakeDrainer) Shutdown(ctx context.Context) error {
+	return f.shutdown(ctx)
+}
+
+// Ao contrário do tracetest.InMemoryExporter, mantém os spans após o Shutdown
+type recordingExporter struct {
+	mu    sync.Mutex
+	names []string
+}
+
+func (e *recordingExporter) ExportSpans(ctx context.Context, spans []sdktrace.ReadOnlySpan) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for _, s := range spans {
+		e.names = append(e.names, s.Name())
+	}
+	return nil
+}
+
+func (e *recordingExporter) Shutdown(ctx context.Context) error {
+	return nil
+}
+
+func TestGracefulShutdown_DrainsBeforeTelemetry(t *testing.T) {
+	var steps []string
+	server := &fakeDrainer{shutdown: func(ctx context.Context) error {
+		steps = append(steps, "server")
+		return nil
+	}}
+	telemetry := func(ctx context.Context) error {
+		steps = append(steps, "telemetry")
+		return nil
+	}
+
+	if err := gracefulShutdown(slog.New(slog.DiscardHandler), server, telemetry, time.Second, time.Second); err != nil {
+		t.Fatalf("expected no error, but got: %v", err)
+	}
+
+	if len(steps) != 2 || steps[0] != "server" || steps[1] != "telemetry" {
+		t.Errorf("expected [server telemetry], but got %v", steps)
+	}
+}
+
+func TestGracefulShutdown_TelemetryGetsOwnTimeout(t *testing.T) {
+	errDrain := errors.New("drain timed out")
+	server := &fakeDrainer{shutdown: func(ctx context.Context) error {
+		<-ctx.Done()
+		return errDrain
+	}}
+
+	var telemetryCtxErr error
+	telemetry := func(ctx context.Context) error {
+		telemetryCtxErr = ctx.Err()
+		return nil
+	}
+
+	err := gracefulShutdown(slog.New(slog.DiscardHandler), server, telemetry, 10*time.Millisecond, time.Second)
+	if !errors.Is(err, errDrain) {
+		t.Errorf("expected error '%v', but got '%v'", errDrain, err)
+	}
+
+	if telemetryCtxErr != nil {
+		t.Errorf("expected telemetry to run with a live context, but got: %v", telemetryCtxErr)
+	}
+}
+
+func TestGracefulShutdown_ExportsSpansFromDrain(t *testing.T) {
+	exporter := &recordingExporter{}
+	tp := sdktrace.NewTracerProvider(sdktrace.WithBatcher(exporter, sdktrace.WithBatchTimeout(time.Hour)))
+
+	// Simula uma requisição terminando durante a drenagem
+	server := &fakeDrainer{shutdown: func(ctx context.Context) error {
+		_, span := tp.Tracer("test").Start(ctx, "in-flight request")
+		span.End()
+		return nil
+	}}
+
+	if err := gracefulShutdown(slog.New(slog.DiscardHandler), server, tp.Shutdown, time.Second, time.Second); err != nil {
+		t.Fatalf("expected no error, but got: %v", err)
+	}
+
+	if len(exporter.names) != 1 || exporter.names[0] != "in-flight request" {
+		t.Errorf("expected the span created during drain to be exported, but got %v", exporter.names)
+	}
+}
+
+func TestDrainers_ShutsDownAll(t *testing.T) {
+	errBoom := errors.New("boom")
+	var mu sync.Mutex
+	var calls int
+	newDrainer := func(err error) drainer {
+		return &fakeDrainer{shutdown: func(ctx context.Context) error {
+			mu.Lock()
+			defer mu.Unlock()
+			calls++
+			return err
+		}}
+	}
+
+	err := drainers{newDrainer(nil), newDrainer(errBoom)}.Shutdown(context.Background())
+	if calls != 2 {
+		t.Errorf("expected both drainers to be shut down, but got %d calls", calls)
+	}
+	if !errors.Is(err, errBoom) {
+		t.Errorf("expected joined error to contain %v, but got %v", errBoom, err)
+	}
+}
@@ -0,0 +1,448 @@
+package telemetry
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"l02-02/version"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/baggage"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	tracesdk "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+)
+
+func TestParseProtocol(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+		ok       bool
+	}{
+		{"", protocolHTTP, true},
+		{"http/protobuf", protocolHTTP, true},
+		{"grpc", protocolGRPC, true},
+		{" GRPC ", protocolGRPC, true},
+		{"http/json", protocolHTTP, false},
+	}
+
+	for _, tt := range tests {
+		got, ok := parseProtocol(tt.input)
+		if got != tt.expected || ok != tt.ok {
+			t.Errorf("parseProtocol(%q): expected (%s, %v), but got (%s, %v)", tt.input, tt.expected, tt.ok, got, ok)
+		}
+	}
+}
+
+func TestNewTraceClient_SelectsExporter(t *testing.T) {
+	tests := []struct {
+		protocol string
+		pkg      string
+	}{
+		{protocolHTTP, "otlptracehttp"},
+		{protocolGRPC, "otlptracegrpc"},
+	}
+
+	for _, tt := range tests {
+		client := newTraceClient(exporterConfig{protocol: tt.protocol, insecure: true})
+		if got := reflect.TypeOf(client).Elem().PkgPath(); !strings.HasSuffix(got, "/"+tt.pkg) {
+			t.Errorf("protocol %s: expected client from %s, but got %s", tt.protocol, tt.pkg, got)
+		}
+	}
+}
+
+func TestNewSampler(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+		ok       bool
+	}{
+		{"", "AlwaysOnSampler", true},
+		{"0.25", "TraceIDRatioBased{0.25}", true},
+		{"1", "AlwaysOnSampler", true},
+		{"1.5", "AlwaysOnSampler", true},
+		{"-0.3", "TraceIDRatioBased{0}", true},
+		{"abc", "AlwaysOnSampler", false},
+	}
+
+	for _, tt := range tests {
+		sampler, ok := newSampler(tt.input)
+		desc := sampler.Description()
+		if ok != tt.ok || !strings.HasPrefix(desc, "ParentBased{root:"+tt.expected) {
+			t.Errorf("newSampler(%q): expected root %s (ok=%v), but got %s (ok=%v)", tt.input, tt.expected, tt.ok, desc, ok)
+		}
+	}
+}
+
+func TestParseInsecure(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected bool
+		ok       bool
+	}{
+		{"", true, true},
+		{"true", true, true},
+		{"false", false, true},
+		{"0", false, true},
+		{"maybe", true, false},
+	}
+
+	for _, tt := range tests {
+		got, ok := parseInsecure(tt.input)
+		if got != tt.expected || ok != tt.ok {
+			t.Errorf("parseInsecure(%q): expected (%v, %v), but got (%v, %v)", tt.input, tt.expected, tt.ok, got, ok)
+		}
+	}
+}
+
+func TestParseHeaders(t *testing.T) {
+	headers, ok := parseHeaders("Authorization=Bearer%20abc, x-tenant = l02 ,")
+	if !ok {
+		t.Fatal("expected headers to be valid")
+	}
+
+	if headers["Authorization"] != "Bearer abc" || headers["x-tenant"] != "l02" || len(headers) != 2 {
+		t.Errorf("unexpected headers: %v", headers)
+	}
+
+	headers, ok = parseHeaders("broken,api-key=123")
+	if ok {
+		t.Error("expected malformed entry to be reported")
+	}
+
+	if headers["api-key"] != "123" || len(headers) != 1 {
+		t.Errorf("expected valid entries to be kept, but got: %v", headers)
+	}
+}
+
+func TestNewTraceClient_SendsHeaders(t *testing.T) {
+	var gotAuth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = r.Header.Get("Authorization")
+	}))
+	defer server.Close()
+
+	client := newTraceClient(exporterConfig{
+		protocol: protocolHTTP,
+		endpoint: strings.TrimPrefix(server.URL, "http://"),
+		insecure: true,
+		headers:  map[string]string{"Authorization": "Bearer abc"},
+	})
+	ctx := context.Background()
+	if err := client.Start(ctx); err != nil {
+		t.Fatalf("expected no error, but got: %v", err)
+	}
+	defer client.Stop(ctx)
+
+	if err := client.UploadTraces(ctx, nil); err != nil {
+		t.Fatalf("expected no error, but got: %v", err)
+	}
+
+	if gotAuth != "Bearer abc" {
+		t.Errorf("expected Authorization 'Bearer abc', but got '%s'", gotAuth)
+	}
+}
+
+func TestNewTraceClient_SecureUsesTLS(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("expected plaintext server not to receive a valid request")
+	}))
+	defer server.Close()
+
+	client := newTraceClient(exporterConfig{
+		protocol: protocolHTTP,
+		endpoint: strings.TrimPrefix(server.URL, "http://"),
+		insecure: false,
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	client.Start(ctx)
+	defer client.Stop(ctx)
+
+	if err := client.UploadTraces(ctx, nil); err == nil {
+		t.Error("expected TLS handshake against plaintext server to fail")
+	}
+}
+
+func TestNewResource(t *testing.T) {
+	original := version.Get()
+	t.Cleanup(func() {
+		version.Version, version.Commit, version.BuildTime = original.Version, original.Commit, original.BuildTime
+	})
+
+	tests := []struct {
+		name        string
+		info        version.Info
+		environment string
+		expected    map[attribute.Key]string
+		absent      []attribute.Key
+	}{
+		{
+			name:        "all values",
+			info:        version.Info{Version: "1.2.3", Commit: "abc123", BuildTime: "2026-10-14T12:00:00Z"},
+			environment: "staging",
+			expected: map[attribute.Key]string{
+				semconv.ServiceNameKey:           "svc",
+				"application.origin":             "app2",
+				semconv.ServiceVersionKey:        "1.2.3",
+				"build.commit":                   "abc123",
+				"build.time":                     "2026-10-14T12:00:00Z",
+				semconv.DeploymentEnvironmentKey: "staging",
+			},
+		},
+		{
+			name:     "empty values are omitted",
+			info:     version.Info{},
+			expected: map[attribute.Key]string{semconv.ServiceNameKey: "svc", "application.origin": "app2"},
+			absent:   []attribute.Key{semconv.ServiceVersionKey, "build.commit", "build.time", semconv.DeploymentEnvironmentKey},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			version.Version, version.Commit, version.BuildTime = tt.info.Version, tt.info.Commit, tt.info.BuildTime
+			set := newResource("svc", tt.environment).Set()
+
+			for key, want := range tt.expected {
+				if got, ok := set.Value(key); !ok || got.AsString() != want {
+					t.Errorf("expected %s=%s, but got %s (present: %v)", key, want, got.AsString(), ok)
+				}
+			}
+
+			for _, key := range tt.absent {
+				if _, ok := set.Value(key); ok {
+					t.Errorf("expected %s to be omitted", key)
+				}
+			}
+		})
+	}
+}
+
+func TestParseResourceAttributes(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []attribute.KeyValue
+		ok       bool
+	}{
+		{"", []attribute.KeyValue{}, true},
+		{"team=weather", []attribute.KeyValue{attribute.String("team", "weather")}, true},
+		{" zone = sa-east-1 , team=weather", []attribute.KeyValue{attribute.String("team", "weather"), attribute.String("zone", "sa-east-1")}, true},
+		{"owner=time%20clima", []attribute.KeyValue{attribute.String("owner", "time clima")}, true},
+		{"team=weather,invalid,=empty", []attribute.KeyValue{attribute.String("team", "weather")}, false},
+	}
+
+	for _, tt := range tests {
+		got, ok := parseResourceAttributes(tt.input)
+		if ok != tt.ok || !reflect.DeepEqual(got, tt.expected) {
+			t.Errorf("parseResourceAttributes(%q): expected (%v, %v), but got (%v, %v)", tt.input, tt.expected, tt.ok, got, ok)
+		}
+	}
+}
+
+func TestResolveServiceName(t *testing.T) {
+	fromAttrs := []attribute.KeyValue{semconv.ServiceNameKey.String("from-attributes")}
+
+	tests := []struct {
+		name     string
+		envName  string
+		attrs    []attribute.KeyValue
+		expected string
+	}{
+		{"fallback", "", nil, "svc"},
+		{"resource attributes", "", fromAttrs, "from-attributes"},
+		{"OTEL_SERVICE_NAME wins", " from-env ", fromAttrs, "from-env"},
+		{"empty attribute ignored", "", []attribute.KeyValue{semconv.ServiceNameKey.String("")}, "svc"},
+	}
+
+	for _, tt := range tests {
+		if got := resolveServiceName("svc", tt.envName, tt.attrs); got != tt.expected {
+			t.Errorf("%s: expected %s, but got %s", tt.name, tt.expected, got)
+		}
+	}
+}
+
+func TestNewResource_ExtraAttributes(t *testing.T) {
+	set := newResource("svc", "staging",
+		attribute.String("team", "weather"),
+		semconv.DeploymentEnvironmentKey.String("production"),
+		semconv.ServiceNameKey.String("ignored"),
+	).Set()
+
+	expected := map[attribute.Key]string{
+		"team":                           "weather",
+		semconv.DeploymentEnvironmentKey: "production",
+		semconv.ServiceNameKey:           "svc",
+		"application.origin":             "app2",
+	}
+	for key, want := range expected {
+		if got, ok := set.Value(key); !ok || got.AsString() != want {
+			t.Errorf("expected %s=%s, but got %s (present: %v)", key, want, got.AsString(), ok)
+		}
+	}
+}
+
+func TestParseMetricsExporter(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected bool
+		ok       bool
+	}{
+		{"", false, true},
+		{"none", false, true},
+		{" OTLP ", true, true},
+		{"prometheus", false, false},
+	}
+
+	for _, tt := range tests {
+		got, ok := parseMetricsExporter(tt.input)
+		if got != tt.expected || ok != tt.ok {
+			t.Errorf("parseMetricsExporter(%q): expected (%v, %v), but got (%v, %v)", tt.input, tt.expected, tt.ok, got, ok)
+		}
+	}
+}
+
+func TestMeterProvider_ExportsOnShutdown(t *testing.T) {
+	var gotPath, gotAuth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		gotAuth = r.Header.Get("Authorization")
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	exporter, err := newMetricExporter(ctx, exporterConfig{
+		protocol: protocolHTTP,
+		endpoint: strings.TrimPrefix(server.URL, "http://"),
+		insecure: true,
+		headers:  map[string]string{"Authorization": "Bearer abc"},
+	})
+	if err != nil {
+		t.Fatalf("expected no error, but got: %v", err)
+	}
+
+	mp := newMeterProvider(newResource("svc", ""), sdkmetric.NewPeriodicReader(exporter))
+	counter, err := mp.Meter("test").Int64Counter("test.count")
+	if err != nil {
+		t.Fatalf("expected no error, but got: %v", err)
+	}
+	counter.Add(ctx, 1)
+
+	// O shutdown descarrega o que ainda não foi exportado
+	if err := mp.Shutdown(ctx); err != nil {
+		t.Fatalf("expected no error, but got: %v", err)
+	}
+
+	if gotPath != "/v1/metrics" {
+		t.Errorf("expected export to /v1/metrics, but got '%s'", gotPath)
+	}
+	if gotAuth != "Bearer abc" {
+		t.Errorf("expected Authorization 'Bearer abc', but got '%s'", gotAuth)
+	}
+}
+
+func TestNewMetricExporter_SelectsExporter(t *testing.T) {
+	tests := []struct {
+		protocol string
+		pkg      string
+	}{
+		{protocolHTTP, "otlpmetrichttp"},
+		{protocolGRPC, "otlpmetricgrpc"},
+	}
+
+	for _, tt := range tests {
+		exporter, err := newMetricExporter(context.Background(), exporterConfig{protocol: tt.protocol, insecure: true})
+		if err != nil {
+			t.Fatalf("protocol %s: expected no error, but got: %v", tt.protocol, err)
+		}
+		if got := reflect.TypeOf(exporter).Elem().PkgPath(); !strings.HasSuffix(got, "/"+tt.pkg) {
+			t.Errorf("protocol %s: expected exporter from %s, but got %s", tt.protocol, tt.pkg, got)
+		}
+		exporter.Shutdown(context.Background())
+	}
+}
+
+func TestParseSDKDisabled(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected bool
+		ok       bool
+	}{
+		{"", false, true},
+		{"true", true, true},
+		{" TRUE ", true, true},
+		{"false", false, true},
+		{"1", false, false},
+	}
+
+	for _, tt := range tests {
+		got, ok := parseSDKDisabled(tt.input)
+		if got != tt.expected || ok != tt.ok {
+			t.Errorf("parseSDKDisabled(%q): expected (%v, %v), but got (%v, %v)", tt.input, tt.expected, tt.ok, got, ok)
+		}
+	}
+}
+
+func TestInitTelemetry_Disabled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("expected no export when disabled, but got request to %s", r.URL.Path)
+	}))
+	defer server.Close()
+
+	t.Setenv("OTEL_SDK_DISABLED", "true")
+	t.Setenv("OTEL_METRICS_EXPORTER", "otlp")
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", strings.TrimPrefix(server.URL, "http://"))
+
+	tracer, meter, shutdown, err := InitTelemetry("svc", "tracer", "meter")
+	if err != nil {
+		t.Fatalf("expected no error, but got: %v", err)
+	}
+
+	ctx := context.Background()
+	_, span := tracer.Start(ctx, "span")
+	span.End()
+	if span.SpanContext().IsValid() {
+		t.Error("expected a no-op span without a valid span context")
+	}
+
+	counter, err := meter.Int64Counter("test.count")
+	if err != nil {
+		t.Fatalf("expected no error, but got: %v", err)
+	}
+	counter.Add(ctx, 1)
+
+	if err := shutdown(ctx); err != nil {
+		t.Errorf("expected no error on shutdown, but got: %v", err)
+	}
+}
+
+func TestBaggageSpanProcessor(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tp := tracesdk.NewTracerProvider(
+		tracesdk.WithSpanProcessor(baggageSpanProcessor{}),
+		tracesdk.WithSpanProcessor(recorder),
+	)
+
+	member, _ := baggage.NewMember("cep", "01001-000")
+	bag, _ := baggage.New(member)
+	ctx := baggage.ContextWithBaggage(context.Background(), bag)
+
+	_, span := tp.Tracer("test").Start(ctx, "child")
+	span.End()
+
+	spans := recorder.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("expected 1 span, but got %d", len(spans))
+	}
+	for _, attr := range spans[0].Attributes() {
+		if attr.Key == "cep" && attr.Value.AsString() == "01001-000" {
+			return
+		}
+	}
+	t.Errorf("expected the cep baggage as a span attribute, but got %v", spans[0].Attributes())
+}
@@ -0,0 +1,137 @@
+package viacep
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultCacheTTL                = 24 * time.Hour
+	defaultCacheMaxEntries         = 1000
+	defaultNegativeCacheTTL        = 5 * time.Minute
+	defaultNegativeCacheMaxEntries = 1000
+)
+
+type cacheEntry struct {
+	value     *ViaCepResponse
+	expiresAt time.Time
+}
+
+// Cache em memória com TTL e limite de entradas (seguro para uso concorrente)
+type cache struct {
+	mu         sync.Mutex
+	ttl        time.Duration
+	maxEntries int
+	entries    map[string]cacheEntry
+	now        func() time.Time
+	// Acertos, expirações e tamanho vão para as métricas com este nome (nil sem WithMetrics)
+	name    string
+	metrics Metrics
+}
+
+func newCache(ttl time.Duration, maxEntries int) *cache {
+	return &cache{
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		entries:    make(map[string]cacheEntry),
+		now:        time.Now,
+	}
+}
+
+func (c *cache) get(key string) (*ViaCepResponse, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok {
+		c.observeLookup(false)
+		return nil, false
+	}
+	if !c.now().Before(entry.expiresAt) {
+		delete(c.entries, key)
+		c.observeLookup(false)
+		c.observeEvictions(1)
+		c.reportSize()
+		return nil, false
+	}
+
+	c.observeLookup(true)
+	value := *entry.value
+	return &value, true
+}
+
+func (c *cache) set(key string, value *ViaCepResponse) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	if _, exists := c.entries[key]; !exists && len(c.entries) >= c.maxEntries {
+		c.evict(now)
+	}
+
+	stored := *value
+	c.entries[key] = cacheEntry{value: &stored, expiresAt: now.Add(c.ttl)}
+	c.reportSize()
+}
+
+// Esvazia o cache, devolvendo quantas entradas havia
+func (c *cache) flush() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	n := len(c.entries)
+	clear(c.entries)
+	c.reportSize()
+	return n
+}
+
+// Remove as entradas expiradas e, se ainda estiver cheio, a mais antiga
+func (c *cache) evict(now time.Time) {
+	before := len(c.entries)
+	var oldestKey string
+	var oldest time.Time
+	for key, entry := range c.entries {
+		if !now.Before(entry.expiresAt) {
+			delete(c.entries, key)
+			continue
+		}
+		if oldestKey == "" || entry.expiresAt.Before(oldest) {
+			oldestKey, oldest = key, entry.expiresAt
+		}
+	}
+
+	if len(c.entries) >= c.maxEntries && oldestKey != "" {
+		delete(c.entries, oldestKey)
+	}
+	c.observeEvictions(before - len(c.entries))
+}
+
+// Chamados com o mutex já travado
+func (c *cache) observeLookup(hit bool) {
+	if c.metrics != nil {
+		c.metrics.ObserveCacheLookup(c.name, hit)
+	}
+}
+
+func (c *cache) observeEvictions(n int) {
+	if c.metrics != nil && n > 0 {
+		c.metrics.ObserveCacheEvictions(c.name, n)
+	}
+}
+
+func (c *cache) reportSize() {
+	if c.metrics != nil {
+		c.metrics.SetCacheSize(c.name, len(c.entries))
+	}
+}
+
+// Chave do cache: apenas os dígitos do CEP
+func normalizeCepKey(cep string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, cep)
+}
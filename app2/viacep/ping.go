@@ -0,0 +1,31 @@
+package viacep
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+// CEP consultado por Ping; qualquer CEP existente serve
+const pingCep = "01001000"
+
+// Verifica se o ViaCEP responde, com uma requisição direta: sem cache, retry, limite de concorrência
+// nem circuit breaker, que esconderiam o estado real da API (para o /ready)
+func (c *Client) Ping(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", fmt.Sprintf("%s/ws/%s/json/", c.baseURL, pingCep), nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("User-Agent", c.userAgent)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("ViaCEP returned status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package viacep
+
+import (
+	"context"
+	"errors"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type CepProvider interface {
+	FindAddressByCep(ctx context.Context, cep string) (*ViaCepResponse, error)
+}
+
+// Consulta os provedores em ordem, passando ao próximo apenas em erros internos/de transporte
+type FallbackProvider struct {
+	providers []CepProvider
+	logger    Logger
+}
+
+func NewFallbackProvider(logger Logger, providers ...CepProvider) *FallbackProvider {
+	return &FallbackProvider{
+		providers: providers,
+		logger:    logger,
+	}
+}
+
+func (f *FallbackProvider) FindAddressByCep(ctx context.Context, cep string) (*ViaCepResponse, error) {
+	span := trace.SpanFromContext(ctx)
+
+	err := ErrInternal
+	for i, provider := range f.providers {
+		if i > 0 {
+			span.AddEvent("cep.fallback", trace.WithAttributes(attribute.Int("cep.provider_index", i)))
+		}
+
+		var address *ViaCepResponse
+		address, err = provider.FindAddressByCep(ctx, cep)
+		if err == nil || errors.Is(err, ErrCepNotFound) || errors.Is(err, ErrInvalidCep) || ctx.Err() != nil {
+			return address, err
+		}
+
+		f.logger.WarnContext(ctx, "CEP provider failed, trying next", "cep", cep, "provider_index", i, "error", err)
+	}
+
+	return nil, err
+}
@@ -0,0 +1,57 @@
+package viacep
+
+import (
+	"context"
+	"errors"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// Agrupa consultas simultâneas do mesmo CEP em uma única chamada ao provedor.
+// O resultado (inclusive o erro) só é compartilhado enquanto a chamada está em andamento
+type SingleflightProvider struct {
+	next  CepProvider
+	group singleflight.Group
+}
+
+func NewSingleflightProvider(next CepProvider) *SingleflightProvider {
+	return &SingleflightProvider{next: next}
+}
+
+func (s *SingleflightProvider) FindAddressByCep(ctx context.Context, cep string) (*ViaCepResponse, error) {
+	ch := s.group.DoChan(normalizeCepKey(cep), func() (any, error) {
+		sharedCtx, cancel := sharedContext(ctx)
+		defer cancel()
+		return s.next.FindAddressByCep(sharedCtx, cep)
+	})
+
+	select {
+	case <-ctx.Done():
+		return nil, timeoutError(ctx.Err())
+	case res := <-ch:
+		if res.Err != nil {
+			return nil, timeoutError(res.Err)
+		}
+		// Cópia por chamador, já que o ponteiro é compartilhado
+		address := *res.Val.(*ViaCepResponse)
+		return &address, nil
+	}
+}
+
+// Sem o cancelamento de quem chegou primeiro, para não derrubar os demais, mas com o prazo dele: a chamada
+// compartilhada não passa do prazo da requisição que a iniciou (sem prazo, vale o timeout do cliente HTTP)
+func sharedContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	shared := context.WithoutCancel(ctx)
+	if deadline, ok := ctx.Deadline(); ok {
+		return context.WithDeadline(shared, deadline)
+	}
+	return shared, func() {}
+}
+
+// Prazo esgotado vira ErrTimeout, como no cliente, para o handler responder 504
+func timeoutError(err error) error {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return ErrTimeout
+	}
+	return err
+}
@@ -0,0 +1,128 @@
+package viacep
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type countingProvider struct {
+	calls   atomic.Int32
+	release chan struct{}
+	err     error
+}
+
+func (p *countingProvider) FindAddressByCep(ctx context.Context, cep string) (*ViaCepResponse, error) {
+	p.calls.Add(1)
+	if p.release != nil {
+		<-p.release
+	}
+	if p.err != nil {
+		return nil, p.err
+	}
+	return &ViaCepResponse{Cep: cep, City: "São Paulo"}, nil
+}
+
+func TestSingleflightProvider_DedupesConcurrentCalls(t *testing.T) {
+	upstream := &countingProvider{release: make(chan struct{})}
+	provider := NewSingleflightProvider(upstream)
+
+	const n = 20
+	var wg sync.WaitGroup
+	results := make([]*ViaCepResponse, n)
+	for i := range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			// Formatos diferentes do mesmo CEP caem na mesma chave
+			cep := "01001-000"
+			if i%2 == 0 {
+				cep = "01001000"
+			}
+			results[i], _ = provider.FindAddressByCep(context.Background(), cep)
+		}()
+	}
+
+	// Dá tempo para todas as goroutines entrarem no grupo antes de liberar a chamada
+	time.Sleep(50 * time.Millisecond)
+	close(upstream.release)
+	wg.Wait()
+
+	if got := upstream.calls.Load(); got != 1 {
+		t.Fatalf("expected upstream to be called once, but got %d", got)
+	}
+
+	for i, address := range results {
+		if address == nil || address.City != "São Paulo" {
+			t.Fatalf("result %d: expected shared address, but got %+v", i, address)
+		}
+	}
+
+	results[0].City = "changed"
+	if results[1].City != "São Paulo" {
+		t.Error("expected each caller to receive its own copy")
+	}
+}
+
+func TestSingleflightProvider_DoesNotCacheErrors(t *testing.T) {
+	upstream := &countingProvider{err: ErrInternal}
+	provider := NewSingleflightProvider(upstream)
+
+	for range 2 {
+		if _, err := provider.FindAddressByCep(context.Background(), "01001-000"); err != ErrInternal {
+			t.Fatalf("expected error '%v', but got '%v'", ErrInternal, err)
+		}
+	}
+
+	if got := upstream.calls.Load(); got != 2 {
+		t.Errorf("expected errors not to outlive the in-flight call (2 upstream calls), but got %d", got)
+	}
+}
+
+func TestSingleflightProvider_CallerCancellation(t *testing.T) {
+	upstream := &countingProvider{release: make(chan struct{})}
+	defer close(upstream.release)
+	provider := NewSingleflightProvider(upstream)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := provider.FindAddressByCep(ctx, "01001-000"); err != context.Canceled {
+		t.Errorf("expected error '%v', but got '%v'", context.Canceled, err)
+	}
+}
+
+// Bloqueia até o contexto da chamada compartilhada acabar e informa o motivo
+type blockingProvider struct {
+	done chan error
+}
+
+func (p *blockingProvider) FindAddressByCep(ctx context.Context, cep string) (*ViaCepResponse, error) {
+	<-ctx.Done()
+	p.done <- ctx.Err()
+	return nil, ctx.Err()
+}
+
+func TestSingleflightProvider_CallerDeadline(t *testing.T) {
+	upstream := &blockingProvider{done: make(chan error, 1)}
+	provider := NewSingleflightProvider(upstream)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	if _, err := provider.FindAddressByCep(ctx, "01001-000"); err != ErrTimeout {
+		t.Errorf("expected error '%v', but got '%v'", ErrTimeout, err)
+	}
+
+	// A chamada compartilhada herda o prazo de quem a iniciou, em vez de seguir sem limite
+	select {
+	case err := <-upstream.done:
+		if err != context.DeadlineExceeded {
+			t.Errorf("expected the upstream context to expire, but got '%v'", err)
+		}
+	case <-time.After(time.Second):
+		t.Error("expected the upstream call to be cancelled at the caller deadline")
+	}
+}
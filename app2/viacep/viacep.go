@@ -3,54 +3,257 @@ package viacep
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
-	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"l02-02/backoff"
+	"l02-02/circuitbreaker"
+	"l02-02/httpx"
+	"l02-02/limiter"
+	"l02-02/version"
+
 	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
 	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
 	"go.opentelemetry.io/otel/trace"
 )
 
 var (
 	ErrCepNotFound = fmt.Errorf("CEP não encontrado")
-	ErrInternal    = fmt.Errorf("ocorreu um erro interno ao buscar o CEP")
+	// O ViaCEP responde 400 para CEPs mal formatados
+	ErrInvalidCep = fmt.Errorf("CEP inválido")
+	ErrInternal   = fmt.Errorf("ocorreu um erro interno ao buscar o CEP")
+	ErrTimeout    = fmt.Errorf("tempo esgotado ao buscar o CEP")
 )
 
-type ViaCepClient interface {
-	FindAddressByCep(ctx context.Context, cep string) (*ViaCepResponse, error)
-}
+// Mantido por compatibilidade: todo ViaCepClient é um CepProvider
+type ViaCepClient = CepProvider
 
+// Subconjunto do *slog.Logger usado pelo cliente
 type Logger interface {
-	Printf(format string, v ...interface{})
+	WarnContext(ctx context.Context, msg string, args ...any)
+	ErrorContext(ctx context.Context, msg string, args ...any)
+}
+
+type Metrics interface {
+	// statusClass é o de httpx.StatusClass
+	ObserveOutbound(upstream, statusClass string, duration time.Duration)
+	// cache é "viacep" (endereços) ou "viacep_not_found" (cache negativo)
+	ObserveCacheLookup(cache string, hit bool)
+	ObserveCacheEvictions(cache string, n int)
+	SetCacheSize(cache string, size int)
 }
 
 type Client struct {
 	httpClient *http.Client
+	timeout    time.Duration
+	transport  httpx.TransportConfig
 	baseURL    string
+	userAgent  string
 	logger     Logger
 	tracer     trace.Tracer
+	cache      *cache
+	// CEPs inexistentes ficam em um cache separado, com TTL curto
+	negativeCache *cache
+	retry         backoff.Policy
+	breaker       *circuitbreaker.Breaker
+	limiter       *limiter.Limiter
+	metrics       Metrics
+	maxBodyBytes  int64
 }
 
+type Option func(*Client)
+
 type ViaCepResponse struct {
 	Cep    string `json:"cep"`
 	Street string `json:"logradouro"`
 	City   string `json:"localidade"`
 	State  string `json:"uf"`
 	Erro   bool   `json:"erro"`
+	// O ViaCEP não informa; preenchidas apenas por provedores que as têm (BrasilAPI v2)
+	Coordinates *Coordinates `json:"-"`
+}
+
+type Coordinates struct {
+	Lat float64
+	Lon float64
+}
+
+// Tempo máximo de cada tentativa de requisição à API externa (ignorado junto com WithHTTPClient)
+func WithTimeout(d time.Duration) Option {
+	return func(c *Client) {
+		if d > 0 {
+			c.timeout = d
+		}
+	}
+}
+
+// Pool de conexões do cliente padrão (ignorado junto com WithHTTPClient)
+func WithTransportConfig(cfg httpx.TransportConfig) Option {
+	return func(c *Client) {
+		c.transport = cfg
+	}
+}
+
+// Identifica o serviço para a API externa (padrão: l02-app2/<versão>)
+func WithUserAgent(userAgent string) Option {
+	return func(c *Client) {
+		if userAgent != "" {
+			c.userAgent = userAgent
+		}
+	}
+}
+
+// Sobrescreve a URL base da API (útil para testes e ambientes de homologação)
+func WithBaseURL(baseURL string) Option {
+	return func(c *Client) {
+		if baseURL != "" {
+			c.baseURL = strings.TrimSuffix(baseURL, "/")
+		}
+	}
+}
+
+// Usa o cliente informado como está: sem o transporte de tracing
+// e sem aplicar WithTimeout, que só vale para o cliente padrão
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// Registra a latência das chamadas à API externa
+func WithMetrics(m Metrics) Option {
+	return func(c *Client) {
+		c.metrics = m
+	}
+}
+
+// Protege a API externa com um circuit breaker (valores <= 0 usam os padrões)
+func WithCircuitBreaker(threshold int, cooldown time.Duration) Option {
+	if threshold <= 0 {
+		threshold = defaultBreakerThreshold
+	}
+	if cooldown <= 0 {
+		cooldown = defaultBreakerCooldown
+	}
+	return func(c *Client) {
+		c.breaker = circuitbreaker.New(threshold, cooldown)
+	}
+}
+
+// Limita as requisições simultâneas à API externa (max <= 0 desabilita); com a vaga ocupada,
+// espera ou, com failFast, falha na hora com limiter.ErrOverloaded
+func WithConcurrencyLimit(max int, failFast bool) Option {
+	return func(c *Client) {
+		if max > 0 {
+			c.limiter = limiter.New(max, failFast)
+		}
+	}
+}
+
+// Tamanho máximo do corpo das respostas, já descomprimido (n <= 0 mantém httpx.DefaultMaxResponseBytes)
+func WithMaxResponseBytes(n int64) Option {
+	return func(c *Client) {
+		if n > 0 {
+			c.maxBodyBytes = n
+		}
+	}
+}
+
+// Habilita o cache em memória de endereços (ttl <= 0 e maxEntries <= 0 usam os padrões)
+func WithCache(ttl time.Duration, maxEntries int) Option {
+	if ttl <= 0 {
+		ttl = defaultCacheTTL
+	}
+	if maxEntries <= 0 {
+		maxEntries = defaultCacheMaxEntries
+	}
+	return func(c *Client) {
+		c.cache = newCache(ttl, maxEntries)
+	}
+}
+
+// Habilita novas tentativas em erros de rede e 5xx (valores <= 0 usam os padrões)
+func WithRetry(maxAttempts int, baseDelay time.Duration) Option {
+	if maxAttempts <= 0 {
+		maxAttempts = defaultRetryMaxAttempts
+	}
+	if baseDelay <= 0 {
+		baseDelay = defaultRetryBaseDelay
+	}
+	return func(c *Client) {
+		c.retry = backoff.Policy{MaxAttempts: maxAttempts, BaseDelay: baseDelay, MaxDelay: defaultRetryMaxDelay}
+	}
+}
+
+// Habilita o cache de CEPs não encontrados (ttl <= 0 e maxEntries <= 0 usam os padrões)
+func WithNegativeCache(ttl time.Duration, maxEntries int) Option {
+	if ttl <= 0 {
+		ttl = defaultNegativeCacheTTL
+	}
+	if maxEntries <= 0 {
+		maxEntries = defaultNegativeCacheMaxEntries
+	}
+	return func(c *Client) {
+		c.negativeCache = newCache(ttl, maxEntries)
+	}
 }
 
-func NewClient(logger Logger, tracer trace.Tracer) *Client {
-	return &Client{
-		httpClient: &http.Client{
-			Transport: otelhttp.NewTransport(http.DefaultTransport),
-			Timeout:   5 * time.Second,
-		},
-		baseURL: "https://viacep.com.br",
-		logger:  logger,
-		tracer:  tracer,
+func NewClient(logger Logger, tracer trace.Tracer, opts ...Option) *Client {
+	c := &Client{
+		timeout:   5 * time.Second,
+		userAgent: version.UserAgent(),
+		baseURL:   "https://viacep.com.br",
+		logger:    logger,
+		tracer:    tracer,
+		retry:     backoff.Policy{MaxAttempts: 1},
+		// Sem teto, uma API externa maliciosa poderia esgotar a memória com um corpo gigante
+		maxBodyBytes: httpx.DefaultMaxResponseBytes,
+	}
+
+	for _, opt := range opts {
+		opt(c)
 	}
+
+	if c.httpClient == nil {
+		c.httpClient = httpx.NewInstrumentedClient(httpx.WithTimeout(c.timeout), httpx.WithTransportConfig(c.transport))
+	}
+
+	// Depois das opções, já que WithMetrics pode vir antes ou depois de WithCache
+	if c.metrics != nil {
+		if c.cache != nil {
+			c.cache.name, c.cache.metrics = "viacep", c.metrics
+		}
+		if c.negativeCache != nil {
+			c.negativeCache.name, c.negativeCache.metrics = "viacep_not_found", c.metrics
+		}
+	}
+
+	return c
+}
+
+// Circuit breaker do cliente (nil sem WithCircuitBreaker), para expor o estado no /health
+func (c *Client) Breaker() *circuitbreaker.Breaker {
+	return c.breaker
+}
+
+// Esvazia o cache de endereços, devolvendo quantas entradas foram removidas (0 sem WithCache)
+func (c *Client) FlushCache() int {
+	if c.cache == nil {
+		return 0
+	}
+	return c.cache.flush()
+}
+
+// Como FlushCache, para o cache de CEPs não encontrados
+func (c *Client) FlushNegativeCache() int {
+	if c.negativeCache == nil {
+		return 0
+	}
+	return c.negativeCache.flush()
 }
 
 func (c *Client) FindAddressByCep(ctx context.Context, cep string) (*ViaCepResponse, error) {
@@ -58,39 +261,164 @@ func (c *Client) FindAddressByCep(ctx context.Context, cep string) (*ViaCepRespo
 	span.SetAttributes(attribute.String("cep.value", cep))
 	defer span.End()
 
-	url := fmt.Sprintf("%s/ws/%s/json/", c.baseURL, cep)
-
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
-	if err != nil {
-		span.RecordError(err)
-		return nil, err
+	cacheKey := normalizeCepKey(cep)
+	if c.cache != nil {
+		if address, ok := c.cache.get(cacheKey); ok {
+			span.AddEvent("cache.hit")
+			return address, nil
+		}
+	}
+	if c.negativeCache != nil {
+		if _, ok := c.negativeCache.get(cacheKey); ok {
+			span.AddEvent("cache.negative_hit")
+			return nil, ErrCepNotFound
+		}
 	}
+	if c.cache != nil || c.negativeCache != nil {
+		span.AddEvent("cache.miss")
+	}
+
+	url := fmt.Sprintf("%s/ws/%s/json/", c.baseURL, cep)
 
-	resp, err := c.httpClient.Do(req)
+	start := time.Now()
+	resp, err := c.do(ctx, span, url)
 	if err != nil {
 		span.RecordError(err)
-		c.logger.Printf("Error requesting from ViaCEP API: %v", err)
+		span.SetStatus(codes.Error, "ViaCEP request failed")
+		c.logger.ErrorContext(ctx, "error requesting from ViaCEP API", "cep", cep, "duration_ms", time.Since(start).Milliseconds(), "error", err)
+		if httpx.IsTimeout(err) {
+			return nil, ErrTimeout
+		}
+		if errors.Is(err, limiter.ErrOverloaded) {
+			return nil, limiter.ErrOverloaded
+		}
 		return nil, ErrInternal
 	}
 	defer resp.Body.Close()
 
 	span.SetAttributes(semconv.HTTPStatusCodeKey.Int(resp.StatusCode))
+	if resp.StatusCode >= http.StatusInternalServerError {
+		span.AddEvent("ViaCEP API returned server error")
+		span.SetStatus(codes.Error, fmt.Sprintf("ViaCEP returned status %d", resp.StatusCode))
+		c.logger.ErrorContext(ctx, "ViaCEP API returned server error", "cep", cep, "status", resp.StatusCode, "duration_ms", time.Since(start).Milliseconds())
+		return nil, ErrInternal
+	}
+
+	if resp.StatusCode == http.StatusBadRequest {
+		// Não vai para o cache negativo: o CEP nem chegou a ser consultado
+		span.AddEvent("ViaCEP API rejected the CEP as malformed")
+		return nil, ErrInvalidCep
+	}
+
 	if resp.StatusCode != http.StatusOK {
-		span.AddEvent("ViaCEP API returned non-OK status")
-		return nil, ErrCepNotFound
+		// 429, 403 e afins não dizem nada sobre o CEP: só o corpo com erro=true vai para o cache negativo
+		span.SetStatus(codes.Error, fmt.Sprintf("ViaCEP returned status %d", resp.StatusCode))
+		c.logger.ErrorContext(ctx, "ViaCEP API returned unexpected status", "cep", cep, "status", resp.StatusCode, "duration_ms", time.Since(start).Milliseconds())
+		return nil, ErrInternal
 	}
 
 	var data ViaCepResponse
-	if err := json.NewDecoder(resp.Body).Decode(&data); err != nil {
+	if err := decodeBody(resp, c.maxBodyBytes, &data); err != nil {
 		span.RecordError(err)
-		c.logger.Printf("Error decoding ViaCEP API response: %v", err)
+		span.SetStatus(codes.Error, "invalid ViaCEP response")
+		c.logger.ErrorContext(ctx, "error decoding ViaCEP API response", "cep", cep, "status", resp.StatusCode, "error", err)
 		return nil, ErrInternal
 	}
 
 	if data.Erro {
 		span.AddEvent("ViaCEP API response indicates CEP not found (erro=true)")
+		c.storeNotFound(cacheKey)
 		return nil, ErrCepNotFound
 	}
 
+	if c.cache != nil {
+		c.cache.set(cacheKey, &data)
+	}
+
 	return &data, nil
 }
+
+// Executa a requisição aplicando a política de novas tentativas sem ultrapassar o prazo do contexto
+func (c *Client) do(ctx context.Context, span trace.Span, url string) (*http.Response, error) {
+	var resp *http.Response
+	policy := c.retry
+	policy.OnRetry = func(attempt int, delay time.Duration) {
+		if resp != nil {
+			resp.Body.Close()
+			resp = nil
+		}
+		span.AddEvent("retry", trace.WithAttributes(
+			attribute.Int("retry.attempt", attempt),
+			attribute.Int64("retry.delay_ms", delay.Milliseconds()),
+		))
+	}
+
+	err := backoff.Retry(ctx, policy, func(attempt int) (bool, error) {
+		req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+		if err != nil {
+			return false, err
+		}
+		req.Header.Set("User-Agent", c.userAgent)
+		httpx.AcceptGzip(req)
+
+		var release func()
+		if c.limiter != nil {
+			release, err = c.limiter.Acquire(ctx, span)
+			if err != nil {
+				return false, err
+			}
+		}
+
+		var gen circuitbreaker.Generation
+		if c.breaker != nil {
+			var transition circuitbreaker.Transition
+			transition, gen, err = c.breaker.Allow()
+			circuitbreaker.RecordTransition(span, transition)
+			if err != nil {
+				if release != nil {
+					release()
+				}
+				return false, err
+			}
+		}
+
+		start := time.Now()
+		resp, err = c.httpClient.Do(req)
+		if release != nil {
+			if err != nil {
+				release()
+			} else {
+				resp.Body = limiter.ReleaseOnClose(resp.Body, release)
+			}
+		}
+		if c.metrics != nil {
+			c.metrics.ObserveOutbound("viacep", httpx.StatusClass(resp, err), time.Since(start))
+		}
+		if c.breaker != nil {
+			// Cancelamento ou prazo do chamador (hedge do app1, deadline propagado) não dizem nada sobre o upstream
+			if ctx.Err() != nil {
+				c.breaker.Abandon(gen)
+			} else {
+				circuitbreaker.RecordTransition(span, c.breaker.RecordResponse(gen, resp, err))
+			}
+		}
+
+		return retryable(ctx, resp, err), err
+	})
+	return resp, err
+}
+
+func (c *Client) storeNotFound(cacheKey string) {
+	if c.negativeCache != nil {
+		c.negativeCache.set(cacheKey, &ViaCepResponse{Erro: true})
+	}
+}
+
+// Decodifica o JSON do corpo descomprimido e limitado por httpx.ResponseBody
+func decodeBody(resp *http.Response, max int64, v any) error {
+	body, err := httpx.ResponseBody(resp, max)
+	if err != nil {
+		return err
+	}
+	return json.NewDecoder(body).Decode(v)
+}
@@ -1,17 +1,33 @@
 package viacep
 
 import (
+	"compress/gzip"
 	"context"
+	"maps"
 	"net/http"
 	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	"l02-02/circuitbreaker"
+	"l02-02/httpx"
+	"l02-02/limiter"
+
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 	"go.opentelemetry.io/otel/trace/noop"
 )
 
 type mockLogger struct{}
 
-func (m *mockLogger) Printf(format string, v ...interface{}) {}
+func (m *mockLogger) WarnContext(ctx context.Context, msg string, args ...any) {}
+
+func (m *mockLogger) ErrorContext(ctx context.Context, msg string, args ...any) {}
 
 func TestFindAddressByCep_Success(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -23,8 +39,7 @@ func TestFindAddressByCep_Success(t *testing.T) {
 	}))
 	defer server.Close()
 
-	client := NewClient(&mockLogger{}, noop.NewTracerProvider().Tracer("test"))
-	client.baseURL = server.URL
+	client := NewClient(&mockLogger{}, noop.NewTracerProvider().Tracer("test"), WithBaseURL(server.URL))
 
 	address, err := client.FindAddressByCep(context.Background(), "01001-000")
 
@@ -48,8 +63,7 @@ func TestFindAddressByCep_NotFound(t *testing.T) {
 	}))
 	defer server.Close()
 
-	client := NewClient(&mockLogger{}, noop.NewTracerProvider().Tracer("test"))
-	client.baseURL = server.URL
+	client := NewClient(&mockLogger{}, noop.NewTracerProvider().Tracer("test"), WithBaseURL(server.URL))
 
 	_, err := client.FindAddressByCep(context.Background(), "99999-999")
 
@@ -57,3 +71,800 @@ func TestFindAddressByCep_NotFound(t *testing.T) {
 		t.Errorf("expected error '%v', but got '%v'", ErrCepNotFound, err)
 	}
 }
+
+func TestFindAddressByCep_InvalidCep(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	client := NewClient(&mockLogger{}, noop.NewTracerProvider().Tracer("test"), WithBaseURL(server.URL), WithNegativeCache(time.Minute, 10))
+
+	for range 2 {
+		_, err := client.FindAddressByCep(context.Background(), "0100100X")
+		if err != ErrInvalidCep {
+			t.Errorf("expected error '%v', but got '%v'", ErrInvalidCep, err)
+		}
+	}
+
+	// CEP mal formatado não é um CEP inexistente: não vai para o cache negativo
+	if got := atomic.LoadInt32(&calls); got != 2 {
+		t.Errorf("expected 2 calls, but got %d", got)
+	}
+}
+
+func newCountingServer(t *testing.T, body string) (*httptest.Server, *int32) {
+	t.Helper()
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+	return server, &calls
+}
+
+func TestFindAddressByCep_CacheHit(t *testing.T) {
+	server, calls := newCountingServer(t, `{"cep": "01001-000", "localidade": "São Paulo"}`)
+
+	client := NewClient(&mockLogger{}, noop.NewTracerProvider().Tracer("test"), WithBaseURL(server.URL), WithCache(time.Hour, 10))
+
+	for i := 0; i < 3; i++ {
+		address, err := client.FindAddressByCep(context.Background(), "01001-000")
+		if err != nil {
+			t.Fatalf("expected no error, but got: %v", err)
+		}
+		if address.City != "São Paulo" {
+			t.Errorf("expected city 'São Paulo', but got '%s'", address.City)
+		}
+	}
+
+	if got := atomic.LoadInt32(calls); got != 1 {
+		t.Errorf("expected 1 upstream call, but got %d", got)
+	}
+}
+
+func TestFindAddressByCep_CacheMiss(t *testing.T) {
+	server, calls := newCountingServer(t, `{"cep": "01001-000", "localidade": "São Paulo"}`)
+
+	client := NewClient(&mockLogger{}, noop.NewTracerProvider().Tracer("test"), WithBaseURL(server.URL), WithCache(time.Hour, 10))
+
+	client.FindAddressByCep(context.Background(), "01001-000")
+	client.FindAddressByCep(context.Background(), "20040-000")
+
+	if got := atomic.LoadInt32(calls); got != 2 {
+		t.Errorf("expected 2 upstream calls, but got %d", got)
+	}
+}
+
+func TestFindAddressByCep_CacheExpiry(t *testing.T) {
+	server, calls := newCountingServer(t, `{"cep": "01001-000", "localidade": "São Paulo"}`)
+
+	client := NewClient(&mockLogger{}, noop.NewTracerProvider().Tracer("test"), WithBaseURL(server.URL), WithCache(time.Minute, 10))
+
+	now := time.Now()
+	client.cache.now = func() time.Time { return now }
+
+	client.FindAddressByCep(context.Background(), "01001-000")
+	now = now.Add(2 * time.Minute)
+	client.FindAddressByCep(context.Background(), "01001-000")
+
+	if got := atomic.LoadInt32(calls); got != 2 {
+		t.Errorf("expected 2 upstream calls after expiry, but got %d", got)
+	}
+}
+
+func TestFlushCache(t *testing.T) {
+	server, calls := newCountingServer(t, `{"cep": "01001-000", "localidade": "São Paulo"}`)
+
+	client := NewClient(&mockLogger{}, noop.NewTracerProvider().Tracer("test"), WithBaseURL(server.URL), WithCache(time.Hour, 10))
+
+	client.FindAddressByCep(context.Background(), "01001-000")
+	client.FindAddressByCep(context.Background(), "20040-000")
+	if got := client.FlushCache(); got != 2 {
+		t.Errorf("expected 2 evicted entries, but got %d", got)
+	}
+	if got := client.FlushNegativeCache(); got != 0 {
+		t.Errorf("expected 0 evicted entries without a negative cache, but got %d", got)
+	}
+
+	client.FindAddressByCep(context.Background(), "01001-000")
+	if got := atomic.LoadInt32(calls); got != 3 {
+		t.Errorf("expected 3 upstream calls after the flush, but got %d", got)
+	}
+}
+
+func TestFindAddressByCep_NoCacheByDefault(t *testing.T) {
+	server, calls := newCountingServer(t, `{"cep": "01001-000", "localidade": "São Paulo"}`)
+
+	client := NewClient(&mockLogger{}, noop.NewTracerProvider().Tracer("test"), WithBaseURL(server.URL))
+
+	client.FindAddressByCep(context.Background(), "01001-000")
+	client.FindAddressByCep(context.Background(), "01001-000")
+
+	if got := atomic.LoadInt32(calls); got != 2 {
+		t.Errorf("expected 2 upstream calls without cache, but got %d", got)
+	}
+}
+
+func TestCache_MaxEntries(t *testing.T) {
+	c := newCache(time.Hour, 2)
+	now := time.Now()
+	c.now = func() time.Time { return now }
+
+	c.set("01001000", &ViaCepResponse{City: "São Paulo"})
+	now = now.Add(time.Second)
+	c.set("20040000", &ViaCepResponse{City: "Rio de Janeiro"})
+	now = now.Add(time.Second)
+	c.set("30130000", &ViaCepResponse{City: "Belo Horizonte"})
+
+	if len(c.entries) != 2 {
+		t.Errorf("expected 2 entries, but got %d", len(c.entries))
+	}
+	if _, ok := c.get("01001000"); ok {
+		t.Error("expected oldest entry to be evicted")
+	}
+}
+
+func TestCache_ConcurrentAccess(t *testing.T) {
+	c := newCache(time.Hour, 50)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			key := string(rune('a' + i))
+			c.set(key, &ViaCepResponse{City: key})
+			c.get(key)
+		}(i)
+	}
+	wg.Wait()
+}
+
+func TestFindAddressByCep_NegativeCacheHit(t *testing.T) {
+	server, calls := newCountingServer(t, `{"erro": true}`)
+
+	client := NewClient(&mockLogger{}, noop.NewTracerProvider().Tracer("test"), WithBaseURL(server.URL), WithNegativeCache(5*time.Minute, 10))
+
+	for i := 0; i < 2; i++ {
+		_, err := client.FindAddressByCep(context.Background(), "99999-999")
+		if err != ErrCepNotFound {
+			t.Errorf("expected error '%v', but got '%v'", ErrCepNotFound, err)
+		}
+	}
+
+	if got := atomic.LoadInt32(calls); got != 1 {
+		t.Errorf("expected 1 upstream call, but got %d", got)
+	}
+}
+
+func TestFindAddressByCep_NegativeCacheExpiry(t *testing.T) {
+	server, calls := newCountingServer(t, `{"erro": true}`)
+
+	client := NewClient(&mockLogger{}, noop.NewTracerProvider().Tracer("test"), WithBaseURL(server.URL), WithNegativeCache(5*time.Minute, 10))
+
+	now := time.Now()
+	client.negativeCache.now = func() time.Time { return now }
+
+	client.FindAddressByCep(context.Background(), "99999-999")
+	now = now.Add(6 * time.Minute)
+	client.FindAddressByCep(context.Background(), "99999-999")
+
+	if got := atomic.LoadInt32(calls); got != 2 {
+		t.Errorf("expected 2 upstream calls after expiry, but got %d", got)
+	}
+}
+
+func TestFindAddressByCep_NegativeCacheDoesNotStoreFound(t *testing.T) {
+	server, _ := newCountingServer(t, `{"cep": "01001-000", "localidade": "São Paulo"}`)
+
+	client := NewClient(&mockLogger{}, noop.NewTracerProvider().Tracer("test"), WithBaseURL(server.URL), WithNegativeCache(5*time.Minute, 10))
+
+	client.FindAddressByCep(context.Background(), "01001-000")
+
+	if len(client.negativeCache.entries) != 0 {
+		t.Errorf("expected empty negative cache, but got %d entries", len(client.negativeCache.entries))
+	}
+}
+
+func TestWithConcurrencyLimit(t *testing.T) {
+	const limit = 2
+	var inFlight, peak int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			p := atomic.LoadInt32(&peak)
+			if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		w.Write([]byte(`{"cep": "01001-000", "localidade": "São Paulo"}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(&mockLogger{}, noop.NewTracerProvider().Tracer("test"), WithBaseURL(server.URL), WithConcurrencyLimit(limit, false))
+
+	var wg sync.WaitGroup
+	for range 5 * limit {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := client.FindAddressByCep(context.Background(), "01001-000"); err != nil {
+				t.Errorf("expected no error, but got: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := atomic.LoadInt32(&peak); got > limit {
+		t.Errorf("expected at most %d concurrent requests, but got %d", limit, got)
+	}
+}
+
+func TestWithConcurrencyLimit_FailFast(t *testing.T) {
+	started := make(chan struct{})
+	unblock := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-unblock
+		w.Write([]byte(`{"cep": "01001-000", "localidade": "São Paulo"}`))
+	}))
+	defer server.Close()
+	defer close(unblock)
+
+	client := NewClient(&mockLogger{}, noop.NewTracerProvider().Tracer("test"), WithBaseURL(server.URL), WithConcurrencyLimit(1, true))
+
+	go client.FindAddressByCep(context.Background(), "01001-000")
+	<-started
+
+	if _, err := client.FindAddressByCep(context.Background(), "01001-000"); err != limiter.ErrOverloaded {
+		t.Errorf("expected error '%v', but got '%v'", limiter.ErrOverloaded, err)
+	}
+}
+
+func TestFindAddressByCep_RetrySucceedsAfterFailures(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) <= 2 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"cep": "01001-000", "localidade": "São Paulo"}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(&mockLogger{}, noop.NewTracerProvider().Tracer("test"), WithBaseURL(server.URL), WithRetry(3, time.Millisecond))
+
+	address, err := client.FindAddressByCep(context.Background(), "01001-000")
+	if err != nil {
+		t.Fatalf("expected no error, but got: %v", err)
+	}
+
+	if address.City != "São Paulo" {
+		t.Errorf("expected city 'São Paulo', but got '%s'", address.City)
+	}
+
+	if got := atomic.LoadInt32(&calls); got != 3 {
+		t.Errorf("expected 3 calls, but got %d", got)
+	}
+}
+
+func TestFindAddressByCep_NoRetryOnNotFound(t *testing.T) {
+	server, calls := newCountingServer(t, `{"erro": true}`)
+
+	client := NewClient(&mockLogger{}, noop.NewTracerProvider().Tracer("test"), WithBaseURL(server.URL), WithRetry(3, time.Millisecond))
+
+	_, err := client.FindAddressByCep(context.Background(), "99999-999")
+	if err != ErrCepNotFound {
+		t.Errorf("expected error '%v', but got '%v'", ErrCepNotFound, err)
+	}
+
+	if got := atomic.LoadInt32(calls); got != 1 {
+		t.Errorf("expected 1 call, but got %d", got)
+	}
+}
+
+func TestFindAddressByCep_CircuitBreakerFailsFast(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	client := NewClient(&mockLogger{}, noop.NewTracerProvider().Tracer("test"), WithBaseURL(server.URL), WithCircuitBreaker(2, time.Minute))
+
+	for i := 0; i < 3; i++ {
+		if _, err := client.FindAddressByCep(context.Background(), "01001-000"); err != ErrInternal {
+			t.Errorf("expected error '%v', but got '%v'", ErrInternal, err)
+		}
+	}
+
+	if got := atomic.LoadInt32(&calls); got != 2 {
+		t.Errorf("expected 2 upstream calls before the breaker opens, but got %d", got)
+	}
+
+	_, err := client.FindAddressByCep(context.Background(), "01001-000")
+	if err != ErrInternal {
+		t.Errorf("expected error '%v', but got '%v'", ErrInternal, err)
+	}
+}
+
+func TestFindAddressByCep_CallerCancellationDoesNotOpenBreaker(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	client := NewClient(&mockLogger{}, noop.NewTracerProvider().Tracer("test"), WithBaseURL(server.URL), WithCircuitBreaker(1, time.Minute))
+
+	for i := 0; i < 3; i++ {
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		client.FindAddressByCep(ctx, "01001-000")
+		cancel()
+	}
+
+	if got := client.Breaker().State(); got != circuitbreaker.Closed {
+		t.Errorf("expected state 'closed', but got '%s'", got)
+	}
+}
+
+type stubProvider struct {
+	address *ViaCepResponse
+	err     error
+	calls   int
+}
+
+func (s *stubProvider) FindAddressByCep(ctx context.Context, cep string) (*ViaCepResponse, error) {
+	s.calls++
+	return s.address, s.err
+}
+
+func TestFallbackProvider_PrimaryFailsSecondarySucceeds(t *testing.T) {
+	primary := &stubProvider{err: ErrInternal}
+	secondary := &stubProvider{address: &ViaCepResponse{City: "São Paulo"}}
+
+	provider := NewFallbackProvider(&mockLogger{}, primary, secondary)
+	address, err := provider.FindAddressByCep(context.Background(), "01001-000")
+	if err != nil {
+		t.Fatalf("expected no error, but got: %v", err)
+	}
+
+	if address.City != "São Paulo" {
+		t.Errorf("expected city 'São Paulo', but got '%s'", address.City)
+	}
+
+	if primary.calls != 1 || secondary.calls != 1 {
+		t.Errorf("expected 1 call on each provider, but got %d and %d", primary.calls, secondary.calls)
+	}
+}
+
+func TestFallbackProvider_NotFoundDoesNotFallThrough(t *testing.T) {
+	primary := &stubProvider{err: ErrCepNotFound}
+	secondary := &stubProvider{address: &ViaCepResponse{City: "São Paulo"}}
+
+	provider := NewFallbackProvider(&mockLogger{}, primary, secondary)
+	_, err := provider.FindAddressByCep(context.Background(), "99999-999")
+	if err != ErrCepNotFound {
+		t.Errorf("expected error '%v', but got '%v'", ErrCepNotFound, err)
+	}
+
+	if secondary.calls != 0 {
+		t.Errorf("expected secondary not to be called, but got %d calls", secondary.calls)
+	}
+}
+
+func TestFallbackProvider_InvalidCepDoesNotFallThrough(t *testing.T) {
+	secondary := &stubProvider{address: &ViaCepResponse{City: "São Paulo"}}
+
+	provider := NewFallbackProvider(&mockLogger{}, &stubProvider{err: ErrInvalidCep}, secondary)
+	_, err := provider.FindAddressByCep(context.Background(), "0100100X")
+	if err != ErrInvalidCep {
+		t.Errorf("expected error '%v', but got '%v'", ErrInvalidCep, err)
+	}
+
+	if secondary.calls != 0 {
+		t.Errorf("expected secondary not to be called, but got %d calls", secondary.calls)
+	}
+}
+
+func TestFallbackProvider_AllFail(t *testing.T) {
+	provider := NewFallbackProvider(&mockLogger{}, &stubProvider{err: ErrInternal}, &stubProvider{err: ErrInternal})
+
+	_, err := provider.FindAddressByCep(context.Background(), "01001-000")
+	if err != ErrInternal {
+		t.Errorf("expected error '%v', but got '%v'", ErrInternal, err)
+	}
+}
+
+func TestFindAddressByCep_ServerErrorIsInternal(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	client := NewClient(&mockLogger{}, noop.NewTracerProvider().Tracer("test"), WithBaseURL(server.URL))
+
+	_, err := client.FindAddressByCep(context.Background(), "01001-000")
+	if err != ErrInternal {
+		t.Errorf("expected error '%v', but got '%v'", ErrInternal, err)
+	}
+}
+
+func TestFindAddressByCep_UnexpectedStatusIsNotCached(t *testing.T) {
+	for _, status := range []int{http.StatusTooManyRequests, http.StatusForbidden, http.StatusNotFound} {
+		t.Run(http.StatusText(status), func(t *testing.T) {
+			var calls atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls.Add(1)
+				w.WriteHeader(status)
+			}))
+			defer server.Close()
+
+			client := NewClient(&mockLogger{}, noop.NewTracerProvider().Tracer("test"), WithBaseURL(server.URL), WithNegativeCache(5*time.Minute, 10))
+
+			for i := 0; i < 2; i++ {
+				_, err := client.FindAddressByCep(context.Background(), "01001-000")
+				if err != ErrInternal {
+					t.Errorf("expected error '%v', but got '%v'", ErrInternal, err)
+				}
+			}
+
+			if got := calls.Load(); got != 2 {
+				t.Errorf("expected 2 upstream calls, but got %d", got)
+			}
+		})
+	}
+}
+
+func TestFindAddressByCep_GzipResponse(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Accept-Encoding"); got != "gzip" {
+			t.Errorf("expected Accept-Encoding 'gzip', but got '%s'", got)
+		}
+		w.Header().Set("Content-Encoding", "gzip")
+		gz := gzip.NewWriter(w)
+		gz.Write([]byte(`{"cep": "01001-000", "localidade": "São Paulo"}`))
+		gz.Close()
+	}))
+	defer server.Close()
+
+	client := NewClient(&mockLogger{}, noop.NewTracerProvider().Tracer("test"), WithBaseURL(server.URL))
+
+	address, err := client.FindAddressByCep(context.Background(), "01001-000")
+	if err != nil {
+		t.Fatalf("expected no error, but got: %v", err)
+	}
+	if address.City != "São Paulo" {
+		t.Errorf("expected city 'São Paulo', but got '%s'", address.City)
+	}
+}
+
+func TestFindAddressByCep_OversizedBody(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"cep": "01001-000", "localidade": "` + strings.Repeat("a", 2048) + `"}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(&mockLogger{}, noop.NewTracerProvider().Tracer("test"), WithBaseURL(server.URL), WithMaxResponseBytes(1024))
+
+	_, err := client.FindAddressByCep(context.Background(), "01001-000")
+	if err != ErrInternal {
+		t.Errorf("expected error '%v', but got '%v'", ErrInternal, err)
+	}
+}
+
+func TestWithTransportConfig(t *testing.T) {
+	cfg := httpx.TransportConfig{MaxIdleConns: 200, MaxIdleConnsPerHost: 50, IdleConnTimeout: 30 * time.Second}
+	client := NewClient(&mockLogger{}, noop.NewTracerProvider().Tracer("test"), WithTransportConfig(cfg))
+
+	if client.transport != cfg {
+		t.Errorf("expected transport config %+v, but got %+v", cfg, client.transport)
+	}
+
+	if client.httpClient == nil || client.httpClient.Transport == nil {
+		t.Fatal("expected the default instrumented client to be built")
+	}
+}
+
+func TestWithTimeout(t *testing.T) {
+	client := NewClient(&mockLogger{}, noop.NewTracerProvider().Tracer("test"), WithTimeout(750*time.Millisecond))
+
+	if client.httpClient.Timeout != 750*time.Millisecond {
+		t.Errorf("expected timeout 750ms, but got %s", client.httpClient.Timeout)
+	}
+}
+
+func TestNewClient_Defaults(t *testing.T) {
+	client := NewClient(&mockLogger{}, noop.NewTracerProvider().Tracer("test"))
+
+	if client.baseURL != "https://viacep.com.br" {
+		t.Errorf("expected default base URL, but got '%s'", client.baseURL)
+	}
+
+	if client.httpClient.Timeout != 5*time.Second {
+		t.Errorf("expected default timeout 5s, but got %s", client.httpClient.Timeout)
+	}
+
+	if client.retry.MaxAttempts != 1 {
+		t.Errorf("expected a single attempt by default, but got %d", client.retry.MaxAttempts)
+	}
+}
+
+func TestNewClient_LaterOptionsOverride(t *testing.T) {
+	client := NewClient(&mockLogger{}, noop.NewTracerProvider().Tracer("test"),
+		WithBaseURL("http://first"),
+		WithTimeout(time.Second),
+		WithRetry(2, time.Millisecond),
+		WithBaseURL("http://second/"),
+		WithTimeout(2*time.Second),
+		WithRetry(4, 10*time.Millisecond),
+	)
+
+	if client.baseURL != "http://second" {
+		t.Errorf("expected base URL 'http://second', but got '%s'", client.baseURL)
+	}
+
+	if client.httpClient.Timeout != 2*time.Second {
+		t.Errorf("expected timeout 2s, but got %s", client.httpClient.Timeout)
+	}
+
+	if client.retry.MaxAttempts != 4 || client.retry.BaseDelay != 10*time.Millisecond {
+		t.Errorf("expected retry 4x10ms, but got %dx%s", client.retry.MaxAttempts, client.retry.BaseDelay)
+	}
+}
+
+func TestWithHTTPClient_UsedAsIs(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Write([]byte(`{"cep":"01001-000","localidade":"São Paulo"}`))
+	}))
+	defer server.Close()
+
+	custom := &http.Client{Timeout: time.Minute}
+	client := NewClient(&mockLogger{}, noop.NewTracerProvider().Tracer("test"), WithHTTPClient(custom), WithTimeout(time.Second), WithBaseURL(server.URL))
+
+	if client.httpClient != custom || custom.Timeout != time.Minute {
+		t.Fatalf("expected supplied http client to be used untouched, but got timeout %s", client.httpClient.Timeout)
+	}
+
+	if _, err := client.FindAddressByCep(context.Background(), "01001-000"); err != nil {
+		t.Fatalf("expected no error, but got: %v", err)
+	}
+
+	if calls.Load() != 1 {
+		t.Errorf("expected 1 call through the supplied client, but got %d", calls.Load())
+	}
+}
+
+func TestFindAddressByCep_SpanStatus(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		code   codes.Code
+	}{
+		{"unexpected client error", http.StatusTooManyRequests, codes.Error},
+		{"server error", http.StatusBadGateway, codes.Error},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+			}))
+			defer server.Close()
+
+			recorder := tracetest.NewSpanRecorder()
+			tracer := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)).Tracer("test")
+			client := NewClient(&mockLogger{}, tracer, WithBaseURL(server.URL), WithHTTPClient(http.DefaultClient))
+			client.FindAddressByCep(context.Background(), "01001-000")
+
+			spans := recorder.Ended()
+			if len(spans) != 1 {
+				t.Fatalf("expected 1 span, but got %d", len(spans))
+			}
+
+			if got := spans[0].Status().Code; got != tt.code {
+				t.Errorf("expected span status %s, but got %s", tt.code, got)
+			}
+		})
+	}
+}
+
+func TestUserAgent(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     []Option
+		expected string
+	}{
+		{"default", nil, "l02-app2/dev"},
+		{"custom", []Option{WithUserAgent("dashboard/1.0")}, "dashboard/1.0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = r.UserAgent()
+				w.Write([]byte(`{"cep":"01001-000","localidade":"São Paulo"}`))
+			}))
+			defer server.Close()
+
+			client := NewClient(&mockLogger{}, noop.NewTracerProvider().Tracer("test"), append(tt.opts, WithBaseURL(server.URL))...)
+			client.FindAddressByCep(context.Background(), "01001-000")
+
+			if got != tt.expected {
+				t.Errorf("expected User-Agent '%s', but got '%s'", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestFindAddressByCep_TimeoutErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+	}))
+	defer server.Close()
+
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name     string
+		ctx      context.Context
+		expected error
+	}{
+		{"slow server", context.Background(), ErrTimeout},
+		{"canceled context", canceled, ErrInternal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := NewClient(&mockLogger{}, noop.NewTracerProvider().Tracer("test"), WithBaseURL(server.URL), WithTimeout(50*time.Millisecond))
+			if _, err := client.FindAddressByCep(tt.ctx, "01001-000"); err != tt.expected {
+				t.Errorf("expected error '%v', but got '%v'", tt.expected, err)
+			}
+		})
+	}
+}
+
+type recordingMetrics struct {
+	mu      sync.Mutex
+	classes []string
+	// Eventos de cache por "<cache> <evento>" e último tamanho informado por cache
+	cacheEvents map[string]int
+	cacheSizes  map[string]int
+}
+
+func (m *recordingMetrics) ObserveOutbound(upstream, statusClass string, duration time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if upstream != "viacep" {
+		statusClass = "unexpected upstream " + upstream
+	}
+	m.classes = append(m.classes, statusClass)
+}
+
+func (m *recordingMetrics) ObserveCacheLookup(cache string, hit bool) {
+	event := "miss"
+	if hit {
+		event = "hit"
+	}
+	m.recordCache(cache+" "+event, 1)
+}
+
+func (m *recordingMetrics) ObserveCacheEvictions(cache string, n int) {
+	m.recordCache(cache+" eviction", n)
+}
+
+func (m *recordingMetrics) SetCacheSize(cache string, size int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.cacheSizes == nil {
+		m.cacheSizes = make(map[string]int)
+	}
+	m.cacheSizes[cache] = size
+}
+
+func (m *recordingMetrics) recordCache(key string, n int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.cacheEvents == nil {
+		m.cacheEvents = make(map[string]int)
+	}
+	m.cacheEvents[key] += n
+}
+
+func TestWithMetrics_StatusClass(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.Write([]byte(`{"cep": "01001-000", "localidade": "São Paulo"}`))
+			return
+		}
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	m := &recordingMetrics{}
+	client := NewClient(&mockLogger{}, noop.NewTracerProvider().Tracer("test"), WithBaseURL(server.URL), WithMetrics(m))
+	client.FindAddressByCep(context.Background(), "01001-000")
+	client.FindAddressByCep(context.Background(), "01001-000")
+
+	// Sem resposta: a chamada conta como "error"
+	server.Close()
+	client.FindAddressByCep(context.Background(), "01001-000")
+
+	expected := []string{"2xx", "5xx", "error"}
+	if !slices.Equal(m.classes, expected) {
+		t.Errorf("expected status classes %v, but got %v", expected, m.classes)
+	}
+}
+
+func TestWithMetrics_CacheCounters(t *testing.T) {
+	server, _ := newCountingServer(t, `{"cep": "01001-000", "localidade": "São Paulo"}`)
+
+	m := &recordingMetrics{}
+	// WithMetrics antes de WithCache: a ordem das opções não deve importar
+	client := NewClient(&mockLogger{}, noop.NewTracerProvider().Tracer("test"), WithBaseURL(server.URL), WithMetrics(m), WithCache(time.Minute, 1))
+	now := time.Now()
+	client.cache.now = func() time.Time { return now }
+
+	client.FindAddressByCep(context.Background(), "01001-000")
+	client.FindAddressByCep(context.Background(), "01001-000")
+	// Com uma única vaga, o segundo CEP tira o primeiro do cache
+	client.FindAddressByCep(context.Background(), "20040-000")
+	now = now.Add(2 * time.Minute)
+	client.FindAddressByCep(context.Background(), "20040-000")
+
+	expected := map[string]int{"viacep hit": 1, "viacep miss": 3, "viacep eviction": 2}
+	if !maps.Equal(m.cacheEvents, expected) {
+		t.Errorf("expected cache events %v, but got %v", expected, m.cacheEvents)
+	}
+	// A última consulta expirou a entrada e voltou a guardá-la
+	if got := m.cacheSizes["viacep"]; got != 1 {
+		t.Errorf("expected cache size 1, but got %d", got)
+	}
+}
+
+func TestPing(t *testing.T) {
+	var status atomic.Int32
+	status.Store(http.StatusOK)
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(int(status.Load()))
+		w.Write([]byte(`{"cep": "01001-000", "localidade": "São Paulo"}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(&mockLogger{}, noop.NewTracerProvider().Tracer("test"), WithBaseURL(server.URL),
+		WithCache(time.Hour, 10), WithCircuitBreaker(1, time.Minute))
+
+	client.FindAddressByCep(context.Background(), "01001-000")
+	if err := client.Ping(context.Background()); err != nil {
+		t.Fatalf("expected no error, but got: %v", err)
+	}
+
+	// Nem o CEP no cache nem o circuito aberto respondem pelo ViaCEP fora do ar
+	status.Store(http.StatusServiceUnavailable)
+	client.FindAddressByCep(context.Background(), "20040-000")
+	calls.Store(0)
+	if err := client.Ping(context.Background()); err == nil {
+		t.Error("expected an error while ViaCEP is down")
+	}
+	if got := calls.Load(); got != 1 {
+		t.Errorf("expected 1 upstream call, but got %d", got)
+	}
+}
@@ -0,0 +1,39 @@
+package viacepmock
+
+import (
+	"context"
+	"sync"
+
+	"l02-02/viacep"
+)
+
+// Implementação configurável de viacep.CepProvider para testes; segura para uso concorrente
+type Provider struct {
+	Address *viacep.ViaCepResponse
+	Err     error
+	// Quando definida, substitui Address e Err (ex.: respostas diferentes por CEP)
+	FindFunc func(ctx context.Context, cep string) (*viacep.ViaCepResponse, error)
+
+	mu    sync.Mutex
+	calls []string
+}
+
+var _ viacep.CepProvider = (*Provider)(nil)
+
+func (p *Provider) FindAddressByCep(ctx context.Context, cep string) (*viacep.ViaCepResponse, error) {
+	p.mu.Lock()
+	p.calls = append(p.calls, cep)
+	p.mu.Unlock()
+
+	if p.FindFunc != nil {
+		return p.FindFunc(ctx, cep)
+	}
+	return p.Address, p.Err
+}
+
+// CEPs consultados, na ordem das chamadas
+func (p *Provider) Calls() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]string(nil), p.calls...)
+}
@@ -0,0 +1,19 @@
+package weatherapi
+
+import "strings"
+
+// Acentos do português (e do espanhol) trocados pela letra sem acento
+var accentFolder = strings.NewReplacer(
+	"á", "a", "à", "a", "â", "a", "ã", "a", "ä", "a",
+	"é", "e", "è", "e", "ê", "e", "ë", "e",
+	"í", "i", "ì", "i", "î", "i", "ï", "i",
+	"ó", "o", "ò", "o", "ô", "o", "õ", "o", "ö", "o",
+	"ú", "u", "ù", "u", "û", "u", "ü", "u",
+	"ç", "c", "ñ", "n",
+)
+
+// Chave da cidade para agrupar consultas: sem espaços extras, em minúsculas e sem acentos, já que a grafia
+// do ViaCEP nem sempre é consistente. Só para chaves: a consulta à WeatherAPI mantém o nome original
+func normalizeCity(city string) string {
+	return accentFolder.Replace(strings.ToLower(strings.Join(strings.Fields(city), " ")))
+}
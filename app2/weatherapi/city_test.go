@@ -0,0 +1,24 @@
+package weatherapi
+
+import "testing"
+
+func TestNormalizeCity(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"São Paulo", "sao paulo"},
+		{"sao paulo", "sao paulo"},
+		{" São Paulo ", "sao paulo"},
+		{"SÃO  PAULO", "sao paulo"},
+		{"Florianópolis", "florianopolis"},
+		{"Conceição do Araguaia", "conceicao do araguaia"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		if got := normalizeCity(tt.input); got != tt.expected {
+			t.Errorf("normalizeCity(%q): expected '%s', but got '%s'", tt.input, tt.expected, got)
+		}
+	}
+}
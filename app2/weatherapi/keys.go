@@ -0,0 +1,67 @@
+package weatherapi
+
+import (
+	"net/http"
+	"sync"
+	"time"
+)
+
+const defaultKeyCooldown = time.Minute
+
+// Rodízio entre as chaves da API; a chave que recebe 429/403 sai do rodízio até o fim do cooldown
+type keyPool struct {
+	mu       sync.Mutex
+	keys     []string
+	until    []time.Time
+	next     int
+	cooldown time.Duration
+	now      func() time.Time
+}
+
+func newKeyPool(keys []string, cooldown time.Duration) *keyPool {
+	return &keyPool{
+		keys:     keys,
+		until:    make([]time.Time, len(keys)),
+		cooldown: cooldown,
+		now:      time.Now,
+	}
+}
+
+// Próxima chave fora do cooldown; com todas em cooldown, a que sai dele primeiro
+func (p *keyPool) pick() (int, string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := p.now()
+	soonest := p.next
+	for i := range p.keys {
+		idx := (p.next + i) % len(p.keys)
+		if !now.Before(p.until[idx]) {
+			soonest = idx
+			break
+		}
+		if p.until[idx].Before(p.until[soonest]) {
+			soonest = idx
+		}
+	}
+
+	p.next = (soonest + 1) % len(p.keys)
+	return soonest, p.keys[soonest]
+}
+
+// Com uma única chave não há para onde rodar: o cooldown não se aplica
+func (p *keyPool) suspend(idx int) bool {
+	if len(p.keys) < 2 {
+		return false
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.until[idx] = p.now().Add(p.cooldown)
+	return true
+}
+
+// Limite de requisições (429) ou cota mensal esgotada/chave bloqueada (403)
+func keyThrottled(status int) bool {
+	return status == http.StatusTooManyRequests || status == http.StatusForbidden
+}
@@ -0,0 +1,115 @@
+package weatherapi
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+func TestKeyPool_RoundRobin(t *testing.T) {
+	pool := newKeyPool([]string{"a", "b", "c"}, time.Minute)
+
+	var got []string
+	for range 4 {
+		_, key := pool.pick()
+		got = append(got, key)
+	}
+
+	if expected := []string{"a", "b", "c", "a"}; !slices.Equal(got, expected) {
+		t.Errorf("expected %v, but got %v", expected, got)
+	}
+}
+
+func TestKeyPool_Cooldown(t *testing.T) {
+	now := time.Date(2026, time.January, 1, 12, 0, 0, 0, time.UTC)
+	pool := newKeyPool([]string{"a", "b", "c"}, time.Minute)
+	pool.now = func() time.Time { return now }
+
+	idx, _ := pool.pick()
+	pool.suspend(idx)
+
+	var got []string
+	for range 3 {
+		_, key := pool.pick()
+		got = append(got, key)
+	}
+	if expected := []string{"b", "c", "b"}; !slices.Equal(got, expected) {
+		t.Errorf("expected 'a' to be skipped during cooldown, but got %v", got)
+	}
+
+	now = now.Add(time.Minute)
+	if _, key := pool.pick(); key != "c" {
+		t.Errorf("expected 'c', but got '%s'", key)
+	}
+	if _, key := pool.pick(); key != "a" {
+		t.Errorf("expected 'a' back after the cooldown, but got '%s'", key)
+	}
+}
+
+func TestKeyPool_AllInCooldown(t *testing.T) {
+	now := time.Date(2026, time.January, 1, 12, 0, 0, 0, time.UTC)
+	pool := newKeyPool([]string{"a", "b"}, time.Minute)
+	pool.now = func() time.Time { return now }
+
+	pool.suspend(1)
+	now = now.Add(time.Second)
+	pool.suspend(0)
+
+	// Nenhuma disponível: usa a que sai do cooldown primeiro
+	if _, key := pool.pick(); key != "b" {
+		t.Errorf("expected 'b', but got '%s'", key)
+	}
+}
+
+func TestKeyPool_SingleKeyIgnoresCooldown(t *testing.T) {
+	pool := newKeyPool([]string{"a"}, time.Minute)
+
+	if pool.suspend(0) {
+		t.Error("expected a single key not to be suspended")
+	}
+	if _, key := pool.pick(); key != "a" {
+		t.Errorf("expected 'a', but got '%s'", key)
+	}
+}
+
+func TestWithAPIKeys_RotatesOnThrottle(t *testing.T) {
+	var mu sync.Mutex
+	var got []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.URL.Query().Get("key")
+		mu.Lock()
+		got = append(got, key)
+		mu.Unlock()
+
+		if key == "key-1" {
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"error":{"code":2007,"message":"API key has exceeded calls per month quota."}}`))
+			return
+		}
+		w.Write([]byte(`{"current":{"temp_c": 25.5, "temp_f": 77.9}}`))
+	}))
+	defer server.Close()
+
+	client := NewClient("", &mockLogger{}, noop.NewTracerProvider().Tracer("test"),
+		WithBaseURL(server.URL),
+		WithAPIKeys([]string{"key-1", "key-2", ""}, time.Minute),
+		WithRetry(2, time.Millisecond),
+	)
+
+	for range 3 {
+		if _, err := client.FindTemperatureByCity(context.Background(), "São Paulo"); err != nil {
+			t.Fatalf("expected no error, but got: %v", err)
+		}
+	}
+
+	// A chave com a cota esgotada é trocada na nova tentativa e depois fica de fora
+	if expected := []string{"key-1", "key-2", "key-2", "key-2"}; !slices.Equal(got, expected) {
+		t.Errorf("expected keys %v, but got %v", expected, got)
+	}
+}
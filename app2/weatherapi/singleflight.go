@@ -0,0 +1,103 @@
+package weatherapi
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// Agrupa consultas simultâneas da mesma cidade em uma única chamada à API.
+// O resultado (inclusive o erro) só é compartilhado enquanto a chamada está em andamento
+type SingleflightClient struct {
+	next  WeatherApiClient
+	group singleflight.Group
+	// Chamado depois que a consulta entra no grupo; permite aos testes liberar a API só com todos agrupados
+	joined func()
+}
+
+func NewSingleflightClient(next WeatherApiClient) *SingleflightClient {
+	return &SingleflightClient{next: next}
+}
+
+func (s *SingleflightClient) FindTemperatureByCity(ctx context.Context, city string) (*WeatherApiResponse, error) {
+	return s.FindTemperatureByLocation(ctx, city, "")
+}
+
+func (s *SingleflightClient) FindTemperatureByLocation(ctx context.Context, city, state string) (*WeatherApiResponse, error) {
+	return s.do(ctx, locationKey(city, state), func(sharedCtx context.Context) (*WeatherApiResponse, error) {
+		return s.next.FindTemperatureByLocation(sharedCtx, city, state)
+	})
+}
+
+func (s *SingleflightClient) FindTemperatureByCoords(ctx context.Context, lat, lon float64) (*WeatherApiResponse, error) {
+	return s.do(ctx, coordsQuery(lat, lon), func(sharedCtx context.Context) (*WeatherApiResponse, error) {
+		return s.next.FindTemperatureByCoords(sharedCtx, lat, lon)
+	})
+}
+
+func (s *SingleflightClient) FindForecastByLocation(ctx context.Context, city, state string, days int) (*WeatherApiResponse, error) {
+	return s.do(ctx, locationKey(city, state)+"|"+strconv.Itoa(days), func(sharedCtx context.Context) (*WeatherApiResponse, error) {
+		return s.next.FindForecastByLocation(sharedCtx, city, state, days)
+	})
+}
+
+func (s *SingleflightClient) FindForecastByCoords(ctx context.Context, lat, lon float64, days int) (*WeatherApiResponse, error) {
+	return s.do(ctx, coordsQuery(lat, lon)+"|"+strconv.Itoa(days), func(sharedCtx context.Context) (*WeatherApiResponse, error) {
+		return s.next.FindForecastByCoords(sharedCtx, lat, lon, days)
+	})
+}
+
+func (s *SingleflightClient) FindTemperatureByLocationOnDate(ctx context.Context, city, state string, date time.Time) (*WeatherApiResponse, error) {
+	return s.do(ctx, locationKey(city, state)+"|"+date.Format(DateLayout), func(sharedCtx context.Context) (*WeatherApiResponse, error) {
+		return s.next.FindTemperatureByLocationOnDate(sharedCtx, city, state, date)
+	})
+}
+
+// "São Paulo" e " sao paulo " caem na mesma chave (normalizeCity)
+func locationKey(city, state string) string {
+	return normalizeCity(city) + "|" + normalizeCity(state)
+}
+
+func (s *SingleflightClient) do(ctx context.Context, key string, fn func(context.Context) (*WeatherApiResponse, error)) (*WeatherApiResponse, error) {
+	ch := s.group.DoChan(key, func() (any, error) {
+		sharedCtx, cancel := sharedContext(ctx)
+		defer cancel()
+		return fn(sharedCtx)
+	})
+	if s.joined != nil {
+		s.joined()
+	}
+
+	select {
+	case <-ctx.Done():
+		return nil, timeoutError(ctx.Err())
+	case res := <-ch:
+		if res.Err != nil {
+			return nil, timeoutError(res.Err)
+		}
+		// Cópia por chamador, já que o ponteiro é compartilhado
+		weather := *res.Val.(*WeatherApiResponse)
+		return &weather, nil
+	}
+}
+
+// Sem o cancelamento de quem chegou primeiro, para não derrubar os demais, mas com o prazo dele: a chamada
+// compartilhada não passa do prazo da requisição que a iniciou (sem prazo, vale o timeout do cliente HTTP)
+func sharedContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	shared := context.WithoutCancel(ctx)
+	if deadline, ok := ctx.Deadline(); ok {
+		return context.WithDeadline(shared, deadline)
+	}
+	return shared, func() {}
+}
+
+// Prazo esgotado vira ErrTimeout, como no cliente, para o handler responder 504
+func timeoutError(err error) error {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return ErrTimeout
+	}
+	return err
+}
@@ -0,0 +1,167 @@
+package weatherapi
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type countingClient struct {
+	calls   atomic.Int32
+	release chan struct{}
+	err     error
+}
+
+func (c *countingClient) FindTemperatureByCity(ctx context.Context, city string) (*WeatherApiResponse, error) {
+	return c.FindTemperatureByLocation(ctx, city, "")
+}
+
+func (c *countingClient) FindTemperatureByCoords(ctx context.Context, lat, lon float64) (*WeatherApiResponse, error) {
+	return c.FindTemperatureByLocation(ctx, "", "")
+}
+
+func (c *countingClient) FindForecastByLocation(ctx context.Context, city, state string, days int) (*WeatherApiResponse, error) {
+	return c.FindTemperatureByLocation(ctx, city, state)
+}
+
+func (c *countingClient) FindForecastByCoords(ctx context.Context, lat, lon float64, days int) (*WeatherApiResponse, error) {
+	return c.FindTemperatureByLocation(ctx, "", "")
+}
+
+func (c *countingClient) FindTemperatureByLocationOnDate(ctx context.Context, city, state string, date time.Time) (*WeatherApiResponse, error) {
+	return c.FindTemperatureByLocation(ctx, city, state)
+}
+
+func (c *countingClient) FindTemperatureByLocation(ctx context.Context, city, state string) (*WeatherApiResponse, error) {
+	c.calls.Add(1)
+	if c.release != nil {
+		<-c.release
+	}
+	if c.err != nil {
+		return nil, c.err
+	}
+	return &WeatherApiResponse{Current: CurrentWeather{TempC: 25}}, nil
+}
+
+func TestSingleflightClient_DedupesConcurrentCalls(t *testing.T) {
+	upstream := &countingClient{release: make(chan struct{})}
+	client := NewSingleflightClient(upstream)
+
+	const n = 20
+	var entered sync.WaitGroup
+	entered.Add(n)
+	client.joined = entered.Done
+
+	var wg sync.WaitGroup
+	var failures atomic.Int32
+	for i := range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			city := "São Paulo"
+			if i%2 == 0 {
+				city = " são paulo "
+			}
+			if weather, err := client.FindTemperatureByCity(context.Background(), city); err != nil || weather.Current.TempC != 25 {
+				failures.Add(1)
+			}
+		}()
+	}
+
+	// A API só responde depois que todas as consultas entraram no grupo
+	entered.Wait()
+	close(upstream.release)
+	wg.Wait()
+
+	if got := upstream.calls.Load(); got != 1 {
+		t.Errorf("expected upstream to be called once, but got %d", got)
+	}
+
+	if failures.Load() != 0 {
+		t.Errorf("expected all callers to get the shared result, but %d failed", failures.Load())
+	}
+}
+
+func TestSingleflightClient_CityVariantsShareKey(t *testing.T) {
+	upstream := &countingClient{release: make(chan struct{})}
+	client := NewSingleflightClient(upstream)
+
+	cities := []string{"São Paulo", "sao paulo", " SÃO PAULO "}
+	var entered sync.WaitGroup
+	entered.Add(len(cities))
+	client.joined = entered.Done
+
+	var wg sync.WaitGroup
+	for _, city := range cities {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			client.FindTemperatureByLocation(context.Background(), city, "SP")
+		}()
+	}
+
+	// A API só responde depois que todas as consultas entraram no grupo
+	entered.Wait()
+	close(upstream.release)
+	wg.Wait()
+
+	if got := upstream.calls.Load(); got != 1 {
+		t.Errorf("expected the city variants to share one upstream call, but got %d", got)
+	}
+
+	// A UF continua fazendo parte da chave
+	if locationKey("São Paulo", "SP") == locationKey("São Paulo", "RJ") {
+		t.Error("expected different states to use different keys")
+	}
+}
+
+func TestSingleflightClient_DoesNotCacheErrors(t *testing.T) {
+	upstream := &countingClient{err: ErrInternal}
+	client := NewSingleflightClient(upstream)
+
+	for range 2 {
+		if _, err := client.FindTemperatureByCity(context.Background(), "São Paulo"); err != ErrInternal {
+			t.Fatalf("expected error '%v', but got '%v'", ErrInternal, err)
+		}
+	}
+
+	if got := upstream.calls.Load(); got != 2 {
+		t.Errorf("expected 2 upstream calls, but got %d", got)
+	}
+}
+
+// Bloqueia até o contexto da chamada compartilhada acabar e informa o motivo
+type blockingClient struct {
+	countingClient
+	done chan error
+}
+
+func (c *blockingClient) FindTemperatureByLocation(ctx context.Context, city, state string) (*WeatherApiResponse, error) {
+	<-ctx.Done()
+	c.done <- ctx.Err()
+	return nil, ctx.Err()
+}
+
+func TestSingleflightClient_CallerDeadline(t *testing.T) {
+	upstream := &blockingClient{done: make(chan error, 1)}
+	client := NewSingleflightClient(upstream)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	if _, err := client.FindTemperatureByLocation(ctx, "São Paulo", "SP"); err != ErrTimeout {
+		t.Errorf("expected error '%v', but got '%v'", ErrTimeout, err)
+	}
+
+	// A chamada compartilhada herda o prazo de quem a iniciou, em vez de seguir sem limite
+	select {
+	case err := <-upstream.done:
+		if err != context.DeadlineExceeded {
+			t.Errorf("expected the upstream context to expire, but got '%v'", err)
+		}
+	case <-time.After(time.Second):
+		t.Error("expected the upstream call to be cancelled at the caller deadline")
+	}
+}
@@ -0,0 +1,66 @@
+package weatherapi
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// Cidades consultadas por ValidateKeys e Ping; qualquer localização conhecida serve
+const (
+	validationQuery = "London"
+	pingQuery       = "São Paulo"
+)
+
+var ErrInvalidAPIKey = fmt.Errorf("chave da WeatherAPI recusada")
+
+// Faz uma consulta com cada chave, sem retry nem circuit breaker, e retorna ErrInvalidAPIKey se alguma
+// for recusada (401/403). Falhas de rede e 5xx retornam o próprio erro, sem concluir nada sobre a chave
+func (c *Client) ValidateKeys(ctx context.Context) error {
+	for idx, key := range c.keys.keys {
+		status, err := c.probe(ctx, validationQuery, key)
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case status == http.StatusUnauthorized || status == http.StatusForbidden:
+			return fmt.Errorf("%w: key index %d, status %d", ErrInvalidAPIKey, idx, status)
+		case status >= http.StatusInternalServerError:
+			return fmt.Errorf("WeatherAPI returned status %d", status)
+		}
+	}
+	return nil
+}
+
+// Verifica se a WeatherAPI responde, com a chave da vez e uma requisição direta: sem retry, limite de
+// concorrência nem circuit breaker, que esconderiam o estado real da API (para o /ready)
+func (c *Client) Ping(ctx context.Context) error {
+	_, key := c.keys.pick()
+	status, err := c.probe(ctx, pingQuery, key)
+	if err != nil {
+		return err
+	}
+	if status != http.StatusOK {
+		return fmt.Errorf("WeatherAPI returned status %d", status)
+	}
+	return nil
+}
+
+// Consulta o clima atual de query com a chave informada, devolvendo só o status; o erro já vem sem a chave
+func (c *Client) probe(ctx context.Context, query, key string) (int, error) {
+	u := c.baseURL + "/current.json?" + url.Values{"q": {query}, "key": {key}}.Encode()
+	req, err := http.NewRequestWithContext(ctx, "GET", u, nil)
+	if err != nil {
+		return 0, redactError(err)
+	}
+	req.Header.Set("User-Agent", c.userAgent)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return 0, redactError(err)
+	}
+	resp.Body.Close()
+	return resp.StatusCode, nil
+}
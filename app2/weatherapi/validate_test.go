@@ -0,0 +1,102 @@
+package weatherapi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+func TestValidateKeys(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("q") != validationQuery {
+			t.Errorf("expected query '%s', but got '%s'", validationQuery, r.URL.Query().Get("q"))
+		}
+		switch r.URL.Query().Get("key") {
+		case "valid-key":
+			w.Write([]byte(`{"current":{"temp_c": 12}}`))
+		case "disabled-key":
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"error":{"code":2008,"message":"API key has been disabled."}}`))
+		default:
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"error":{"code":2006,"message":"API key is invalid."}}`))
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name    string
+		keys    []string
+		invalid bool
+	}{
+		{"valid", []string{"valid-key"}, false},
+		{"forbidden", []string{"disabled-key"}, true},
+		{"unauthorized", []string{"wrong-key"}, true},
+		{"one of many rejected", []string{"valid-key", "disabled-key"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := NewClient(tt.keys[0], &mockLogger{}, noop.NewTracerProvider().Tracer("test"),
+				WithBaseURL(server.URL), WithAPIKeys(tt.keys, 0))
+
+			err := client.ValidateKeys(context.Background())
+			if got := errors.Is(err, ErrInvalidAPIKey); got != tt.invalid {
+				t.Errorf("expected invalid key %v, but got error '%v'", tt.invalid, err)
+			}
+			if !tt.invalid && err != nil {
+				t.Errorf("expected no error, but got: %v", err)
+			}
+		})
+	}
+}
+
+func TestValidateKeys_UpstreamUnavailable(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	client := NewClient("fake-api-key", &mockLogger{}, noop.NewTracerProvider().Tracer("test"), WithBaseURL(server.URL))
+
+	// Sem resposta conclusiva, a chave não é dada como inválida
+	err := client.ValidateKeys(context.Background())
+	if err == nil || errors.Is(err, ErrInvalidAPIKey) {
+		t.Errorf("expected an error other than '%v', but got '%v'", ErrInvalidAPIKey, err)
+	}
+}
+
+func TestPing(t *testing.T) {
+	var status atomic.Int32
+	status.Store(http.StatusOK)
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(int(status.Load()))
+	}))
+	defer server.Close()
+
+	client := NewClient("key", &mockLogger{}, noop.NewTracerProvider().Tracer("test"),
+		WithBaseURL(server.URL), WithCircuitBreaker(1, time.Minute))
+
+	if err := client.Ping(context.Background()); err != nil {
+		t.Fatalf("expected no error, but got: %v", err)
+	}
+
+	// Com o circuito aberto, o Ping continua indo à API em vez de falhar na hora
+	status.Store(http.StatusServiceUnavailable)
+	client.FindTemperatureByCity(context.Background(), "São Paulo")
+	calls.Store(0)
+	if err := client.Ping(context.Background()); err == nil {
+		t.Error("expected an error while the WeatherAPI is down")
+	}
+	if got := calls.Load(); got != 1 {
+		t.Errorf("expected 1 upstream call, but got %d", got)
+	}
+}
@@ -3,131 +3,659 @@ package weatherapi
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"math"
 	"net/http"
 	"net/url"
+	"strconv"
+	"strings"
 	"time"
 
-	"go.opentelemetry.io/otel/attribute"
+	"l02-02/backoff"
+	"l02-02/circuitbreaker"
+	"l02-02/httpx"
+	"l02-02/limiter"
+	"l02-02/version"
 
-	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
-	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
 	"go.opentelemetry.io/otel/trace"
 )
 
 var (
 	ErrCityNotFound = fmt.Errorf("cidade não encontrada")
 	ErrInternal     = fmt.Errorf("ocorreu um erro interno ao buscar o clima")
+	ErrTimeout      = fmt.Errorf("tempo esgotado ao buscar o clima")
+	ErrInvalidDays  = fmt.Errorf("quantidade de dias da previsão inválida")
+	ErrInvalidDate  = fmt.Errorf("data do histórico fora do intervalo aceito")
+	ErrRateLimited  = fmt.Errorf("limite de requisições da WeatherAPI atingido")
+)
+
+// 429 da WeatherAPI; errors.Is(err, ErrRateLimited) vale. RetryAfter é zero quando a API não informa o Retry-After
+type RateLimitError struct {
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitError) Error() string {
+	return ErrRateLimited.Error()
+}
+
+func (e *RateLimitError) Unwrap() error {
+	return ErrRateLimited
+}
+
+// Limite de dias da previsão no plano gratuito da WeatherAPI
+const MaxForecastDays = 3
+
+// Faixa usada por WithSanityCheck, com folga sobre os recordes registrados na Terra
+const (
+	MinPlausibleTempC = -90.0
+	MaxPlausibleTempC = 60.0
 )
 
+// Formato do parâmetro dt do history.json
+const DateLayout = "2006-01-02"
+
+// A WeatherAPI só tem histórico a partir desta data
+var MinHistoryDate = time.Date(2010, time.January, 1, 0, 0, 0, 0, time.UTC)
+
 type WeatherApiClient interface {
 	FindTemperatureByCity(ctx context.Context, city string) (*WeatherApiResponse, error)
+	// Com a UF, evita a cidade homônima de outro estado; state vazio equivale a FindTemperatureByCity
+	FindTemperatureByLocation(ctx context.Context, city, state string) (*WeatherApiResponse, error)
+	FindTemperatureByCoords(ctx context.Context, lat, lon float64) (*WeatherApiResponse, error)
+	// Mesmas consultas no forecast.json, com a previsão de 1 a MaxForecastDays dias além do clima atual
+	FindForecastByLocation(ctx context.Context, city, state string, days int) (*WeatherApiResponse, error)
+	FindForecastByCoords(ctx context.Context, lat, lon float64, days int) (*WeatherApiResponse, error)
+	// Clima de um dia passado (history.json), entre MinHistoryDate e hoje
+	FindTemperatureByLocationOnDate(ctx context.Context, city, state string, date time.Time) (*WeatherApiResponse, error)
 }
 
+// Subconjunto do *slog.Logger usado pelo cliente
 type Logger interface {
-	Printf(format string, v ...interface{})
+	WarnContext(ctx context.Context, msg string, args ...any)
+	ErrorContext(ctx context.Context, msg string, args ...any)
+}
+
+type Metrics interface {
+	// statusClass é o de httpx.StatusClass
+	ObserveOutbound(upstream, statusClass string, duration time.Duration)
 }
 
 type Client struct {
-	apiKey     string
+	keys       *keyPool
 	httpClient *http.Client
+	timeout    time.Duration
+	transport  httpx.TransportConfig
 	logger     Logger
 	baseURL    string
-	tracer     trace.Tracer
+	userAgent  string
+	aqi        bool
+	// Rejeita temperaturas fora da faixa plausível (WithSanityCheck)
+	sanityCheck bool
+	tracer      trace.Tracer
+	retry       backoff.Policy
+	breaker     *circuitbreaker.Breaker
+	limiter     *limiter.Limiter
+	metrics     Metrics
+	// Teto do corpo das respostas, já descomprimido (WithMaxResponseBytes)
+	maxBodyBytes int64
 }
 
+type Option func(*Client)
+
 type CurrentWeather struct {
 	TempC float64 `json:"temp_c"`
 	TempF float64 `json:"temp_f"`
+	// Opcionais: ponteiros para distinguir ausência de zero
+	Humidity  *int      `json:"humidity"`
+	WindKph   *float64  `json:"wind_kph"`
+	Condition Condition `json:"condition"`
+	// Presente apenas com WithAQI
+	AirQuality *AirQuality `json:"air_quality"`
+}
+
+type AirQuality struct {
+	PM25       float64 `json:"pm2_5"`
+	PM10       float64 `json:"pm10"`
+	USEPAIndex int     `json:"us-epa-index"`
+}
+
+type Condition struct {
+	Text string `json:"text"`
+}
+
+type Location struct {
+	Name      string  `json:"name"`
+	Region    string  `json:"region"`
+	Country   string  `json:"country"`
+	Lat       float64 `json:"lat"`
+	Lon       float64 `json:"lon"`
+	Localtime string  `json:"localtime"`
 }
 
 type WeatherApiResponse struct {
-	Current CurrentWeather `json:"current"`
-	Erro    bool           `json:"erro"`
+	Location Location       `json:"location"`
+	Current  CurrentWeather `json:"current"`
+	// Presente apenas nas consultas ao forecast.json
+	Forecast *Forecast `json:"forecast"`
+}
+
+// Envelope de erro da WeatherAPI, enviado com 400/401/403 (e, em alguns casos, com 200):
+// {"error": {"code": 1006, "message": "No matching location found."}}
+type apiError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// Código da WeatherAPI para a localização não encontrada; os demais (chave inválida, cota...) são falhas do serviço
+const codeNoMatchingLocation = 1006
+
+type Forecast struct {
+	ForecastDay []ForecastDay `json:"forecastday"`
+}
+
+type ForecastDay struct {
+	Date string     `json:"date"`
+	Day  DayWeather `json:"day"`
 }
 
-// Necessário para sobrescrever dados da URL
-type redactingTransport struct {
-	base http.RoundTripper
+type DayWeather struct {
+	MaxTempC          float64   `json:"maxtemp_c"`
+	MaxTempF          float64   `json:"maxtemp_f"`
+	MinTempC          float64   `json:"mintemp_c"`
+	MinTempF          float64   `json:"mintemp_f"`
+	AvgTempC          float64   `json:"avgtemp_c"`
+	AvgTempF          float64   `json:"avgtemp_f"`
+	DailyChanceOfRain int       `json:"daily_chance_of_rain"`
+	Condition         Condition `json:"condition"`
 }
 
-// Não expor chave de API na URL
-func (t *redactingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
-	span := trace.SpanFromContext(req.Context())
+// Erros do http.Client (*url.Error) trazem a URL completa, inclusive a chave
+func redactError(err error) error {
+	var urlErr *url.Error
+	if !errors.As(err, &urlErr) {
+		return err
+	}
+
+	u, parseErr := url.Parse(urlErr.URL)
+	if parseErr != nil {
+		return &url.Error{Op: urlErr.Op, URL: "***", Err: urlErr.Err}
+	}
+	sanitized, _ := httpx.RedactQuery(u, "key")
+	return &url.Error{Op: urlErr.Op, URL: sanitized, Err: urlErr.Err}
+}
 
-	if req.URL != nil {
-		q := req.URL.Query()
-		if q.Get("key") != "" {
-			sanitizedURL := *req.URL
-			newQuery := req.URL.Query()
-			newQuery.Set("key", "***")
-			sanitizedURL.RawQuery = newQuery.Encode()
-			sanitizedURLString := sanitizedURL.String()
-			span.SetAttributes(
-				semconv.HTTPURLKey.String(sanitizedURLString),
-				semconv.URLFullKey.String(sanitizedURLString),
-			)
+// Habilita novas tentativas em erros de rede, 5xx e 429 (valores <= 0 usam os padrões)
+func WithRetry(maxAttempts int, baseDelay time.Duration) Option {
+	if maxAttempts <= 0 {
+		maxAttempts = defaultRetryMaxAttempts
+	}
+	if baseDelay <= 0 {
+		baseDelay = defaultRetryBaseDelay
+	}
+	return func(c *Client) {
+		c.retry = backoff.Policy{MaxAttempts: maxAttempts, BaseDelay: baseDelay, MaxDelay: defaultRetryMaxDelay}
+	}
+}
+
+// Tempo máximo de cada tentativa de requisição à API externa (ignorado junto com WithHTTPClient)
+func WithTimeout(d time.Duration) Option {
+	return func(c *Client) {
+		if d > 0 {
+			c.timeout = d
 		}
 	}
+}
 
-	return t.base.RoundTrip(req)
+// Inclui a qualidade do ar (aqi=yes) nas consultas; desligado por padrão para não aumentar a resposta
+func WithAQI(enabled bool) Option {
+	return func(c *Client) {
+		c.aqi = enabled
+	}
 }
 
-func NewClient(apiKey string, logger Logger, tracer trace.Tracer) *Client {
-	otelTransport := otelhttp.NewTransport(&redactingTransport{base: http.DefaultTransport})
-	return &Client{
-		apiKey:     apiKey,
-		httpClient: &http.Client{Transport: otelTransport, Timeout: 5 * time.Second},
-		baseURL:    "https://api.weatherapi.com/v1",
-		logger:     logger,
-		tracer:     tracer,
+// Trata temperaturas fora de [MinPlausibleTempC, MaxPlausibleTempC] como resposta inválida (ErrInternal)
+func WithSanityCheck(enabled bool) Option {
+	return func(c *Client) {
+		c.sanityCheck = enabled
 	}
 }
 
+// Distribui as requisições entre várias chaves em rodízio; a chave que recebe 429/403
+// fica de fora por cooldown (<= 0 usa o padrão). Chaves vazias são ignoradas
+func WithAPIKeys(keys []string, cooldown time.Duration) Option {
+	if cooldown <= 0 {
+		cooldown = defaultKeyCooldown
+	}
+	var valid []string
+	for _, k := range keys {
+		if k != "" {
+			valid = append(valid, k)
+		}
+	}
+	return func(c *Client) {
+		if len(valid) > 0 {
+			c.keys = newKeyPool(valid, cooldown)
+		}
+	}
+}
+
+// Pool de conexões do cliente padrão (ignorado junto com WithHTTPClient)
+func WithTransportConfig(cfg httpx.TransportConfig) Option {
+	return func(c *Client) {
+		c.transport = cfg
+	}
+}
+
+// Identifica o serviço para a API externa (padrão: l02-app2/<versão>)
+func WithUserAgent(userAgent string) Option {
+	return func(c *Client) {
+		if userAgent != "" {
+			c.userAgent = userAgent
+		}
+	}
+}
+
+// Sobrescreve a URL base da API (útil para testes e ambientes de homologação)
+func WithBaseURL(baseURL string) Option {
+	return func(c *Client) {
+		if baseURL != "" {
+			c.baseURL = strings.TrimSuffix(baseURL, "/")
+		}
+	}
+}
+
+// Usa o cliente informado como está: sem o transporte de tracing/redação da chave
+// e sem aplicar WithTimeout, que só vale para o cliente padrão
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// Registra a latência das chamadas à API externa
+func WithMetrics(m Metrics) Option {
+	return func(c *Client) {
+		c.metrics = m
+	}
+}
+
+// Limita as requisições simultâneas à API externa (max <= 0 desabilita); com a vaga ocupada,
+// espera ou, com failFast, falha na hora com limiter.ErrOverloaded
+func WithConcurrencyLimit(max int, failFast bool) Option {
+	return func(c *Client) {
+		if max > 0 {
+			c.limiter = limiter.New(max, failFast)
+		}
+	}
+}
+
+// Tamanho máximo do corpo das respostas, já descomprimido (n <= 0 mantém httpx.DefaultMaxResponseBytes)
+func WithMaxResponseBytes(n int64) Option {
+	return func(c *Client) {
+		if n > 0 {
+			c.maxBodyBytes = n
+		}
+	}
+}
+
+// Protege a API externa com um circuit breaker (valores <= 0 usam os padrões)
+func WithCircuitBreaker(threshold int, cooldown time.Duration) Option {
+	if threshold <= 0 {
+		threshold = defaultBreakerThreshold
+	}
+	if cooldown <= 0 {
+		cooldown = defaultBreakerCooldown
+	}
+	return func(c *Client) {
+		c.breaker = circuitbreaker.New(threshold, cooldown)
+	}
+}
+
+func NewClient(apiKey string, logger Logger, tracer trace.Tracer, opts ...Option) *Client {
+	c := &Client{
+		timeout:   5 * time.Second,
+		userAgent: version.UserAgent(),
+		baseURL:   "https://api.weatherapi.com/v1",
+		logger:    logger,
+		tracer:    tracer,
+		retry:     backoff.Policy{MaxAttempts: 1},
+		// Sem teto, uma API externa maliciosa poderia esgotar a memória com um corpo gigante
+		maxBodyBytes: httpx.DefaultMaxResponseBytes,
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	if c.keys == nil {
+		c.keys = newKeyPool([]string{apiKey}, defaultKeyCooldown)
+	}
+
+	if c.httpClient == nil {
+		// Não expor a chave de API na URL registrada no span
+		c.httpClient = httpx.NewInstrumentedClient(httpx.WithTimeout(c.timeout), httpx.WithTransportConfig(c.transport), httpx.WithRedactedQueryParams("key"))
+	}
+
+	return c
+}
+
+// Circuit breaker do cliente (nil sem WithCircuitBreaker), para expor o estado no /health
+func (c *Client) Breaker() *circuitbreaker.Breaker {
+	return c.breaker
+}
+
 func (c *Client) FindTemperatureByCity(ctx context.Context, city string) (*WeatherApiResponse, error) {
-	ctx, span := c.tracer.Start(ctx, "FindTemperatureByCity")
-	span.SetAttributes(attribute.String("city.name", city))
+	return c.FindTemperatureByLocation(ctx, city, "")
+}
+
+func (c *Client) FindTemperatureByLocation(ctx context.Context, city, state string) (*WeatherApiResponse, error) {
+	ctx, span := c.tracer.Start(ctx, "FindTemperatureByLocation")
+	span.SetAttributes(attribute.String("city.name", city), attribute.String("city.state", state))
 	defer span.End()
 
-	baseURL, err := url.Parse(c.baseURL)
-	if err != nil {
-		span.RecordError(err)
-		c.logger.Printf("Invalid base URL: %v", err)
-		return nil, ErrInternal
+	return c.fetch(ctx, span, "/current.json", c.queryParams(locationQuery(city, state)))
+}
+
+// Mais preciso que o nome da cidade, quando o provedor de CEP informa as coordenadas
+func (c *Client) FindTemperatureByCoords(ctx context.Context, lat, lon float64) (*WeatherApiResponse, error) {
+	ctx, span := c.tracer.Start(ctx, "FindTemperatureByCoords")
+	span.SetAttributes(attribute.Float64("location.lat", lat), attribute.Float64("location.lon", lon))
+	defer span.End()
+
+	return c.fetch(ctx, span, "/current.json", c.queryParams(coordsQuery(lat, lon)))
+}
+
+func (c *Client) FindForecastByLocation(ctx context.Context, city, state string, days int) (*WeatherApiResponse, error) {
+	ctx, span := c.tracer.Start(ctx, "FindForecastByLocation")
+	span.SetAttributes(attribute.String("city.name", city), attribute.String("city.state", state), attribute.Int("forecast.days", days))
+	defer span.End()
+
+	if !ValidForecastDays(days) {
+		return nil, ErrInvalidDays
+	}
+	params := c.queryParams(locationQuery(city, state))
+	params.Set("days", strconv.Itoa(days))
+	return c.fetch(ctx, span, "/forecast.json", params)
+}
+
+func (c *Client) FindForecastByCoords(ctx context.Context, lat, lon float64, days int) (*WeatherApiResponse, error) {
+	ctx, span := c.tracer.Start(ctx, "FindForecastByCoords")
+	span.SetAttributes(attribute.Float64("location.lat", lat), attribute.Float64("location.lon", lon), attribute.Int("forecast.days", days))
+	defer span.End()
+
+	if !ValidForecastDays(days) {
+		return nil, ErrInvalidDays
+	}
+	params := c.queryParams(coordsQuery(lat, lon))
+	params.Set("days", strconv.Itoa(days))
+	return c.fetch(ctx, span, "/forecast.json", params)
+}
+
+func (c *Client) FindTemperatureByCityOnDate(ctx context.Context, city string, date time.Time) (*WeatherApiResponse, error) {
+	return c.FindTemperatureByLocationOnDate(ctx, city, "", date)
+}
+
+// O dia histórico vem em Forecast.ForecastDay[0]; a resposta não tem o bloco current
+func (c *Client) FindTemperatureByLocationOnDate(ctx context.Context, city, state string, date time.Time) (*WeatherApiResponse, error) {
+	ctx, span := c.tracer.Start(ctx, "FindTemperatureByLocationOnDate")
+	span.SetAttributes(attribute.String("city.name", city), attribute.String("city.state", state), attribute.String("history.date", date.Format(DateLayout)))
+	defer span.End()
+
+	if !ValidHistoryDate(date, time.Now()) {
+		return nil, ErrInvalidDate
 	}
 
-	baseURL.Path += "/current.json"
 	params := url.Values{}
-	params.Add("key", c.apiKey)
-	params.Add("q", city)
+	params.Set("q", locationQuery(city, state))
+	params.Set("dt", date.Format(DateLayout))
+	return c.fetch(ctx, span, "/history.json", params)
+}
+
+// Compara só o dia do calendário: nem antes de MinHistoryDate, nem depois de hoje
+func ValidHistoryDate(date, now time.Time) bool {
+	day := date.Format(DateLayout)
+	return day >= MinHistoryDate.Format(DateLayout) && day <= now.Format(DateLayout)
+}
+
+func ValidForecastDays(days int) bool {
+	return days >= 1 && days <= MaxForecastDays
+}
+
+// Parâmetros comuns ao current.json e ao forecast.json
+func (c *Client) queryParams(q string) url.Values {
+	params := url.Values{}
+	params.Set("q", q)
+	if c.aqi {
+		params.Set("aqi", "yes")
+	}
+	return params
+}
 
-	baseURL.RawQuery = params.Encode()
-	fullURL := baseURL.String()
-	req, err := http.NewRequestWithContext(ctx, "GET", fullURL, nil)
+// Consulta o endpoint da WeatherAPI com os parâmetros já montados; a chave é acrescentada a cada tentativa
+func (c *Client) fetch(ctx context.Context, span trace.Span, endpoint string, params url.Values) (*WeatherApiResponse, error) {
+	q := params.Get("q")
+	baseURL, err := url.Parse(c.baseURL)
 	if err != nil {
 		span.RecordError(err)
-		return nil, err
+		span.SetStatus(codes.Error, "invalid base URL")
+		c.logger.ErrorContext(ctx, "invalid base URL", "error", err)
+		return nil, ErrInternal
 	}
 
-	resp, err := c.httpClient.Do(req)
+	baseURL.Path += endpoint
+	start := time.Now()
+	resp, err := c.do(ctx, span, baseURL, params)
 	if err != nil {
+		err = redactError(err)
 		span.RecordError(err)
-		c.logger.Printf("Error requesting from WeatherAPI: %v", err)
+		span.SetStatus(codes.Error, "WeatherAPI request failed")
+		c.logger.ErrorContext(ctx, "error requesting from WeatherAPI", "query", q, "duration_ms", time.Since(start).Milliseconds(), "error", err)
+		if httpx.IsTimeout(err) {
+			return nil, ErrTimeout
+		}
+		if errors.Is(err, limiter.ErrOverloaded) {
+			return nil, limiter.ErrOverloaded
+		}
 		return nil, ErrInternal
 	}
 	defer resp.Body.Close()
 
+	// Limite de requisições não é cidade não encontrada: o chamador deve esperar e tentar de novo
+	if resp.StatusCode == http.StatusTooManyRequests {
+		retryAfter := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+		span.AddEvent("WeatherAPI rate limited", trace.WithAttributes(attribute.Int64("retry_after_s", int64(retryAfter.Seconds()))))
+		span.SetStatus(codes.Error, "WeatherAPI rate limited")
+		c.logger.WarnContext(ctx, "WeatherAPI rate limited", "query", q, "retry_after", retryAfter.String())
+		return nil, &RateLimitError{RetryAfter: retryAfter}
+	}
+
+	var data struct {
+		WeatherApiResponse
+		Error *apiError `json:"error"`
+	}
+	decodeErr := decodeBody(resp, c.maxBodyBytes, &data)
+
+	if data.Error != nil {
+		return nil, c.upstreamError(ctx, span, q, resp.StatusCode, data.Error)
+	}
+
 	if resp.StatusCode != http.StatusOK {
+		// Sem o envelope de erro: 400/404 seguem como cidade não encontrada, o resto é falha da API
 		span.AddEvent("WeatherAPI returned non-OK status")
-		return nil, ErrCityNotFound
+		span.SetStatus(codes.Error, fmt.Sprintf("WeatherAPI returned status %d", resp.StatusCode))
+		if resp.StatusCode == http.StatusBadRequest || resp.StatusCode == http.StatusNotFound {
+			return nil, ErrCityNotFound
+		}
+		return nil, ErrInternal
 	}
 
-	var data WeatherApiResponse
-	if err := json.NewDecoder(resp.Body).Decode(&data); err != nil {
-		span.RecordError(err)
-		c.logger.Printf("Error decoding WeatherAPI response: %v", err)
+	if decodeErr != nil {
+		span.RecordError(decodeErr)
+		span.SetStatus(codes.Error, "invalid WeatherAPI response")
+		c.logger.ErrorContext(ctx, "error decoding WeatherAPI response", "query", q, "status", resp.StatusCode, "error", decodeErr)
 		return nil, ErrInternal
 	}
 
-	return &data, nil
+	if c.sanityCheck {
+		if temp, ok := implausibleTemp(&data.WeatherApiResponse); ok {
+			span.AddEvent("implausible temperature", trace.WithAttributes(attribute.Float64("weather.temp_c", temp)))
+			span.SetStatus(codes.Error, "implausible WeatherAPI temperature")
+			c.logger.ErrorContext(ctx, "WeatherAPI returned implausible temperature", "query", q, "temp_c", temp)
+			return nil, ErrInternal
+		}
+	}
+
+	return &data.WeatherApiResponse, nil
+}
+
+// Traduz o envelope de erro: 1006 é ErrCityNotFound, os demais códigos indicam problema de configuração ou da API
+func (c *Client) upstreamError(ctx context.Context, span trace.Span, q string, status int, apiErr *apiError) error {
+	span.SetAttributes(attribute.Int("weatherapi.error_code", apiErr.Code))
+	if apiErr.Code == codeNoMatchingLocation {
+		span.AddEvent("city not found")
+		span.SetStatus(codes.Error, apiErr.Message)
+		return ErrCityNotFound
+	}
+
+	span.SetStatus(codes.Error, fmt.Sprintf("WeatherAPI error %d: %s", apiErr.Code, apiErr.Message))
+	c.logger.ErrorContext(ctx, "WeatherAPI returned an error", "query", q, "status", status, "code", apiErr.Code, "message", apiErr.Message)
+	return ErrInternal
+}
+
+// Primeira temperatura fora da faixa plausível, no clima atual ou nos dias da previsão/histórico
+func implausibleTemp(data *WeatherApiResponse) (float64, bool) {
+	temps := []float64{data.Current.TempC}
+	if data.Forecast != nil {
+		for _, d := range data.Forecast.ForecastDay {
+			temps = append(temps, d.Day.MaxTempC, d.Day.MinTempC, d.Day.AvgTempC)
+		}
+	}
+
+	for _, t := range temps {
+		if t < MinPlausibleTempC || t > MaxPlausibleTempC || math.IsNaN(t) {
+			return t, true
+		}
+	}
+	return 0, false
+}
+
+// Retry-After em segundos ou como data HTTP; ausente, inválido ou no passado vira zero
+func parseRetryAfter(v string, now time.Time) time.Duration {
+	if v == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(v); err == nil {
+		return max(time.Duration(seconds)*time.Second, 0)
+	}
+	if date, err := http.ParseTime(v); err == nil {
+		return max(date.Sub(now).Truncate(time.Second), 0)
+	}
+	return 0
+}
+
+// "Cidade,UF,Brazil" quando a UF é conhecida
+func locationQuery(city, state string) string {
+	if state == "" {
+		return city
+	}
+	return city + "," + state + ",Brazil"
+}
+
+// "lat,lon" em graus decimais
+func coordsQuery(lat, lon float64) string {
+	return strconv.FormatFloat(lat, 'f', -1, 64) + "," + strconv.FormatFloat(lon, 'f', -1, 64)
+}
+
+// Executa a requisição aplicando a política de novas tentativas sem ultrapassar o prazo do contexto.
+// Cada tentativa usa a próxima chave do rodízio, então a chave limitada não é repetida na nova tentativa
+func (c *Client) do(ctx context.Context, span trace.Span, u *url.URL, params url.Values) (*http.Response, error) {
+	var resp *http.Response
+	policy := c.retry
+	policy.OnRetry = func(attempt int, delay time.Duration) {
+		if resp != nil {
+			resp.Body.Close()
+			resp = nil
+		}
+		span.AddEvent("retry", trace.WithAttributes(
+			attribute.Int("retry.attempt", attempt),
+			attribute.Int64("retry.delay_ms", delay.Milliseconds()),
+		))
+	}
+
+	err := backoff.Retry(ctx, policy, func(attempt int) (bool, error) {
+		keyIdx, key := c.keys.pick()
+		params.Set("key", key)
+		u.RawQuery = params.Encode()
+		req, err := http.NewRequestWithContext(ctx, "GET", u.String(), nil)
+		if err != nil {
+			return false, err
+		}
+		req.Header.Set("User-Agent", c.userAgent)
+		httpx.AcceptGzip(req)
+
+		var release func()
+		if c.limiter != nil {
+			release, err = c.limiter.Acquire(ctx, span)
+			if err != nil {
+				return false, err
+			}
+		}
+
+		var gen circuitbreaker.Generation
+		if c.breaker != nil {
+			var transition circuitbreaker.Transition
+			transition, gen, err = c.breaker.Allow()
+			circuitbreaker.RecordTransition(span, transition)
+			if err != nil {
+				if release != nil {
+					release()
+				}
+				return false, err
+			}
+		}
+
+		start := time.Now()
+		resp, err = c.httpClient.Do(req)
+		if release != nil {
+			if err != nil {
+				release()
+			} else {
+				resp.Body = limiter.ReleaseOnClose(resp.Body, release)
+			}
+		}
+		if c.metrics != nil {
+			c.metrics.ObserveOutbound("weatherapi", httpx.StatusClass(resp, err), time.Since(start))
+		}
+		if c.breaker != nil {
+			// Cancelamento ou prazo do chamador (hedge do app1, deadline propagado) não dizem nada sobre o upstream
+			if ctx.Err() != nil {
+				c.breaker.Abandon(gen)
+			} else {
+				circuitbreaker.RecordTransition(span, c.breaker.RecordResponse(gen, resp, redactError(err)))
+			}
+		}
+
+		// Com outra chave disponível, o 403 também vale uma nova tentativa
+		rotated := false
+		if err == nil && keyThrottled(resp.StatusCode) && c.keys.suspend(keyIdx) {
+			rotated = true
+			span.AddEvent("api key cooldown", trace.WithAttributes(attribute.Int("weatherapi.key_index", keyIdx)))
+			c.logger.WarnContext(ctx, "WeatherAPI key throttled, skipping it during cooldown", "key_index", keyIdx, "status", resp.StatusCode)
+		}
+
+		return rotated || retryable(ctx, resp, err), err
+	})
+	return resp, err
+}
+
+// Decodifica o JSON do corpo descomprimido e limitado por httpx.ResponseBody
+func decodeBody(resp *http.Response, max int64, v any) error {
+	body, err := httpx.ResponseBody(resp, max)
+	if err != nil {
+		return err
+	}
+	return json.NewDecoder(body).Decode(v)
 }
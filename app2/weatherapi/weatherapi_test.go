@@ -1,17 +1,34 @@
 package weatherapi
 
 import (
+	"bytes"
+	"compress/gzip"
 	"context"
+	"errors"
+	"log/slog"
 	"net/http"
 	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	"l02-02/circuitbreaker"
+	"l02-02/httpx"
+
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 	"go.opentelemetry.io/otel/trace/noop"
 )
 
 type mockLogger struct{}
 
-func (m *mockLogger) Printf(format string, v ...interface{}) {}
+func (m *mockLogger) WarnContext(ctx context.Context, msg string, args ...any) {}
+
+func (m *mockLogger) ErrorContext(ctx context.Context, msg string, args ...any) {}
 
 func TestFindTemperatureByCity_Success(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -23,12 +40,11 @@ func TestFindTemperatureByCity_Success(t *testing.T) {
 			t.Errorf("expected query param 'q' to be 'São Paulo', but got '%s'", r.URL.Query().Get("q"))
 		}
 		w.WriteHeader(http.StatusOK)
-		w.Write([]byte(`{"current":{"temp_c": 25.5, "temp_f": 77.9}}`))
+		w.Write([]byte(`{"current":{"temp_c": 25.5, "temp_f": 77.9, "humidity": 62, "wind_kph": 11.2, "condition": {"text": "Partly cloudy"}}}`))
 	}))
 	defer server.Close()
 
-	client := NewClient("fake-api-key", &mockLogger{}, noop.NewTracerProvider().Tracer("test"))
-	client.baseURL = server.URL
+	client := NewClient("fake-api-key", &mockLogger{}, noop.NewTracerProvider().Tracer("test"), WithBaseURL(server.URL))
 	weather, err := client.FindTemperatureByCity(context.Background(), "São Paulo")
 
 	if err != nil {
@@ -42,6 +58,139 @@ func TestFindTemperatureByCity_Success(t *testing.T) {
 	if weather.Current.TempF != 77.9 {
 		t.Errorf("expected TempF 77.9, but got '%f'", weather.Current.TempF)
 	}
+
+	if weather.Current.Humidity == nil || *weather.Current.Humidity != 62 {
+		t.Errorf("expected Humidity 62, but got %v", weather.Current.Humidity)
+	}
+
+	if weather.Current.WindKph == nil || *weather.Current.WindKph != 11.2 {
+		t.Errorf("expected WindKph 11.2, but got %v", weather.Current.WindKph)
+	}
+
+	if weather.Current.Condition.Text != "Partly cloudy" {
+		t.Errorf("expected Condition 'Partly cloudy', but got '%s'", weather.Current.Condition.Text)
+	}
+}
+
+func TestFindTemperatureByLocation_QueryIncludesState(t *testing.T) {
+	tests := []struct {
+		state    string
+		expected string
+	}{
+		{"SP", "São Francisco,SP,Brazil"},
+		{"", "São Francisco"},
+	}
+
+	for _, tt := range tests {
+		var gotQ string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			gotQ = r.URL.Query().Get("q")
+			w.Write([]byte(`{"current":{"temp_c": 25.5, "temp_f": 77.9}}`))
+		}))
+
+		client := NewClient("fake-api-key", &mockLogger{}, noop.NewTracerProvider().Tracer("test"), WithBaseURL(server.URL))
+		if _, err := client.FindTemperatureByLocation(context.Background(), "São Francisco", tt.state); err != nil {
+			t.Fatalf("expected no error, but got: %v", err)
+		}
+		server.Close()
+
+		if gotQ != tt.expected {
+			t.Errorf("expected query param 'q' to be '%s', but got '%s'", tt.expected, gotQ)
+		}
+	}
+}
+
+func TestWithAQI(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    []Option
+		wantAQI string
+	}{
+		{"disabled by default", nil, ""},
+		{"enabled", []Option{WithAQI(true)}, "yes"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotAQI string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotAQI = r.URL.Query().Get("aqi")
+				if gotAQI != "yes" {
+					w.Write([]byte(`{"current":{"temp_c": 25.5, "temp_f": 77.9}}`))
+					return
+				}
+				w.Write([]byte(`{"current":{"temp_c": 25.5, "temp_f": 77.9, "air_quality": {"co": 230.3, "o3": 65.1, "pm2_5": 12.4, "pm10": 18.9, "us-epa-index": 2, "gb-defra-index": 1}}}`))
+			}))
+			defer server.Close()
+
+			opts := append([]Option{WithBaseURL(server.URL)}, tt.opts...)
+			client := NewClient("fake-api-key", &mockLogger{}, noop.NewTracerProvider().Tracer("test"), opts...)
+			weather, err := client.FindTemperatureByCity(context.Background(), "São Paulo")
+			if err != nil {
+				t.Fatalf("expected no error, but got: %v", err)
+			}
+
+			if gotAQI != tt.wantAQI {
+				t.Errorf("expected aqi param '%s', but got '%s'", tt.wantAQI, gotAQI)
+			}
+
+			if tt.wantAQI == "" {
+				if weather.Current.AirQuality != nil {
+					t.Errorf("expected no air quality, but got %+v", weather.Current.AirQuality)
+				}
+				return
+			}
+
+			expected := AirQuality{PM25: 12.4, PM10: 18.9, USEPAIndex: 2}
+			if weather.Current.AirQuality == nil || *weather.Current.AirQuality != expected {
+				t.Errorf("expected air quality %+v, but got %+v", expected, weather.Current.AirQuality)
+			}
+		})
+	}
+}
+
+func TestFindTemperatureByCoords(t *testing.T) {
+	var gotQ string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotQ = r.URL.Query().Get("q")
+		w.Write([]byte(`{"current":{"temp_c": 25.5, "temp_f": 77.9}}`))
+	}))
+	defer server.Close()
+
+	client := NewClient("fake-api-key", &mockLogger{}, noop.NewTracerProvider().Tracer("test"), WithBaseURL(server.URL))
+	weather, err := client.FindTemperatureByCoords(context.Background(), -23.5503, -46.6339)
+	if err != nil {
+		t.Fatalf("expected no error, but got: %v", err)
+	}
+
+	if gotQ != "-23.5503,-46.6339" {
+		t.Errorf("expected query param 'q' to be '-23.5503,-46.6339', but got '%s'", gotQ)
+	}
+
+	if weather.Current.TempC != 25.5 {
+		t.Errorf("expected TempC 25.5, but got '%f'", weather.Current.TempC)
+	}
+}
+
+func TestFindTemperatureByCity_Location(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{
+			"location": {"name": "Bom Jesus", "region": "Piaui", "country": "Brazil", "lat": -9.07, "lon": -44.36, "tz_id": "America/Fortaleza", "localtime_epoch": 1760450400, "localtime": "2026-10-14 11:00"},
+			"current": {"temp_c": 31.0, "temp_f": 87.8, "humidity": 40, "wind_kph": 9.4, "condition": {"text": "Sunny", "icon": "//cdn.weatherapi.com/weather/64x64/day/113.png", "code": 1000}}
+		}`))
+	}))
+	defer server.Close()
+
+	client := NewClient("fake-api-key", &mockLogger{}, noop.NewTracerProvider().Tracer("test"), WithBaseURL(server.URL))
+	weather, err := client.FindTemperatureByCity(context.Background(), "Bom Jesus")
+	if err != nil {
+		t.Fatalf("expected no error, but got: %v", err)
+	}
+
+	expected := Location{Name: "Bom Jesus", Region: "Piaui", Country: "Brazil", Lat: -9.07, Lon: -44.36, Localtime: "2026-10-14 11:00"}
+	if weather.Location != expected {
+		t.Errorf("expected location %+v, but got %+v", expected, weather.Location)
+	}
 }
 
 func TestFindTemperatureByCity_NotFound(t *testing.T) {
@@ -50,11 +199,632 @@ func TestFindTemperatureByCity_NotFound(t *testing.T) {
 	}))
 	defer server.Close()
 
-	client := NewClient("fake-api-key", &mockLogger{}, noop.NewTracerProvider().Tracer("test"))
-	client.baseURL = server.URL
+	client := NewClient("fake-api-key", &mockLogger{}, noop.NewTracerProvider().Tracer("test"), WithBaseURL(server.URL))
 
 	_, err := client.FindTemperatureByCity(context.Background(), "CidadeInexistente")
 	if err != ErrCityNotFound {
 		t.Errorf("expected error '%v', but got '%v'", ErrCityNotFound, err)
 	}
 }
+
+func TestFindTemperatureByCity_GzipResponse(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Accept-Encoding"); got != "gzip" {
+			t.Errorf("expected Accept-Encoding 'gzip', but got '%s'", got)
+		}
+		w.Header().Set("Content-Encoding", "gzip")
+		gz := gzip.NewWriter(w)
+		gz.Write([]byte(`{"current":{"temp_c": 25.5, "temp_f": 77.9}}`))
+		gz.Close()
+	}))
+	defer server.Close()
+
+	client := NewClient("fake-api-key", &mockLogger{}, noop.NewTracerProvider().Tracer("test"), WithBaseURL(server.URL))
+
+	weather, err := client.FindTemperatureByCity(context.Background(), "São Paulo")
+	if err != nil {
+		t.Fatalf("expected no error, but got: %v", err)
+	}
+	if weather.Current.TempC != 25.5 {
+		t.Errorf("expected TempC 25.5, but got '%f'", weather.Current.TempC)
+	}
+}
+
+func TestFindTemperatureByCity_OversizedBody(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"current":{"temp_c": 25.5, "condition": {"text": "` + strings.Repeat("a", 2048) + `"}}}`))
+	}))
+	defer server.Close()
+
+	client := NewClient("fake-api-key", &mockLogger{}, noop.NewTracerProvider().Tracer("test"), WithBaseURL(server.URL), WithMaxResponseBytes(1024))
+
+	_, err := client.FindTemperatureByCity(context.Background(), "São Paulo")
+	if err != ErrInternal {
+		t.Errorf("expected error '%v', but got '%v'", ErrInternal, err)
+	}
+}
+
+func TestFindTemperatureByCity_ErrorEnvelope(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		body     string
+		expected error
+		code     int
+	}{
+		{"no matching location", http.StatusBadRequest, `{"error":{"code":1006,"message":"No matching location found."}}`, ErrCityNotFound, 1006},
+		{"invalid api key", http.StatusUnauthorized, `{"error":{"code":2006,"message":"API key is invalid."}}`, ErrInternal, 2006},
+		{"quota exceeded", http.StatusForbidden, `{"error":{"code":2007,"message":"API key has exceeded calls per month quota."}}`, ErrInternal, 2007},
+		{"error with status 200", http.StatusOK, `{"error":{"code":1006,"message":"No matching location found."}}`, ErrCityNotFound, 1006},
+		{"server error without envelope", http.StatusBadGateway, "", ErrInternal, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			recorder := tracetest.NewSpanRecorder()
+			tracer := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)).Tracer("test")
+			client := NewClient("fake-api-key", &mockLogger{}, tracer, WithBaseURL(server.URL), WithHTTPClient(http.DefaultClient))
+
+			_, err := client.FindTemperatureByCity(context.Background(), "CidadeInexistente")
+			if err != tt.expected {
+				t.Errorf("expected error '%v', but got '%v'", tt.expected, err)
+			}
+
+			if tt.code == 0 {
+				return
+			}
+			var got int64
+			for _, attr := range recorder.Ended()[0].Attributes() {
+				if attr.Key == "weatherapi.error_code" {
+					got = attr.Value.AsInt64()
+				}
+			}
+			if got != int64(tt.code) {
+				t.Errorf("expected weatherapi.error_code %d, but got %d", tt.code, got)
+			}
+		})
+	}
+}
+
+func TestFindTemperatureByCity_RateLimited(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "30")
+		w.WriteHeader(http.StatusTooManyRequests)
+		w.Write([]byte(`{"error":{"code":2007,"message":"API key has exceeded calls per month quota."}}`))
+	}))
+	defer server.Close()
+
+	client := NewClient("fake-api-key", &mockLogger{}, noop.NewTracerProvider().Tracer("test"), WithBaseURL(server.URL))
+
+	_, err := client.FindTemperatureByCity(context.Background(), "São Paulo")
+	if !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("expected error '%v', but got '%v'", ErrRateLimited, err)
+	}
+
+	var rateLimited *RateLimitError
+	if !errors.As(err, &rateLimited) || rateLimited.RetryAfter != 30*time.Second {
+		t.Errorf("expected RetryAfter 30s, but got %+v", rateLimited)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2026, time.October, 14, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		value string
+		want  time.Duration
+	}{
+		{"", 0},
+		{"120", 2 * time.Minute},
+		{"-5", 0},
+		{now.Add(90 * time.Second).Format(http.TimeFormat), 90 * time.Second},
+		{now.Add(-time.Minute).Format(http.TimeFormat), 0},
+		{"soon", 0},
+	}
+
+	for _, tt := range tests {
+		if got := parseRetryAfter(tt.value, now); got != tt.want {
+			t.Errorf("%q: expected %s, but got %s", tt.value, tt.want, got)
+		}
+	}
+}
+
+func TestFindTemperatureByCity_RetrySucceedsAfterFailures(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) <= 2 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"current":{"temp_c": 25.5, "temp_f": 77.9}}`))
+	}))
+	defer server.Close()
+
+	client := NewClient("fake-api-key", &mockLogger{}, noop.NewTracerProvider().Tracer("test"), WithBaseURL(server.URL), WithRetry(3, time.Millisecond))
+
+	weather, err := client.FindTemperatureByCity(context.Background(), "São Paulo")
+	if err != nil {
+		t.Fatalf("expected no error, but got: %v", err)
+	}
+
+	if weather.Current.TempC != 25.5 {
+		t.Errorf("expected TempC 25.5, but got '%f'", weather.Current.TempC)
+	}
+
+	if got := atomic.LoadInt32(&calls); got != 3 {
+		t.Errorf("expected 3 calls, but got %d", got)
+	}
+}
+
+func TestFindTemperatureByCity_RetryGivesUpAfterMaxAttempts(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	client := NewClient("fake-api-key", &mockLogger{}, noop.NewTracerProvider().Tracer("test"), WithBaseURL(server.URL), WithRetry(3, time.Millisecond))
+
+	if _, err := client.FindTemperatureByCity(context.Background(), "São Paulo"); err == nil {
+		t.Fatal("expected an error, but got nil")
+	}
+
+	if got := atomic.LoadInt32(&calls); got != 3 {
+		t.Errorf("expected 3 calls, but got %d", got)
+	}
+}
+
+func TestFindTemperatureByCity_NoRetryOnClientError(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	client := NewClient("fake-api-key", &mockLogger{}, noop.NewTracerProvider().Tracer("test"), WithBaseURL(server.URL), WithRetry(3, time.Millisecond))
+
+	_, err := client.FindTemperatureByCity(context.Background(), "CidadeInexistente")
+	if err != ErrCityNotFound {
+		t.Errorf("expected error '%v', but got '%v'", ErrCityNotFound, err)
+	}
+
+	if got := atomic.LoadInt32(&calls); got != 1 {
+		t.Errorf("expected 1 call, but got %d", got)
+	}
+}
+
+func TestFindTemperatureByCity_RetryRespectsContext(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	client := NewClient("fake-api-key", &mockLogger{}, noop.NewTracerProvider().Tracer("test"), WithBaseURL(server.URL), WithRetry(5, time.Second))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	if _, err := client.FindTemperatureByCity(ctx, "São Paulo"); err == nil {
+		t.Fatal("expected an error, but got nil")
+	}
+
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("expected to stop at the context deadline, but took %s", elapsed)
+	}
+}
+
+func TestFindTemperatureByCity_CircuitBreakerFailsFast(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	client := NewClient("fake-api-key", &mockLogger{}, noop.NewTracerProvider().Tracer("test"), WithBaseURL(server.URL), WithCircuitBreaker(1, time.Minute))
+
+	client.FindTemperatureByCity(context.Background(), "São Paulo")
+	_, err := client.FindTemperatureByCity(context.Background(), "São Paulo")
+	if err != ErrInternal {
+		t.Errorf("expected error '%v', but got '%v'", ErrInternal, err)
+	}
+
+	if got := atomic.LoadInt32(&calls); got != 1 {
+		t.Errorf("expected 1 upstream call, but got %d", got)
+	}
+}
+
+func TestFindTemperatureByCity_CallerCancellationDoesNotOpenBreaker(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	client := NewClient("fake-api-key", &mockLogger{}, noop.NewTracerProvider().Tracer("test"), WithBaseURL(server.URL), WithCircuitBreaker(1, time.Minute))
+
+	for i := 0; i < 3; i++ {
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		client.FindTemperatureByCity(ctx, "São Paulo")
+		cancel()
+	}
+
+	if got := client.Breaker().State(); got != circuitbreaker.Closed {
+		t.Errorf("expected state 'closed', but got '%s'", got)
+	}
+}
+
+func TestWithTransportConfig(t *testing.T) {
+	cfg := httpx.TransportConfig{MaxIdleConns: 200, MaxIdleConnsPerHost: 50, IdleConnTimeout: 30 * time.Second}
+	client := NewClient("fake-api-key", &mockLogger{}, noop.NewTracerProvider().Tracer("test"), WithTransportConfig(cfg))
+
+	if client.transport != cfg {
+		t.Errorf("expected transport config %+v, but got %+v", cfg, client.transport)
+	}
+
+	if client.httpClient == nil || client.httpClient.Transport == nil {
+		t.Fatal("expected the default instrumented client to be built")
+	}
+}
+
+func TestWithTimeout(t *testing.T) {
+	client := NewClient("fake-api-key", &mockLogger{}, noop.NewTracerProvider().Tracer("test"), WithTimeout(750*time.Millisecond))
+
+	if client.httpClient.Timeout != 750*time.Millisecond {
+		t.Errorf("expected timeout 750ms, but got %s", client.httpClient.Timeout)
+	}
+}
+
+func TestWithHTTPClient_TakesPrecedenceOverTimeout(t *testing.T) {
+	custom := &http.Client{Timeout: time.Minute}
+	client := NewClient("fake-api-key", &mockLogger{}, noop.NewTracerProvider().Tracer("test"), WithHTTPClient(custom), WithTimeout(time.Second))
+
+	if client.httpClient != custom {
+		t.Fatal("expected the supplied http client to be used")
+	}
+
+	if custom.Timeout != time.Minute {
+		t.Errorf("expected supplied client timeout to be untouched, but got %s", custom.Timeout)
+	}
+}
+
+func TestFindTemperatureByCity_ErrorLogRedactsKey(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	server.Close()
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+	client := NewClient("super-secret-key", logger, noop.NewTracerProvider().Tracer("test"), WithBaseURL(server.URL))
+
+	if _, err := client.FindTemperatureByCity(context.Background(), "São Paulo"); err != ErrInternal {
+		t.Fatalf("expected error '%v', but got '%v'", ErrInternal, err)
+	}
+
+	if buf.Len() == 0 {
+		t.Fatal("expected the transport error to be logged")
+	}
+
+	if strings.Contains(buf.String(), "super-secret-key") {
+		t.Errorf("expected API key to be redacted from logs, but got: %s", buf.String())
+	}
+}
+
+func TestFindTemperatureByCity_SpanStatusOnError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	recorder := tracetest.NewSpanRecorder()
+	tracer := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)).Tracer("test")
+	client := NewClient("fake-api-key", &mockLogger{}, tracer, WithBaseURL(server.URL), WithHTTPClient(http.DefaultClient))
+	client.FindTemperatureByCity(context.Background(), "São Paulo")
+
+	spans := recorder.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("expected 1 span, but got %d", len(spans))
+	}
+
+	if got := spans[0].Status().Code; got != codes.Error {
+		t.Errorf("expected span status %s, but got %s", codes.Error, got)
+	}
+}
+
+func TestUserAgent(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     []Option
+		expected string
+	}{
+		{"default", nil, "l02-app2/dev"},
+		{"custom", []Option{WithUserAgent("dashboard/1.0")}, "dashboard/1.0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = r.UserAgent()
+				w.Write([]byte(`{"current":{"temp_c": 25.5, "temp_f": 77.9}}`))
+			}))
+			defer server.Close()
+
+			client := NewClient("fake-api-key", &mockLogger{}, noop.NewTracerProvider().Tracer("test"), append(tt.opts, WithBaseURL(server.URL))...)
+			client.FindTemperatureByCity(context.Background(), "São Paulo")
+
+			if got != tt.expected {
+				t.Errorf("expected User-Agent '%s', but got '%s'", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestFindTemperatureByCity_TimeoutErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+	}))
+	defer server.Close()
+
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name     string
+		ctx      context.Context
+		expected error
+	}{
+		{"slow server", context.Background(), ErrTimeout},
+		{"canceled context", canceled, ErrInternal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := NewClient("fake-api-key", &mockLogger{}, noop.NewTracerProvider().Tracer("test"), WithBaseURL(server.URL), WithTimeout(50*time.Millisecond))
+			if _, err := client.FindTemperatureByCity(tt.ctx, "São Paulo"); err != tt.expected {
+				t.Errorf("expected error '%v', but got '%v'", tt.expected, err)
+			}
+		})
+	}
+}
+
+func TestFindForecastByLocation(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/forecast.json" {
+			t.Errorf("expected path '/forecast.json', but got '%s'", r.URL.Path)
+		}
+
+		if r.URL.Query().Get("days") != "2" {
+			t.Errorf("expected query param 'days' to be '2', but got '%s'", r.URL.Query().Get("days"))
+		}
+
+		w.Write([]byte(`{
+			"current": {"temp_c": 25.5, "temp_f": 77.9},
+			"forecast": {"forecastday": [
+				{"date": "2026-10-14", "day": {"maxtemp_c": 28.1, "maxtemp_f": 82.6, "mintemp_c": 17.4, "mintemp_f": 63.3, "daily_chance_of_rain": 80, "condition": {"text": "Patchy rain nearby"}}},
+				{"date": "2026-10-15", "day": {"maxtemp_c": 24.0, "maxtemp_f": 75.2, "mintemp_c": 16.2, "mintemp_f": 61.2, "daily_chance_of_rain": 0, "condition": {"text": "Sunny"}}}
+			]}
+		}`))
+	}))
+	defer server.Close()
+
+	client := NewClient("fake-api-key", &mockLogger{}, noop.NewTracerProvider().Tracer("test"), WithBaseURL(server.URL))
+	weather, err := client.FindForecastByLocation(context.Background(), "São Paulo", "SP", 2)
+	if err != nil {
+		t.Fatalf("expected no error, but got: %v", err)
+	}
+
+	if weather.Current.TempC != 25.5 {
+		t.Errorf("expected TempC 25.5, but got '%f'", weather.Current.TempC)
+	}
+
+	if weather.Forecast == nil || len(weather.Forecast.ForecastDay) != 2 {
+		t.Fatalf("expected 2 forecast days, but got %+v", weather.Forecast)
+	}
+
+	expected := ForecastDay{Date: "2026-10-14", Day: DayWeather{
+		MaxTempC: 28.1, MaxTempF: 82.6, MinTempC: 17.4, MinTempF: 63.3, DailyChanceOfRain: 80, Condition: Condition{Text: "Patchy rain nearby"},
+	}}
+	if weather.Forecast.ForecastDay[0] != expected {
+		t.Errorf("expected first day %+v, but got %+v", expected, weather.Forecast.ForecastDay[0])
+	}
+}
+
+func TestFindForecast_CurrentEndpointWithoutDays(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/current.json" || r.URL.Query().Has("days") {
+			t.Errorf("expected current.json without days, but got %s", r.URL.RequestURI())
+		}
+		w.Write([]byte(`{"current":{"temp_c": 25.5, "temp_f": 77.9}}`))
+	}))
+	defer server.Close()
+
+	client := NewClient("fake-api-key", &mockLogger{}, noop.NewTracerProvider().Tracer("test"), WithBaseURL(server.URL))
+	weather, err := client.FindTemperatureByLocation(context.Background(), "São Paulo", "SP")
+	if err != nil {
+		t.Fatalf("expected no error, but got: %v", err)
+	}
+
+	if weather.Forecast != nil {
+		t.Errorf("expected no forecast, but got %+v", weather.Forecast)
+	}
+}
+
+func TestFindForecast_InvalidDays(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+	}))
+	defer server.Close()
+
+	client := NewClient("fake-api-key", &mockLogger{}, noop.NewTracerProvider().Tracer("test"), WithBaseURL(server.URL))
+	for _, days := range []int{-1, 0, MaxForecastDays + 1} {
+		if _, err := client.FindForecastByLocation(context.Background(), "São Paulo", "SP", days); err != ErrInvalidDays {
+			t.Errorf("expected ErrInvalidDays for %d days, but got '%v'", days, err)
+		}
+		if _, err := client.FindForecastByCoords(context.Background(), -23.55, -46.63, days); err != ErrInvalidDays {
+			t.Errorf("expected ErrInvalidDays for %d days, but got '%v'", days, err)
+		}
+	}
+
+	if got := calls.Load(); got != 0 {
+		t.Errorf("expected no upstream calls, but got %d", got)
+	}
+}
+
+func TestFindTemperatureByCityOnDate(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/history.json" {
+			t.Errorf("expected path '/history.json', but got '%s'", r.URL.Path)
+		}
+
+		if r.URL.Query().Get("dt") != "2024-01-15" || r.URL.Query().Get("q") != "São Paulo" {
+			t.Errorf("expected dt '2024-01-15' and q 'São Paulo', but got %s", r.URL.RawQuery)
+		}
+
+		if r.URL.Query().Has("aqi") {
+			t.Error("expected no aqi param on history requests")
+		}
+
+		w.Write([]byte(`{"location": {"name": "Sao Paulo"}, "forecast": {"forecastday": [
+			{"date": "2024-01-15", "day": {"maxtemp_c": 30.2, "maxtemp_f": 86.4, "mintemp_c": 20.1, "mintemp_f": 68.2, "avgtemp_c": 24.6, "avgtemp_f": 76.3}}
+		]}}`))
+	}))
+	defer server.Close()
+
+	client := NewClient("fake-api-key", &mockLogger{}, noop.NewTracerProvider().Tracer("test"), WithBaseURL(server.URL), WithAQI(true))
+	weather, err := client.FindTemperatureByCityOnDate(context.Background(), "São Paulo", time.Date(2024, time.January, 15, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("expected no error, but got: %v", err)
+	}
+
+	day := weather.Forecast.ForecastDay[0].Day
+	if day.AvgTempC != 24.6 || day.MaxTempC != 30.2 || day.MinTempC != 20.1 {
+		t.Errorf("expected avg/max/min 24.6/30.2/20.1, but got %v/%v/%v", day.AvgTempC, day.MaxTempC, day.MinTempC)
+	}
+}
+
+func TestValidHistoryDate(t *testing.T) {
+	now := time.Date(2026, time.October, 14, 15, 0, 0, 0, time.UTC)
+	tests := []struct {
+		date     time.Time
+		expected bool
+	}{
+		{MinHistoryDate, true},
+		{MinHistoryDate.AddDate(0, 0, -1), false},
+		{time.Date(2026, time.October, 14, 0, 0, 0, 0, time.UTC), true},
+		{time.Date(2026, time.October, 15, 0, 0, 0, 0, time.UTC), false},
+	}
+
+	for _, tt := range tests {
+		if got := ValidHistoryDate(tt.date, now); got != tt.expected {
+			t.Errorf("expected ValidHistoryDate(%s) = %v, but got %v", tt.date.Format(DateLayout), tt.expected, got)
+		}
+	}
+}
+
+func TestFindTemperatureByCityOnDate_InvalidDate(t *testing.T) {
+	client := NewClient("fake-api-key", &mockLogger{}, noop.NewTracerProvider().Tracer("test"), WithBaseURL("http://127.0.0.1:0"))
+	if _, err := client.FindTemperatureByCityOnDate(context.Background(), "São Paulo", time.Now().AddDate(0, 0, 2)); err != ErrInvalidDate {
+		t.Errorf("expected error '%v', but got '%v'", ErrInvalidDate, err)
+	}
+}
+
+func TestWithSanityCheck(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     []Option
+		body     string
+		expected error
+	}{
+		{"in range", []Option{WithSanityCheck(true)}, `{"current":{"temp_c": 25.5, "temp_f": 77.9}}`, nil},
+		{"negative in range", []Option{WithSanityCheck(true)}, `{"current":{"temp_c": -45.2, "temp_f": -49.4}}`, nil},
+		{"too hot", []Option{WithSanityCheck(true)}, `{"current":{"temp_c": 9999, "temp_f": 18030.2}}`, ErrInternal},
+		{"too cold", []Option{WithSanityCheck(true)}, `{"current":{"temp_c": -120, "temp_f": -184}}`, ErrInternal},
+		{"forecast day out of range", []Option{WithSanityCheck(true)}, `{"current":{"temp_c": 25.5}, "forecast":{"forecastday":[{"date":"2026-10-14","day":{"maxtemp_c": 70, "mintemp_c": 20, "avgtemp_c": 25}}]}}`, ErrInternal},
+		{"disabled by default", nil, `{"current":{"temp_c": 9999, "temp_f": 18030.2}}`, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			recorder := tracetest.NewSpanRecorder()
+			tracer := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)).Tracer("test")
+			opts := append([]Option{WithBaseURL(server.URL)}, tt.opts...)
+			client := NewClient("fake-api-key", &mockLogger{}, tracer, opts...)
+
+			_, err := client.FindTemperatureByCity(context.Background(), "São Paulo")
+			if err != tt.expected {
+				t.Fatalf("expected error %v, but got %v", tt.expected, err)
+			}
+
+			// O transporte instrumentado também registra o span HTTP no mesmo recorder
+			var found bool
+			for _, span := range recorder.Ended() {
+				for _, e := range span.Events() {
+					found = found || e.Name == "implausible temperature"
+				}
+			}
+			if found != (tt.expected != nil) {
+				t.Errorf("expected implausible temperature event: %v, but got %v", tt.expected != nil, found)
+			}
+		})
+	}
+}
+
+type recordingMetrics struct {
+	mu      sync.Mutex
+	classes []string
+}
+
+func (m *recordingMetrics) ObserveOutbound(upstream, statusClass string, duration time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if upstream != "weatherapi" {
+		statusClass = "unexpected upstream " + upstream
+	}
+	m.classes = append(m.classes, statusClass)
+}
+
+func TestWithMetrics_StatusClass(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.Write([]byte(`{"current":{"temp_c": 25.5, "temp_f": 77.9}}`))
+			return
+		}
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	m := &recordingMetrics{}
+	client := NewClient("fake-api-key", &mockLogger{}, noop.NewTracerProvider().Tracer("test"), WithBaseURL(server.URL), WithMetrics(m))
+	client.FindTemperatureByCity(context.Background(), "São Paulo")
+	client.FindTemperatureByCity(context.Background(), "São Paulo")
+
+	// Sem resposta: a chamada conta como "error"
+	server.Close()
+	client.FindTemperatureByCity(context.Background(), "São Paulo")
+
+	expected := []string{"2xx", "5xx", "error"}
+	if !slices.Equal(m.classes, expected) {
+		t.Errorf("expected status classes %v, but got %v", expected, m.classes)
+	}
+}
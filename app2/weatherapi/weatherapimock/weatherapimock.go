@@ -0,0 +1,75 @@
+package weatherapimock
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"l02-02/weatherapi"
+)
+
+// Uma chamada recebida; apenas os campos do método chamado são preenchidos
+type Call struct {
+	Method string
+	City   string
+	State  string
+	Lat    float64
+	Lon    float64
+	Days   int
+	Date   time.Time
+}
+
+// Implementação configurável de weatherapi.WeatherApiClient para testes; segura para uso concorrente
+type Client struct {
+	Response *weatherapi.WeatherApiResponse
+	Err      error
+	// Quando definida, substitui Response e Err (ex.: erro apenas na previsão)
+	Func func(ctx context.Context, call Call) (*weatherapi.WeatherApiResponse, error)
+
+	mu    sync.Mutex
+	calls []Call
+}
+
+var _ weatherapi.WeatherApiClient = (*Client)(nil)
+
+func (c *Client) FindTemperatureByCity(ctx context.Context, city string) (*weatherapi.WeatherApiResponse, error) {
+	return c.record(ctx, Call{Method: "FindTemperatureByCity", City: city})
+}
+
+func (c *Client) FindTemperatureByLocation(ctx context.Context, city, state string) (*weatherapi.WeatherApiResponse, error) {
+	return c.record(ctx, Call{Method: "FindTemperatureByLocation", City: city, State: state})
+}
+
+func (c *Client) FindTemperatureByCoords(ctx context.Context, lat, lon float64) (*weatherapi.WeatherApiResponse, error) {
+	return c.record(ctx, Call{Method: "FindTemperatureByCoords", Lat: lat, Lon: lon})
+}
+
+func (c *Client) FindForecastByLocation(ctx context.Context, city, state string, days int) (*weatherapi.WeatherApiResponse, error) {
+	return c.record(ctx, Call{Method: "FindForecastByLocation", City: city, State: state, Days: days})
+}
+
+func (c *Client) FindForecastByCoords(ctx context.Context, lat, lon float64, days int) (*weatherapi.WeatherApiResponse, error) {
+	return c.record(ctx, Call{Method: "FindForecastByCoords", Lat: lat, Lon: lon, Days: days})
+}
+
+func (c *Client) FindTemperatureByLocationOnDate(ctx context.Context, city, state string, date time.Time) (*weatherapi.WeatherApiResponse, error) {
+	return c.record(ctx, Call{Method: "FindTemperatureByLocationOnDate", City: city, State: state, Date: date})
+}
+
+// Chamadas recebidas, na ordem
+func (c *Client) Calls() []Call {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]Call(nil), c.calls...)
+}
+
+func (c *Client) record(ctx context.Context, call Call) (*weatherapi.WeatherApiResponse, error) {
+	c.mu.Lock()
+	c.calls = append(c.calls, call)
+	c.mu.Unlock()
+
+	if c.Func != nil {
+		return c.Func(ctx, call)
+	}
+	return c.Response, c.Err
+}
@@ -0,0 +1,4 @@
+// Código gerado a partir de weather.proto; requer protoc, protoc-gen-go e protoc-gen-go-grpc no PATH
+package weatherpb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative weather.proto
@@ -0,0 +1,281 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        v5.27.1
+// source: weather.proto
+
+// Contrato gRPC do app2, equivalente ao GET /get-weather-by-cep (apenas o clima atual)
+
+package weatherpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type GetWeatherByCepRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Com ou sem hífen, como no endpoint HTTP
+	Cep           string `protobuf:"bytes,1,opt,name=cep,proto3" json:"cep,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetWeatherByCepRequest) Reset() {
+	*x = GetWeatherByCepRequest{}
+	mi := &file_weather_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetWeatherByCepRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetWeatherByCepRequest) ProtoMessage() {}
+
+func (x *GetWeatherByCepRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_weather_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetWeatherByCepRequest.ProtoReflect.Descriptor instead.
+func (*GetWeatherByCepRequest) Descriptor() ([]byte, []int) {
+	return file_weather_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *GetWeatherByCepRequest) GetCep() string {
+	if x != nil {
+		return x.Cep
+	}
+	return ""
+}
+
+type WeatherReply struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	City   string                 `protobuf:"bytes,1,opt,name=city,proto3" json:"city,omitempty"`
+	State  string                 `protobuf:"bytes,2,opt,name=state,proto3" json:"state,omitempty"`
+	Street string                 `protobuf:"bytes,3,opt,name=street,proto3" json:"street,omitempty"`
+	Cep    string                 `protobuf:"bytes,4,opt,name=cep,proto3" json:"cep,omitempty"`
+	TempC  float64                `protobuf:"fixed64,5,opt,name=temp_c,json=tempC,proto3" json:"temp_c,omitempty"`
+	TempF  float64                `protobuf:"fixed64,6,opt,name=temp_f,json=tempF,proto3" json:"temp_f,omitempty"`
+	TempK  float64                `protobuf:"fixed64,7,opt,name=temp_k,json=tempK,proto3" json:"temp_k,omitempty"`
+	// Ausentes quando a WeatherAPI não os informa
+	Humidity      *int32   `protobuf:"varint,8,opt,name=humidity,proto3,oneof" json:"humidity,omitempty"`
+	WindKph       *float64 `protobuf:"fixed64,9,opt,name=wind_kph,json=windKph,proto3,oneof" json:"wind_kph,omitempty"`
+	Condition     string   `protobuf:"bytes,10,opt,name=condition,proto3" json:"condition,omitempty"`
+	Region        string   `protobuf:"bytes,11,opt,name=region,proto3" json:"region,omitempty"`
+	Country       string   `protobuf:"bytes,12,opt,name=country,proto3" json:"country,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WeatherReply) Reset() {
+	*x = WeatherReply{}
+	mi := &file_weather_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WeatherReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WeatherReply) ProtoMessage() {}
+
+func (x *WeatherReply) ProtoReflect() protoreflect.Message {
+	mi := &file_weather_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WeatherReply.ProtoReflect.Descriptor instead.
+func (*WeatherReply) Descriptor() ([]byte, []int) {
+	return file_weather_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *WeatherReply) GetCity() string {
+	if x != nil {
+		return x.City
+	}
+	return ""
+}
+
+func (x *WeatherReply) GetState() string {
+	if x != nil {
+		return x.State
+	}
+	return ""
+}
+
+func (x *WeatherReply) GetStreet() string {
+	if x != nil {
+		return x.Street
+	}
+	return ""
+}
+
+func (x *WeatherReply) GetCep() string {
+	if x != nil {
+		return x.Cep
+	}
+	return ""
+}
+
+func (x *WeatherReply) GetTempC() float64 {
+	if x != nil {
+		return x.TempC
+	}
+	return 0
+}
+
+func (x *WeatherReply) GetTempF() float64 {
+	if x != nil {
+		return x.TempF
+	}
+	return 0
+}
+
+func (x *WeatherReply) GetTempK() float64 {
+	if x != nil {
+		return x.TempK
+	}
+	return 0
+}
+
+func (x *WeatherReply) GetHumidity() int32 {
+	if x != nil && x.Humidity != nil {
+		return *x.Humidity
+	}
+	return 0
+}
+
+func (x *WeatherReply) GetWindKph() float64 {
+	if x != nil && x.WindKph != nil {
+		return *x.WindKph
+	}
+	return 0
+}
+
+func (x *WeatherReply) GetCondition() string {
+	if x != nil {
+		return x.Condition
+	}
+	return ""
+}
+
+func (x *WeatherReply) GetRegion() string {
+	if x != nil {
+		return x.Region
+	}
+	return ""
+}
+
+func (x *WeatherReply) GetCountry() string {
+	if x != nil {
+		return x.Country
+	}
+	return ""
+}
+
+var File_weather_proto protoreflect.FileDescriptor
+
+const file_weather_proto_rawDesc = "" +
+	"\n" +
+	"\rweather.proto\x12\n" +
+	"weather.v1\"*\n" +
+	"\x16GetWeatherByCepRequest\x12\x10\n" +
+	"\x03cep\x18\x01 \x01(\tR\x03cep\"\xd2\x02\n" +
+	"\fWeatherReply\x12\x12\n" +
+	"\x04city\x18\x01 \x01(\tR\x04city\x12\x14\n" +
+	"\x05state\x18\x02 \x01(\tR\x05state\x12\x16\n" +
+	"\x06street\x18\x03 \x01(\tR\x06street\x12\x10\n" +
+	"\x03cep\x18\x04 \x01(\tR\x03cep\x12\x15\n" +
+	"\x06temp_c\x18\x05 \x01(\x01R\x05tempC\x12\x15\n" +
+	"\x06temp_f\x18\x06 \x01(\x01R\x05tempF\x12\x15\n" +
+	"\x06temp_k\x18\a \x01(\x01R\x05tempK\x12\x1f\n" +
+	"\bhumidity\x18\b \x01(\x05H\x00R\bhumidity\x88\x01\x01\x12\x1e\n" +
+	"\bwind_kph\x18\t \x01(\x01H\x01R\awindKph\x88\x01\x01\x12\x1c\n" +
+	"\tcondition\x18\n" +
+	" \x01(\tR\tcondition\x12\x16\n" +
+	"\x06region\x18\v \x01(\tR\x06region\x12\x18\n" +
+	"\acountry\x18\f \x01(\tR\acountryB\v\n" +
+	"\t_humidityB\v\n" +
+	"\t_wind_kph2a\n" +
+	"\x0eWeatherService\x12O\n" +
+	"\x0fGetWeatherByCep\x12\".weather.v1.GetWeatherByCepRequest\x1a\x18.weather.v1.WeatherReplyB\x12Z\x10l02-02/weatherpbb\x06proto3"
+
+var (
+	file_weather_proto_rawDescOnce sync.Once
+	file_weather_proto_rawDescData []byte
+)
+
+func file_weather_proto_rawDescGZIP() []byte {
+	file_weather_proto_rawDescOnce.Do(func() {
+		file_weather_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_weather_proto_rawDesc), len(file_weather_proto_rawDesc)))
+	})
+	return file_weather_proto_rawDescData
+}
+
+var file_weather_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_weather_proto_goTypes = []any{
+	(*GetWeatherByCepRequest)(nil), // 0: weather.v1.GetWeatherByCepRequest
+	(*WeatherReply)(nil),           // 1: weather.v1.WeatherReply
+}
+var file_weather_proto_depIdxs = []int32{
+	0, // 0: weather.v1.WeatherService.GetWeatherByCep:input_type -> weather.v1.GetWeatherByCepRequest
+	1, // 1: weather.v1.WeatherService.GetWeatherByCep:output_type -> weather.v1.WeatherReply
+	1, // [1:2] is the sub-list for method output_type
+	0, // [0:1] is the sub-list for method input_type
+	0, // [0:0] is the sub-list for extension type_name
+	0, // [0:0] is the sub-list for extension extendee
+	0, // [0:0] is the sub-list for field type_name
+}
+
+func init() { file_weather_proto_init() }
+func file_weather_proto_init() {
+	if File_weather_proto != nil {
+		return
+	}
+	file_weather_proto_msgTypes[1].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_weather_proto_rawDesc), len(file_weather_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   2,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_weather_proto_goTypes,
+		DependencyIndexes: file_weather_proto_depIdxs,
+		MessageInfos:      file_weather_proto_msgTypes,
+	}.Build()
+	File_weather_proto = out.File
+	file_weather_proto_goTypes = nil
+	file_weather_proto_depIdxs = nil
+}